@@ -30,6 +30,42 @@ var (
 		Usage: `This flag specifies the source paths separated by ",". Example "-sources ` + strings.Join([]string{"path/1", "path/2", "path/3"}, sourcePathsDelimiter) + "\"",
 		Value: "",
 	}
+	dbRoot = cli.StringFlag{
+		Name: "db-root",
+		Usage: "This flag specifies the node's db root path. If set, the sources are the directories of the storage " +
+			"unit provided with the `unit` flag, from all the epochs, merged in epoch order. Can not be used with the `sources` flag",
+		Value: "",
+	}
+	unit = cli.StringFlag{
+		Name:  "unit",
+		Usage: "This flag specifies the storage unit name used with the `db-root` flag. Example \"-unit BlockHeaders\" or \"-unit AccountsTrie/MainDB\"",
+		Value: "",
+	}
+	conflictPolicy = cli.StringFlag{
+		Name:  "conflict-policy",
+		Usage: "This flag specifies the value kept when a key has different values in the sources. Available policies: last-wins, first-wins, fail-on-conflict",
+		Value: string(storer.LastWinsPolicy),
+	}
+	conflictReport = cli.StringFlag{
+		Name:  "conflict-report",
+		Usage: "This flag specifies the file where the keys with different values in the sources are written. No report is written if empty",
+		Value: "",
+	}
+	batchSize = cli.IntFlag{
+		Name:  "batch-size",
+		Usage: "This flag specifies the number of key-value pairs read from a source in one batch",
+		Value: storer.DefaultBatchSize,
+	}
+	numParallelReaders = cli.IntFlag{
+		Name:  "num-parallel-readers",
+		Usage: "This flag specifies the number of sources read at the same time, ahead of the destination writer",
+		Value: storer.DefaultNumParallelReaders,
+	}
+	configFile = cli.StringFlag{
+		Name:  "config",
+		Usage: "This flag specifies the TOML file holding the DB and cache options of the sources and of the destination. The default level DB options are used if empty",
+		Value: "",
+	}
 	logLevel = cli.StringFlag{
 		Name: "log-level",
 		Usage: "This flag specifies the logger `level(s)`. It can contain multiple comma-separated value. For example" +
@@ -43,7 +79,9 @@ var (
 		Usage: "Boolean option for enabling log saving. If set, it will automatically save all the logs into a file.",
 	}
 
-	errEmptyPathProvided = errors.New("empty path provided")
+	errEmptyPathProvided    = errors.New("empty path provided")
+	errEmptyUnitProvided    = errors.New("empty storage unit name provided")
+	errSourcesAndDBRootUsed = errors.New("the `sources` and `db-root` flags can not be used together")
 )
 
 const helpTemplate = `NAME:
@@ -65,6 +103,13 @@ VERSION:
 type parsedFlags struct {
 	destPath    string
 	sourcePaths []string
+	dbRootPath  string
+	unitName    string
+	policy      storer.ConflictPolicy
+	reportPath  string
+	batchSize   int
+	numReaders  int
+	configFile  string
 	logLevel    string
 	logSave     bool
 }
@@ -78,6 +123,13 @@ func main() {
 	app.Flags = []cli.Flag{
 		dest,
 		sources,
+		dbRoot,
+		unit,
+		conflictPolicy,
+		conflictReport,
+		batchSize,
+		numParallelReaders,
+		configFile,
 		logLevel,
 		logSaveFile,
 	}
@@ -117,16 +169,38 @@ func parseFlags(ctx *cli.Context) (parsedFlags, error) {
 	sourcePaths := ctx.GlobalString(sources.Name)
 
 	flags := parsedFlags{
-		destPath:    ctx.GlobalString(dest.Name),
-		sourcePaths: strings.Split(sourcePaths, sourcePathsDelimiter),
-		logLevel:    ctx.GlobalString(logLevel.Name),
-		logSave:     ctx.GlobalBool(logSaveFile.Name),
+		destPath:   ctx.GlobalString(dest.Name),
+		dbRootPath: ctx.GlobalString(dbRoot.Name),
+		unitName:   ctx.GlobalString(unit.Name),
+		reportPath: ctx.GlobalString(conflictReport.Name),
+		batchSize:  ctx.GlobalInt(batchSize.Name),
+		numReaders: ctx.GlobalInt(numParallelReaders.Name),
+		configFile: ctx.GlobalString(configFile.Name),
+		logLevel:   ctx.GlobalString(logLevel.Name),
+		logSave:    ctx.GlobalBool(logSaveFile.Name),
 	}
 
 	// TODO add separate check functions
 	if len(flags.destPath) == 0 {
 		return parsedFlags{}, fmt.Errorf("%w for `dest` flag", errEmptyPathProvided)
 	}
+	var err error
+	flags.policy, err = storer.ParseConflictPolicy(ctx.GlobalString(conflictPolicy.Name))
+	if err != nil {
+		return parsedFlags{}, err
+	}
+	if len(flags.dbRootPath) > 0 {
+		if len(sourcePaths) > 0 {
+			return parsedFlags{}, errSourcesAndDBRootUsed
+		}
+		if len(flags.unitName) == 0 {
+			return parsedFlags{}, fmt.Errorf("%w for `unit` flag", errEmptyUnitProvided)
+		}
+
+		return flags, nil
+	}
+
+	flags.sourcePaths = strings.Split(sourcePaths, sourcePathsDelimiter)
 	for idx, src := range flags.sourcePaths {
 		if len(src) == 0 {
 			return parsedFlags{}, fmt.Errorf("%w for source flag with index %d", errEmptyPathProvided, idx)
@@ -142,18 +216,37 @@ func doAction(flags parsedFlags) error {
 		return err
 	}
 
-	persisterCreator := storer.NewPersisterCreator()
+	sourcePaths, err := getSourcePaths(flags)
+	if err != nil {
+		return err
+	}
+
+	dataMerger, err := storer.NewDataMergerWithArgs(storer.ArgsDataMerger{
+		ConflictPolicy:     flags.policy,
+		ReportPath:         flags.reportPath,
+		BatchSize:          flags.batchSize,
+		NumParallelReaders: flags.numReaders,
+	})
+	if err != nil {
+		return err
+	}
+
+	sourcePersisterCreator, destinationPersisterCreator, err := storer.CreatePersisterCreators(flags.configFile)
+	if err != nil {
+		return err
+	}
 	args := storer.ArgsFullDBMerger{
-		DataMergerInstance:  storer.NewDataMerger(),
-		PersisterCreator:    persisterCreator,
-		OsOperationsHandler: path.NewOsOperationsHandler(),
+		DataMergerInstance:          dataMerger,
+		SourcePersisterCreator:      sourcePersisterCreator,
+		DestinationPersisterCreator: destinationPersisterCreator,
+		OsOperationsHandler:         path.NewOsOperationsHandler(),
 	}
 	fullDataMerger, err := storer.NewFullDBMerger(args)
 	if err != nil {
 		return err
 	}
 
-	destDB, err := fullDataMerger.MergeDBs(flags.destPath, flags.sourcePaths...)
+	destDB, err := fullDataMerger.MergeDBs(flags.destPath, sourcePaths...)
 	if err != nil {
 		return err
 	}
@@ -161,6 +254,30 @@ func doAction(flags parsedFlags) error {
 	return destDB.Close()
 }
 
+// getSourcePaths returns the source paths provided by the user or, in the db root mode, the storage unit
+// directories from all the epochs found in the node's db root path
+func getSourcePaths(flags parsedFlags) ([]string, error) {
+	if len(flags.dbRootPath) == 0 {
+		return flags.sourcePaths, nil
+	}
+
+	parser := path.NewParser(flags.dbRootPath)
+	err := parser.ParseDirectory()
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing the db root path %s", err, flags.dbRootPath)
+	}
+
+	sourcePaths, err := parser.StorageUnitPaths(flags.unitName)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("found storage unit directories", "chain ID", parser.ChainID(), "shard ID", parser.ShardID(),
+		"unit", flags.unitName, "epochs", fmt.Sprintf("%d-%d", parser.LowestContinuousEpoch(), parser.HighestEpoch()))
+
+	return sourcePaths, nil
+}
+
 func processFileLogger(log logger.Logger, flags parsedFlags) error {
 	var err error
 	if flags.logSave {
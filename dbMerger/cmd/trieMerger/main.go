@@ -0,0 +1,243 @@
+package main
+
+import (
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/multiversx/mx-chain-core-go/hashing/blake2b"
+	"github.com/multiversx/mx-chain-core-go/marshal"
+	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/multiversx/mx-chain-logger-go/file"
+	"github.com/multiversx/mx-chain-tools-go/dbmerger/path"
+	"github.com/multiversx/mx-chain-tools-go/dbmerger/storer"
+	"github.com/urfave/cli"
+)
+
+const valuesDelimiter = ","
+const defaultLogsPath = "logs"
+const logFilePrefix = "log"
+
+var (
+	log = logger.GetOrCreate("main")
+
+	dest = cli.StringFlag{
+		Name:  "dest",
+		Usage: "This flag specifies the destination path",
+		Value: "",
+	}
+	sources = cli.StringFlag{
+		Name:  "sources",
+		Usage: `This flag specifies the source paths separated by ",". Example "-sources ` + strings.Join([]string{"path/1", "path/2", "path/3"}, valuesDelimiter) + "\"",
+		Value: "",
+	}
+	rootHashes = cli.StringFlag{
+		Name:  "root-hashes",
+		Usage: `This flag specifies the hex encoded root hashes of the tries to be copied, separated by ",". Example "-root-hashes ` + strings.Join([]string{"0a1b", "2c3d"}, valuesDelimiter) + "\"",
+		Value: "",
+	}
+	skipDataTries = cli.BoolFlag{
+		Name:  "skip-data-tries",
+		Usage: "Boolean option for copying only the main tries. If not set, the data tries of the accounts found in the leaves are copied as well.",
+	}
+	configFile = cli.StringFlag{
+		Name:  "config",
+		Usage: "This flag specifies the TOML file holding the DB and cache options of the sources and of the destination. The default level DB options are used if empty",
+		Value: "",
+	}
+	logLevel = cli.StringFlag{
+		Name: "log-level",
+		Usage: "This flag specifies the logger `level(s)`. It can contain multiple comma-separated value. For example" +
+			", if set to *:INFO the logs for all packages will have the INFO level. However, if set to *:INFO,api:DEBUG" +
+			" the logs for all packages will have the INFO level, excepting the api package which will receive a DEBUG" +
+			" log level.",
+		Value: "*:" + logger.LogDebug.String(),
+	}
+	logSaveFile = cli.BoolFlag{
+		Name:  "log-save",
+		Usage: "Boolean option for enabling log saving. If set, it will automatically save all the logs into a file.",
+	}
+
+	errEmptyPathProvided     = errors.New("empty path provided")
+	errEmptyRootHashProvided = errors.New("empty root hash provided")
+)
+
+const helpTemplate = `NAME:
+   {{.Name}} - {{.Usage}}
+USAGE:
+   {{.HelpName}} {{if .VisibleFlags}}[global options]{{end}}
+   {{if len .Authors}}
+AUTHOR:
+   {{range .Authors}}{{ . }}{{end}}
+   {{end}}{{if .Commands}}
+GLOBAL OPTIONS:
+   {{range .VisibleFlags}}{{.}}
+   {{end}}
+VERSION:
+   {{.Version}}
+   {{end}}
+`
+
+type parsedFlags struct {
+	destPath      string
+	sourcePaths   []string
+	rootHashes    [][]byte
+	skipDataTries bool
+	configFile    string
+	logLevel      string
+	logSave       bool
+}
+
+func main() {
+	app := cli.NewApp()
+	cli.AppHelpTemplate = helpTemplate
+	app.Name = "Trie merger tool CLI App"
+	app.Version = "v1.0.0"
+	app.Usage = "This is the entry point for the trie merge tool able to copy only the reachable trie nodes from 1 or more level DB databases"
+	app.Flags = []cli.Flag{
+		dest,
+		sources,
+		rootHashes,
+		skipDataTries,
+		configFile,
+		logLevel,
+		logSaveFile,
+	}
+	app.Authors = []cli.Author{
+		{
+			Name:  "The MultiversX Team",
+			Email: "contact@multiversx.com",
+		},
+	}
+
+	app.Action = action
+
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func action(ctx *cli.Context) {
+	flags, err := parseFlags(ctx)
+	if err != nil {
+		log.Error("cannot process input flags", "error", err)
+		return
+	}
+
+	err = doAction(flags)
+	if err != nil {
+		log.Error("cannot perform action", "error", err)
+		return
+	}
+
+	log.Info("action performed")
+}
+
+func parseFlags(ctx *cli.Context) (parsedFlags, error) {
+	sourcePaths := ctx.GlobalString(sources.Name)
+
+	flags := parsedFlags{
+		destPath:      ctx.GlobalString(dest.Name),
+		sourcePaths:   strings.Split(sourcePaths, valuesDelimiter),
+		skipDataTries: ctx.GlobalBool(skipDataTries.Name),
+		configFile:    ctx.GlobalString(configFile.Name),
+		logLevel:      ctx.GlobalString(logLevel.Name),
+		logSave:       ctx.GlobalBool(logSaveFile.Name),
+	}
+
+	if len(flags.destPath) == 0 {
+		return parsedFlags{}, fmt.Errorf("%w for `dest` flag", errEmptyPathProvided)
+	}
+	for idx, src := range flags.sourcePaths {
+		if len(src) == 0 {
+			return parsedFlags{}, fmt.Errorf("%w for source flag with index %d", errEmptyPathProvided, idx)
+		}
+	}
+
+	var err error
+	flags.rootHashes, err = parseRootHashes(ctx.GlobalString(rootHashes.Name))
+	if err != nil {
+		return parsedFlags{}, err
+	}
+
+	return flags, nil
+}
+
+func parseRootHashes(value string) ([][]byte, error) {
+	hexRootHashes := strings.Split(value, valuesDelimiter)
+	result := make([][]byte, 0, len(hexRootHashes))
+	for idx, hexRootHash := range hexRootHashes {
+		hexRootHash = strings.TrimSpace(hexRootHash)
+		if len(hexRootHash) == 0 {
+			return nil, fmt.Errorf("%w for `root-hashes` flag with index %d", errEmptyRootHashProvided, idx)
+		}
+
+		rootHash, err := hex.DecodeString(hexRootHash)
+		if err != nil {
+			return nil, fmt.Errorf("%w for `root-hashes` flag with index %d", err, idx)
+		}
+
+		result = append(result, rootHash)
+	}
+
+	return result, nil
+}
+
+func doAction(flags parsedFlags) error {
+	err := processFileLogger(log, flags)
+	if err != nil {
+		return err
+	}
+
+	sourcePersisterCreator, destinationPersisterCreator, err := storer.CreatePersisterCreators(flags.configFile)
+	if err != nil {
+		return err
+	}
+
+	args := storer.ArgsTrieMerger{
+		SourcePersisterCreator:      sourcePersisterCreator,
+		DestinationPersisterCreator: destinationPersisterCreator,
+		OsOperationsHandler:         path.NewOsOperationsHandler(),
+		Marshaller:                  &marshal.GogoProtoMarshalizer{},
+		Hasher:                      blake2b.NewBlake2b(),
+		WithDataTries:               !flags.skipDataTries,
+	}
+	trieMerger, err := storer.NewTrieMerger(args)
+	if err != nil {
+		return err
+	}
+
+	destDB, err := trieMerger.MergeTries(flags.destPath, flags.rootHashes, flags.sourcePaths...)
+	if err != nil {
+		return err
+	}
+
+	return destDB.Close()
+}
+
+func processFileLogger(log logger.Logger, flags parsedFlags) error {
+	var err error
+	if flags.logSave {
+		_, err = file.NewFileLogging(file.ArgsFileLogging{
+			WorkingDir:      "",
+			DefaultLogsPath: defaultLogsPath,
+			LogFilePrefix:   logFilePrefix,
+		})
+		if err != nil {
+			return fmt.Errorf("%w creating a log file", err)
+		}
+	}
+
+	err = logger.SetLogLevel(flags.logLevel)
+	if err != nil {
+		return err
+	}
+
+	log.Trace("logger updated", "level", flags.logLevel)
+
+	return nil
+}
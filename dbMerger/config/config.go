@@ -0,0 +1,56 @@
+package config
+
+import (
+	"github.com/multiversx/mx-chain-core-go/core"
+	"github.com/multiversx/mx-chain-storage-go/storageUnit"
+)
+
+const (
+	defaultBatchDelaySeconds = 2
+	defaultMaxBatchSize      = 10000
+	defaultMaxOpenFiles      = 10
+)
+
+// PersisterConfig holds the options used when opening a DB. The DB.FilePath value is not used, the paths are provided
+// as flags. No cache is used if the Cache.Type value is empty
+type PersisterConfig struct {
+	DB    storageUnit.DBConfig
+	Cache storageUnit.CacheConfig
+}
+
+// Config holds the persister options, separately for the source DBs and the destination DB
+type Config struct {
+	Source      PersisterConfig
+	Destination PersisterConfig
+}
+
+// DefaultPersisterConfig returns the options of a level DB without cache
+func DefaultPersisterConfig() PersisterConfig {
+	return PersisterConfig{
+		DB: storageUnit.DBConfig{
+			Type:              storageUnit.LvlDB,
+			BatchDelaySeconds: defaultBatchDelaySeconds,
+			MaxBatchSize:      defaultMaxBatchSize,
+			MaxOpenFiles:      defaultMaxOpenFiles,
+		},
+	}
+}
+
+// DefaultConfig returns the default options for both the source DBs and the destination DB
+func DefaultConfig() *Config {
+	return &Config{
+		Source:      DefaultPersisterConfig(),
+		Destination: DefaultPersisterConfig(),
+	}
+}
+
+// LoadConfig loads the config from the provided TOML file. The values missing from the file keep their defaults
+func LoadConfig(filePath string) (*Config, error) {
+	cfg := DefaultConfig()
+	err := core.LoadTomlFile(cfg, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return cfg, nil
+}
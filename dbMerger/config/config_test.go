@@ -0,0 +1,65 @@
+package config
+
+import (
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/multiversx/mx-chain-storage-go/storageUnit"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoadConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing file should error", func(t *testing.T) {
+		t.Parallel()
+
+		cfg, err := LoadConfig(filepath.Join(t.TempDir(), "missing.toml"))
+		assert.Nil(t, cfg)
+		assert.NotNil(t, err)
+	})
+	t.Run("example config files should load", func(t *testing.T) {
+		t.Parallel()
+
+		for _, configFile := range []string{"../cmd/generalDBMerger/config.toml", "../cmd/trieMerger/config.toml"} {
+			cfg, err := LoadConfig(configFile)
+			assert.Nil(t, err)
+			assert.Equal(t, DefaultPersisterConfig().DB, cfg.Source.DB)
+			assert.Equal(t, DefaultPersisterConfig().DB, cfg.Destination.DB)
+		}
+	})
+	t.Run("should keep the defaults for the missing values", func(t *testing.T) {
+		t.Parallel()
+
+		configFile := filepath.Join(t.TempDir(), "config.toml")
+		configContent := `
+[Source]
+    [Source.DB]
+        Type = "LvlDBSerial"
+        MaxOpenFiles = 100
+[Destination]
+    [Destination.Cache]
+        Name = "destination"
+        Type = "LRU"
+        Capacity = 50000
+`
+		assert.Nil(t, ioutil.WriteFile(configFile, []byte(configContent), 0644))
+
+		cfg, err := LoadConfig(configFile)
+		assert.Nil(t, err)
+
+		expectedSource := DefaultPersisterConfig()
+		expectedSource.DB.Type = storageUnit.LvlDBSerial
+		expectedSource.DB.MaxOpenFiles = 100
+		assert.Equal(t, expectedSource, cfg.Source)
+
+		expectedDestination := DefaultPersisterConfig()
+		expectedDestination.Cache = storageUnit.CacheConfig{
+			Name:     "destination",
+			Type:     storageUnit.LRUCache,
+			Capacity: 50000,
+		}
+		assert.Equal(t, expectedDestination, cfg.Destination)
+	})
+}
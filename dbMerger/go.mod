@@ -23,7 +23,9 @@ require (
 	github.com/kr/pretty v0.3.0 // indirect
 	github.com/mr-tron/base58 v1.2.0 // indirect
 	github.com/multiversx/concurrent-map v0.1.4 // indirect
+	github.com/multiversx/mx-chain-crypto-go v1.2.5 // indirect
 	github.com/multiversx/mx-chain-p2p-go v1.0.10 // indirect
+	github.com/multiversx/mx-chain-vm-common-go v1.3.36 // indirect
 	github.com/onsi/gomega v1.13.0 // indirect
 	github.com/pelletier/go-toml v1.9.3 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
@@ -1,6 +1,9 @@
 package integrationTests
 
 import (
+	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/multiversx/mx-chain-tools-go/dbmerger/path"
@@ -18,9 +21,10 @@ func TestFullDBMergerWith3Persisters(t *testing.T) {
 	dbPathDest := t.TempDir()
 
 	args := storer.ArgsFullDBMerger{
-		DataMergerInstance:  storer.NewDataMerger(),
-		PersisterCreator:    persisterCreator,
-		OsOperationsHandler: path.NewOsOperationsHandler(),
+		DataMergerInstance:          storer.NewDataMerger(),
+		SourcePersisterCreator:      persisterCreator,
+		DestinationPersisterCreator: persisterCreator,
+		OsOperationsHandler:         path.NewOsOperationsHandler(),
 	}
 	fullDataMerger, err := storer.NewFullDBMerger(args)
 	assert.Nil(t, err)
@@ -31,6 +35,46 @@ func TestFullDBMergerWith3Persisters(t *testing.T) {
 	writeChecker.CheckDB(t, dest)
 }
 
+func TestFullDBMergerWithNodeDBLayout(t *testing.T) {
+	persisterCreator := storer.NewPersisterCreator()
+	writeChecker := NewDBDataWriteChecker()
+
+	dbRootPath := filepath.Join(t.TempDir(), "db")
+	assert.Nil(t, os.MkdirAll(filepath.Join(dbRootPath, "1", "Static", "Shard_0"), os.ModePerm))
+	overriddenKey := []byte("overridden key")
+	for epoch := 3; epoch <= 5; epoch++ {
+		unitPath := filepath.Join(dbRootPath, "1", fmt.Sprintf("Epoch_%d", epoch), "Shard_0", "BlockHeaders")
+		db, err := persisterCreator.CreatePersister(unitPath)
+		assert.Nil(t, err)
+		writeChecker.AddDataToDB(db, 10)
+		assert.Nil(t, db.Put(overriddenKey, []byte(fmt.Sprintf("epoch %d", epoch))))
+		_ = db.Close()
+	}
+
+	parser := path.NewParser(dbRootPath)
+	assert.Nil(t, parser.ParseDirectory())
+	sourcePaths, err := parser.StorageUnitPaths("BlockHeaders")
+	assert.Nil(t, err)
+	assert.Equal(t, 3, len(sourcePaths))
+
+	args := storer.ArgsFullDBMerger{
+		DataMergerInstance:          storer.NewDataMerger(),
+		SourcePersisterCreator:      persisterCreator,
+		DestinationPersisterCreator: persisterCreator,
+		OsOperationsHandler:         path.NewOsOperationsHandler(),
+	}
+	fullDataMerger, err := storer.NewFullDBMerger(args)
+	assert.Nil(t, err)
+
+	dest, err := fullDataMerger.MergeDBs(t.TempDir(), sourcePaths...)
+	assert.Nil(t, err)
+
+	writeChecker.CheckDB(t, dest)
+	value, err := dest.Get(overriddenKey)
+	assert.Nil(t, err)
+	assert.Equal(t, "epoch 5", string(value))
+}
+
 func createDBAndAddData(tb testing.TB, persisterCreator storer.PersisterCreator, writeChecker *dbDataWriteChecker, numData int) string {
 	dbPath := tb.TempDir()
 	db, err := persisterCreator.CreatePersister(dbPath)
@@ -9,4 +9,8 @@ var (
 	errMissingStaticDirectory    = errors.New("missing Static directory")
 	errInvalidShardIDDirectory   = errors.New("invalid shard ID directory")
 	errDirectoryIsNotEmpty       = errors.New("directory is not empty")
+	errDirectoryNotParsed        = errors.New("the db root directory was not parsed")
+	errEmptyStorageUnitName      = errors.New("empty storage unit name")
+	errNotContinuousEpochs       = errors.New("the epochs are not continuous")
+	errMissingStorageUnit        = errors.New("missing storage unit directory")
 )
@@ -24,6 +24,7 @@ type parser struct {
 	chainID               string
 	highestEpoch          uint64
 	lowestContinuousEpoch uint64
+	lowestEpoch           uint64
 	foundStatic           bool
 	shardID               uint64
 }
@@ -77,6 +78,7 @@ func (p *parser) resetData() {
 	p.chainID = ""
 	p.highestEpoch = 0
 	p.lowestContinuousEpoch = 0
+	p.lowestEpoch = 0
 	p.foundStatic = false
 	p.shardID = 0
 }
@@ -133,6 +135,7 @@ func (p *parser) processEpochs(directories []os.DirEntry) error {
 
 	p.highestEpoch = uint64(epochs[0])
 	p.lowestContinuousEpoch = uint64(epochs[0])
+	p.lowestEpoch = uint64(epochs[len(epochs)-1])
 	for i := 1; i < len(epochs); i++ {
 		if epochs[i] == int(p.lowestContinuousEpoch)-1 {
 			p.lowestContinuousEpoch--
@@ -202,3 +205,53 @@ func (p *parser) ShardID() uint64 {
 
 	return p.shardID
 }
+
+// StorageUnitPaths returns the paths of the provided storage unit from all the epoch directories, in ascending
+// epoch order. The unit name can contain sub-directories (AccountsTrie/MainDB). It errors if the epochs
+// are not continuous or if an epoch directory does not contain the storage unit. ParseDirectory should be called first
+func (p *parser) StorageUnitPaths(unitName string) ([]string, error) {
+	p.mutData.RLock()
+	defer p.mutData.RUnlock()
+
+	if len(p.chainID) == 0 {
+		return nil, errDirectoryNotParsed
+	}
+	if len(unitName) == 0 {
+		return nil, errEmptyStorageUnitName
+	}
+	if p.lowestEpoch != p.lowestContinuousEpoch {
+		return nil, fmt.Errorf("%w, found epoch %d below the continuous epochs %d-%d",
+			errNotContinuousEpochs, p.lowestEpoch, p.lowestContinuousEpoch, p.highestEpoch)
+	}
+
+	shardDirectory := fmt.Sprintf("%s%d", shardPathPart, p.shardID)
+	unitPaths := make([]string, 0, p.highestEpoch-p.lowestEpoch+1)
+	for epoch := p.lowestEpoch; epoch <= p.highestEpoch; epoch++ {
+		epochDirectory := fmt.Sprintf("%s%d", epochPathPart, epoch)
+		unitPath := path.Join(p.dbRootPath, p.chainID, epochDirectory, shardDirectory, unitName)
+		err := p.checkDirectoryExists(unitPath)
+		if err != nil {
+			return nil, fmt.Errorf("%w for epoch %d", err, epoch)
+		}
+
+		unitPaths = append(unitPaths, unitPath)
+	}
+
+	return unitPaths, nil
+}
+
+func (p *parser) checkDirectoryExists(directoryPath string) error {
+	parentPath, name := path.Split(directoryPath)
+	directories, err := p.readDirectories(path.Clean(parentPath))
+	if err != nil {
+		return fmt.Errorf("%w, path %s, inner error %s", errMissingStorageUnit, directoryPath, err.Error())
+	}
+
+	for _, dir := range directories {
+		if dir.Name() == name {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w, path %s", errMissingStorageUnit, directoryPath)
+}
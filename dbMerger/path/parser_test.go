@@ -161,3 +161,83 @@ func TestParser_ParseDirectory(t *testing.T) {
 		assert.Equal(t, uint64(1), p.ShardID())
 	})
 }
+
+func TestParser_StorageUnitPaths(t *testing.T) {
+	t.Parallel()
+
+	t.Run("directory not parsed should error", func(t *testing.T) {
+		t.Parallel()
+
+		p := NewParser("db")
+
+		unitPaths, err := p.StorageUnitPaths("BlockHeaders")
+		assert.Nil(t, unitPaths)
+		assert.Equal(t, errDirectoryNotParsed, err)
+	})
+	t.Run("empty unit name should error", func(t *testing.T) {
+		t.Parallel()
+
+		ds := createMockDirectoryStructure()
+		p := NewParser("db")
+		p.dirReadHandler = ds.ListDirectory
+		_ = p.ParseDirectory()
+
+		unitPaths, err := p.StorageUnitPaths("")
+		assert.Nil(t, unitPaths)
+		assert.Equal(t, errEmptyStorageUnitName, err)
+	})
+	t.Run("not continuous epochs should error", func(t *testing.T) {
+		t.Parallel()
+
+		ds := createMockDirectoryStructure()
+		ds.AddPath("db/1/Epoch_721/Shard_1", true)
+		p := NewParser("db")
+		p.dirReadHandler = ds.ListDirectory
+		_ = p.ParseDirectory()
+
+		unitPaths, err := p.StorageUnitPaths("BlockHeaders")
+		assert.Nil(t, unitPaths)
+		assert.True(t, errors.Is(err, errNotContinuousEpochs))
+		assert.True(t, strings.Contains(err.Error(), "found epoch 721 below the continuous epochs 724-726"))
+	})
+	t.Run("missing storage unit should error", func(t *testing.T) {
+		t.Parallel()
+
+		ds := createMockDirectoryStructure()
+		ds.AddPath("db/1/Epoch_724/Shard_1/BlockHeaders", true)
+		ds.AddPath("db/1/Epoch_726/Shard_1/BlockHeaders", true)
+		p := NewParser("db")
+		p.dirReadHandler = ds.ListDirectory
+		_ = p.ParseDirectory()
+
+		unitPaths, err := p.StorageUnitPaths("BlockHeaders")
+		assert.Nil(t, unitPaths)
+		assert.True(t, errors.Is(err, errMissingStorageUnit))
+		assert.True(t, strings.Contains(err.Error(), "db/1/Epoch_725/Shard_1/BlockHeaders for epoch 725"))
+	})
+	t.Run("should work", func(t *testing.T) {
+		t.Parallel()
+
+		ds := createMockDirectoryStructure()
+		for _, epoch := range []int{726, 724, 725} {
+			ds.AddPath(fmt.Sprintf("db/1/Epoch_%d/Shard_1/AccountsTrie/MainDB", epoch), true)
+		}
+		ds.AddPath("db/1/Epoch_724/Shard_2/BlockHeaders", true)
+		p := NewParser("db")
+		p.dirReadHandler = ds.ListDirectory
+		_ = p.ParseDirectory()
+
+		unitPaths, err := p.StorageUnitPaths("AccountsTrie/MainDB")
+		assert.Nil(t, err)
+		expectedPaths := []string{
+			"db/1/Epoch_724/Shard_1/AccountsTrie/MainDB",
+			"db/1/Epoch_725/Shard_1/AccountsTrie/MainDB",
+			"db/1/Epoch_726/Shard_1/AccountsTrie/MainDB",
+		}
+		assert.Equal(t, expectedPaths, unitPaths)
+
+		unitPaths, err = p.StorageUnitPaths("BlockHeaders")
+		assert.Nil(t, unitPaths)
+		assert.True(t, errors.Is(err, errMissingStorageUnit))
+	})
+}
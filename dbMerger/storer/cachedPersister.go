@@ -0,0 +1,97 @@
+package storer
+
+import (
+	"github.com/multiversx/mx-chain-storage-go/types"
+)
+
+// cachedPersister keeps the recently written and read values in a cache, in front of the persister
+type cachedPersister struct {
+	persister types.Persister
+	cacher    types.Cacher
+}
+
+func newCachedPersister(persister types.Persister, cacher types.Cacher) *cachedPersister {
+	return &cachedPersister{
+		persister: persister,
+		cacher:    cacher,
+	}
+}
+
+// Put writes the value in the persister and in the cache
+func (cp *cachedPersister) Put(key, val []byte) error {
+	err := cp.persister.Put(key, val)
+	if err != nil {
+		return err
+	}
+
+	cp.cacher.Put(key, val, len(val))
+
+	return nil
+}
+
+// Get returns the value from the cache or, if not cached, from the persister
+func (cp *cachedPersister) Get(key []byte) ([]byte, error) {
+	cachedValue, found := cp.cacher.Get(key)
+	if found {
+		val, ok := cachedValue.([]byte)
+		if ok {
+			return val, nil
+		}
+	}
+
+	val, err := cp.persister.Get(key)
+	if err != nil {
+		return nil, err
+	}
+
+	cp.cacher.Put(key, val, len(val))
+
+	return val, nil
+}
+
+// Has returns nil if the key is cached or present in the persister
+func (cp *cachedPersister) Has(key []byte) error {
+	if cp.cacher.Has(key) {
+		return nil
+	}
+
+	return cp.persister.Has(key)
+}
+
+// Close clears the cache and closes the persister
+func (cp *cachedPersister) Close() error {
+	cp.cacher.Clear()
+
+	return cp.persister.Close()
+}
+
+// Remove removes the key from the cache and from the persister
+func (cp *cachedPersister) Remove(key []byte) error {
+	cp.cacher.Remove(key)
+
+	return cp.persister.Remove(key)
+}
+
+// Destroy clears the cache and removes the persister data
+func (cp *cachedPersister) Destroy() error {
+	cp.cacher.Clear()
+
+	return cp.persister.Destroy()
+}
+
+// DestroyClosed clears the cache and removes the closed persister data
+func (cp *cachedPersister) DestroyClosed() error {
+	cp.cacher.Clear()
+
+	return cp.persister.DestroyClosed()
+}
+
+// RangeKeys iterates over the persister's key-value pairs
+func (cp *cachedPersister) RangeKeys(handler func(key []byte, val []byte) bool) {
+	cp.persister.RangeKeys(handler)
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (cp *cachedPersister) IsInterfaceNil() bool {
+	return cp == nil
+}
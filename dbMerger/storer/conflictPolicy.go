@@ -0,0 +1,43 @@
+package storer
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ConflictPolicy defines which value is kept when a key has different values in the merged persisters
+type ConflictPolicy string
+
+const (
+	// LastWinsPolicy keeps the value from the last source containing the key
+	LastWinsPolicy ConflictPolicy = "last-wins"
+	// FirstWinsPolicy keeps the value from the first source containing the key
+	FirstWinsPolicy ConflictPolicy = "first-wins"
+	// FailOnConflictPolicy stops the merge when a key has different values
+	FailOnConflictPolicy ConflictPolicy = "fail-on-conflict"
+	// ComparatorPolicy lets the provided ConflictComparator choose the value
+	ComparatorPolicy ConflictPolicy = "comparator"
+)
+
+// ConflictComparator is called for a key that has different values in the destination and in a source. It returns
+// true if the source value should replace the destination value
+type ConflictComparator func(key []byte, destValue []byte, sourceValue []byte) bool
+
+var policiesAvailableFromFlags = []ConflictPolicy{LastWinsPolicy, FirstWinsPolicy, FailOnConflictPolicy}
+
+// ParseConflictPolicy returns the conflict policy with the provided name. The comparator policy can not be parsed as it
+// requires a ConflictComparator function
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	for _, policy := range policiesAvailableFromFlags {
+		if string(policy) == name {
+			return policy, nil
+		}
+	}
+
+	names := make([]string, 0, len(policiesAvailableFromFlags))
+	for _, policy := range policiesAvailableFromFlags {
+		names = append(names, string(policy))
+	}
+
+	return "", fmt.Errorf("%w %s, available policies: %s", errInvalidConflictPolicy, name, strings.Join(names, ", "))
+}
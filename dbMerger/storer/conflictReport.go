@@ -0,0 +1,65 @@
+package storer
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"sort"
+)
+
+const reportFilePermissions = 0644
+
+// conflictValue is a value found for a conflicting key. The destination's initial value has the source index 0 and the
+// sources are numbered from 1
+type conflictValue struct {
+	SourceIndex int    `json:"sourceIndex"`
+	ValueHash   string `json:"valueHash"`
+}
+
+// keyConflict holds the different values found for a key and the hash of the value kept
+type keyConflict struct {
+	Key           string          `json:"key"`
+	Values        []conflictValue `json:"values"`
+	KeptValueHash string          `json:"keptValueHash"`
+}
+
+// conflictReport is written after a merge, if a report path was provided
+type conflictReport struct {
+	Policy       ConflictPolicy `json:"policy"`
+	NumSources   int            `json:"numSources"`
+	NumConflicts int            `json:"numConflicts"`
+	Conflicts    []*keyConflict `json:"conflicts"`
+}
+
+func newConflictReport(policy ConflictPolicy, numSources int, conflicts map[string]*keyConflict) *conflictReport {
+	report := &conflictReport{
+		Policy:       policy,
+		NumSources:   numSources,
+		NumConflicts: len(conflicts),
+		Conflicts:    make([]*keyConflict, 0, len(conflicts)),
+	}
+	for _, conflict := range conflicts {
+		report.Conflicts = append(report.Conflicts, conflict)
+	}
+	sort.Slice(report.Conflicts, func(i, j int) bool {
+		return report.Conflicts[i].Key < report.Conflicts[j].Key
+	})
+
+	return report
+}
+
+func (report *conflictReport) writeToFile(reportPath string) error {
+	reportBytes, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(reportPath, reportBytes, reportFilePermissions)
+}
+
+func computeValueHash(value []byte) string {
+	hash := sha256.Sum256(value)
+
+	return hex.EncodeToString(hash[:])
+}
@@ -1,7 +1,11 @@
 package storer
 
 import (
+	"bytes"
+	"encoding/hex"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/multiversx/mx-chain-core-go/core/check"
 	logger "github.com/multiversx/mx-chain-logger-go"
@@ -10,39 +14,210 @@ import (
 
 var log = logger.GetOrCreate("storer")
 
-// dataMerger is able to copy key by key all values from the provided sources persisters into the destination persister
+const (
+	// destinationSourceIndex is the source index reported for the values that were already in the destination persister.
+	// The sources are reported from 1, so the indices match the sources list of a full merge, that starts by copying
+	// its first source as the destination
+	destinationSourceIndex = 0
+	// DefaultBatchSize is the default number of key-value pairs read from a source in one batch
+	DefaultBatchSize = 10000
+	// DefaultNumParallelReaders is the default number of sources read at the same time
+	DefaultNumParallelReaders = 2
+	// readAheadBatches is the number of batches a reader can prepare before they are written
+	readAheadBatches    = 4
+	progressLogInterval = 30 * time.Second
+)
+
+// keyValue is a key-value pair read from a source
+type keyValue struct {
+	key   []byte
+	value []byte
+}
+
+// ArgsDataMerger is the DTO used in the NewDataMergerWithArgs constructor function
+type ArgsDataMerger struct {
+	ConflictPolicy ConflictPolicy
+	// Comparator is required by the ComparatorPolicy and ignored by the other policies
+	Comparator ConflictComparator
+	// ReportPath is the file where the conflicts are written after each merge. No report is written if empty
+	ReportPath string
+	// BatchSize is the number of key-value pairs read from a source in one batch
+	BatchSize int
+	// NumParallelReaders is the number of sources read at the same time, ahead of the writer
+	NumParallelReaders int
+}
+
+// dataMerger is able to copy key by key all values from the provided sources persisters into the destination persister.
+// The sources are read concurrently, in batches, while a single writer stores the batches in the sources order
 type dataMerger struct {
+	conflictPolicy      ConflictPolicy
+	comparator          ConflictComparator
+	reportPath          string
+	batchSize           int
+	numParallelReaders  int
+	progressLogInterval time.Duration
 }
 
-// NewDataMerger returns a new instance of a data merger
+// NewDataMerger returns a new instance of a data merger that keeps the value from the last source on conflicts
 func NewDataMerger() *dataMerger {
-	return &dataMerger{}
+	return &dataMerger{
+		conflictPolicy:      LastWinsPolicy,
+		batchSize:           DefaultBatchSize,
+		numParallelReaders:  DefaultNumParallelReaders,
+		progressLogInterval: progressLogInterval,
+	}
+}
+
+// NewDataMergerWithArgs returns a new instance of a data merger that resolves the conflicts with the provided policy
+func NewDataMergerWithArgs(args ArgsDataMerger) (*dataMerger, error) {
+	switch args.ConflictPolicy {
+	case LastWinsPolicy, FirstWinsPolicy, FailOnConflictPolicy:
+	case ComparatorPolicy:
+		if args.Comparator == nil {
+			return nil, errNilConflictComparator
+		}
+	default:
+		return nil, fmt.Errorf("%w %s", errInvalidConflictPolicy, args.ConflictPolicy)
+	}
+	if args.BatchSize < 1 {
+		return nil, fmt.Errorf("%w, BatchSize %d", errInvalidValue, args.BatchSize)
+	}
+	if args.NumParallelReaders < 1 {
+		return nil, fmt.Errorf("%w, NumParallelReaders %d", errInvalidValue, args.NumParallelReaders)
+	}
+
+	return &dataMerger{
+		conflictPolicy:      args.ConflictPolicy,
+		comparator:          args.Comparator,
+		reportPath:          args.ReportPath,
+		batchSize:           args.BatchSize,
+		numParallelReaders:  args.NumParallelReaders,
+		progressLogInterval: progressLogInterval,
+	}, nil
 }
 
-// MergeDBs will iterate over all provided sources and take all key-value pairs and write them in the destination persister
+// MergeDBs will iterate over all provided sources and take all key-value pairs and write them in the destination persister.
+// A key that already exists in the destination with a different value is a conflict, resolved with the conflict policy
 func (dm *dataMerger) MergeDBs(dest types.Persister, sources ...types.Persister) error {
 	err := checkArgs(dest, sources...)
 	if err != nil {
 		return err
 	}
 
+	conflicts := make(map[string]*keyConflict)
+	err = dm.mergeSources(dest, sources, conflicts)
+	if len(dm.reportPath) > 0 {
+		errReport := newConflictReport(dm.conflictPolicy, len(sources)+1, conflicts).writeToFile(dm.reportPath)
+		if errReport != nil {
+			log.Error("cannot write the conflict report", "path", dm.reportPath, "error", errReport)
+		}
+	}
+	if len(conflicts) > 0 {
+		log.Warn("found keys with different values", "num conflicts", len(conflicts), "policy", dm.conflictPolicy)
+	}
+
+	return err
+}
+
+// mergeSources writes the batches of each source in order, while the next sources are already being read. The readers
+// are stopped and waited for before returning, so the caller can close the sources
+func (dm *dataMerger) mergeSources(dest types.Persister, sources []types.Persister, conflicts map[string]*keyConflict) error {
+	done := make(chan struct{})
+	readersWaitGroup := &sync.WaitGroup{}
+	defer func() {
+		close(done)
+		readersWaitGroup.Wait()
+	}()
+
+	readerSlots := make(chan struct{}, dm.numParallelReaders)
+	batchesPerSource := dm.startReaders(sources, readerSlots, done, readersWaitGroup)
+	progress := newMergeProgress(dm.progressLogInterval)
 	numKeys := 0
+	for idx, batches := range batchesPerSource {
+		for batch := range batches {
+			copiedKeys, errMerge := dm.writeBatch(dest, sources, idx, batch, conflicts)
+			if errMerge != nil {
+				return errMerge
+			}
 
-	for _, source := range sources {
-		copiedKeys, errMerge := mergeDB(dest, source)
-		if errMerge != nil {
-			return errMerge
+			numKeys += copiedKeys
+			progress.addBatch(batch, reportedSourceIndex(idx))
 		}
 
-		numKeys += copiedKeys
+		<-readerSlots
 	}
 
-	log.Debug("finished copying data",
-		"num source persisters", len(sources), "num key-values copied", numKeys)
+	log.Debug("finished copying data", append([]interface{}{
+		"num source persisters", len(sources), "num key-values copied", numKeys}, progress.logArgs()...)...)
 
 	return nil
 }
 
+// startReaders starts reading the sources in order, at most len(readerSlots) at the same time. A slot is released by the
+// writer after all the batches of a source were written. The provided wait group is done when all the readers returned
+func (dm *dataMerger) startReaders(
+	sources []types.Persister,
+	readerSlots chan struct{},
+	done chan struct{},
+	readersWaitGroup *sync.WaitGroup,
+) []chan []keyValue {
+	batchesPerSource := make([]chan []keyValue, 0, len(sources))
+	for range sources {
+		batchesPerSource = append(batchesPerSource, make(chan []keyValue, readAheadBatches))
+	}
+
+	readersWaitGroup.Add(1)
+	go func() {
+		defer readersWaitGroup.Done()
+
+		for idx, source := range sources {
+			select {
+			case readerSlots <- struct{}{}:
+			case <-done:
+				return
+			}
+
+			readersWaitGroup.Add(1)
+			go func(source types.Persister, batches chan<- []keyValue) {
+				defer readersWaitGroup.Done()
+				dm.readSource(source, batches, done)
+			}(source, batchesPerSource[idx])
+		}
+	}()
+
+	return batchesPerSource
+}
+
+func (dm *dataMerger) readSource(source types.Persister, batches chan<- []keyValue, done <-chan struct{}) {
+	defer close(batches)
+
+	batch := make([]keyValue, 0, dm.batchSize)
+	source.RangeKeys(func(key []byte, val []byte) bool {
+		batch = append(batch, keyValue{key: key, value: val})
+		if len(batch) < dm.batchSize {
+			return true
+		}
+
+		sent := sendBatch(batches, batch, done)
+		batch = make([]keyValue, 0, dm.batchSize)
+
+		return sent
+	})
+
+	if len(batch) > 0 {
+		_ = sendBatch(batches, batch, done)
+	}
+}
+
+func sendBatch(batches chan<- []keyValue, batch []keyValue, done <-chan struct{}) bool {
+	select {
+	case batches <- batch:
+		return true
+	case <-done:
+		return false
+	}
+}
+
 func checkArgs(dest types.Persister, sources ...types.Persister) error {
 	if check.IfNil(dest) {
 		return fmt.Errorf("%w for the destination persister", errNilPersister)
@@ -56,20 +231,119 @@ func checkArgs(dest types.Persister, sources ...types.Persister) error {
 	return nil
 }
 
-func mergeDB(dest types.Persister, source types.Persister) (int, error) {
-	var foundErr error
+// writeBatch writes the batch values that should be kept in the destination and returns their number. The destination
+// is read only if a value can be dropped or a conflict has to be reported
+func (dm *dataMerger) writeBatch(dest types.Persister, sources []types.Persister, sourceIndex int, batch []keyValue, conflicts map[string]*keyConflict) (int, error) {
+	shouldReadDestination := dm.needsExistingValues()
 	numKeysCopied := 0
-	source.RangeKeys(func(key []byte, val []byte) bool {
+	for _, kv := range batch {
+		if shouldReadDestination {
+			shouldPut, err := dm.resolve(dest, sources, sourceIndex, kv.key, kv.value, conflicts)
+			if err != nil {
+				return numKeysCopied, err
+			}
+			if !shouldPut {
+				continue
+			}
+		}
+
+		err := dest.Put(kv.key, kv.value)
+		if err != nil {
+			return numKeysCopied, err
+		}
 		numKeysCopied++
-		foundErr = dest.Put(key, val)
-		if foundErr != nil {
-			return false
+	}
+
+	return numKeysCopied, nil
+}
+
+// needsExistingValues returns false if every source value is written and no conflict is reported
+func (dm *dataMerger) needsExistingValues() bool {
+	return dm.conflictPolicy != LastWinsPolicy || len(dm.reportPath) > 0
+}
+
+// resolve returns true if the source value should be written in the destination
+func (dm *dataMerger) resolve(
+	dest types.Persister,
+	sources []types.Persister,
+	sourceIndex int,
+	key []byte,
+	val []byte,
+	conflicts map[string]*keyConflict,
+) (bool, error) {
+	existingValue, err := dest.Get(key)
+	keyExists := err == nil && existingValue != nil
+	if !keyExists {
+		return true, nil
+	}
+	if bytes.Equal(existingValue, val) {
+		return false, nil
+	}
+
+	conflict := dm.recordConflict(sources, sourceIndex, key, existingValue, val, conflicts)
+	shouldPut := false
+	switch dm.conflictPolicy {
+	case LastWinsPolicy:
+		shouldPut = true
+	case FailOnConflictPolicy:
+		return false, fmt.Errorf("%w for key %s, source index %d", errConflictFound, conflict.Key, reportedSourceIndex(sourceIndex))
+	case ComparatorPolicy:
+		shouldPut = dm.comparator(key, existingValue, val)
+	}
+
+	if shouldPut {
+		conflict.KeptValueHash = computeValueHash(val)
+	}
+
+	return shouldPut, nil
+}
+
+func (dm *dataMerger) recordConflict(
+	sources []types.Persister,
+	sourceIndex int,
+	key []byte,
+	existingValue []byte,
+	val []byte,
+	conflicts map[string]*keyConflict,
+) *keyConflict {
+	conflict, found := conflicts[string(key)]
+	if !found {
+		existingValueHash := computeValueHash(existingValue)
+		conflict = &keyConflict{
+			Key:           hex.EncodeToString(key),
+			KeptValueHash: existingValueHash,
+			Values: []conflictValue{{
+				SourceIndex: findValueSourceIndex(sources[:sourceIndex], key, existingValue),
+				ValueHash:   existingValueHash,
+			}},
 		}
+		conflicts[string(key)] = conflict
+	}
 
-		return true
+	conflict.Values = append(conflict.Values, conflictValue{
+		SourceIndex: reportedSourceIndex(sourceIndex),
+		ValueHash:   computeValueHash(val),
 	})
 
-	return numKeysCopied, foundErr
+	return conflict
+}
+
+// findValueSourceIndex returns the reported index of the last source holding the value, or destinationSourceIndex if
+// the value was already in the destination
+func findValueSourceIndex(sources []types.Persister, key []byte, value []byte) int {
+	for idx := len(sources) - 1; idx >= 0; idx-- {
+		sourceValue, err := sources[idx].Get(key)
+		if err == nil && bytes.Equal(sourceValue, value) {
+			return reportedSourceIndex(idx)
+		}
+	}
+
+	return destinationSourceIndex
+}
+
+// reportedSourceIndex returns the index of a source in the report and in the logs, after the destination's index
+func reportedSourceIndex(sourceIndex int) int {
+	return sourceIndex + 1
 }
 
 // IsInterfaceNil returns true if there is no value under the interface
@@ -1,11 +1,19 @@
 package storer
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"errors"
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
 	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/multiversx/mx-chain-core-go/core/check"
+	"github.com/multiversx/mx-chain-storage-go/types"
 	"github.com/multiversx/mx-chain-tools-go/dbmerger/mock"
 	"github.com/stretchr/testify/assert"
 )
@@ -129,3 +137,341 @@ func checkMapContained(tb testing.TB, mainMap map[string]string, subset map[stri
 		assert.Equal(tb, val, recovered)
 	}
 }
+
+func createMockArgsDataMerger(policy ConflictPolicy) ArgsDataMerger {
+	return ArgsDataMerger{
+		ConflictPolicy:     policy,
+		BatchSize:          2,
+		NumParallelReaders: 2,
+	}
+}
+
+func TestNewDataMergerWithArgs(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid conflict policy should error", func(t *testing.T) {
+		t.Parallel()
+
+		dm, err := NewDataMergerWithArgs(createMockArgsDataMerger("random"))
+		assert.True(t, check.IfNil(dm))
+		assert.True(t, errors.Is(err, errInvalidConflictPolicy))
+	})
+	t.Run("comparator policy with nil comparator should error", func(t *testing.T) {
+		t.Parallel()
+
+		dm, err := NewDataMergerWithArgs(createMockArgsDataMerger(ComparatorPolicy))
+		assert.True(t, check.IfNil(dm))
+		assert.Equal(t, errNilConflictComparator, err)
+	})
+	t.Run("invalid batch size should error", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsDataMerger(LastWinsPolicy)
+		args.BatchSize = 0
+		dm, err := NewDataMergerWithArgs(args)
+		assert.True(t, check.IfNil(dm))
+		assert.True(t, errors.Is(err, errInvalidValue))
+		assert.True(t, strings.Contains(err.Error(), "BatchSize"))
+	})
+	t.Run("invalid number of parallel readers should error", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsDataMerger(LastWinsPolicy)
+		args.NumParallelReaders = 0
+		dm, err := NewDataMergerWithArgs(args)
+		assert.True(t, check.IfNil(dm))
+		assert.True(t, errors.Is(err, errInvalidValue))
+		assert.True(t, strings.Contains(err.Error(), "NumParallelReaders"))
+	})
+	t.Run("should work", func(t *testing.T) {
+		t.Parallel()
+
+		for _, policy := range []ConflictPolicy{LastWinsPolicy, FirstWinsPolicy, FailOnConflictPolicy} {
+			dm, err := NewDataMergerWithArgs(createMockArgsDataMerger(policy))
+			assert.False(t, check.IfNil(dm))
+			assert.Nil(t, err)
+		}
+
+		args := createMockArgsDataMerger(ComparatorPolicy)
+		args.Comparator = func(key []byte, destValue []byte, sourceValue []byte) bool {
+			return true
+		}
+		dm, err := NewDataMergerWithArgs(args)
+		assert.False(t, check.IfNil(dm))
+		assert.Nil(t, err)
+	})
+}
+
+func TestParseConflictPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy, err := ParseConflictPolicy("first-wins")
+	assert.Nil(t, err)
+	assert.Equal(t, FirstWinsPolicy, policy)
+
+	policy, err = ParseConflictPolicy(string(ComparatorPolicy))
+	assert.Empty(t, policy)
+	assert.True(t, errors.Is(err, errInvalidConflictPolicy))
+	assert.True(t, strings.Contains(err.Error(), "available policies: last-wins, first-wins, fail-on-conflict"))
+}
+
+func createPersisterMock(data map[string]string) types.Persister {
+	persister := mock.NewPersisterMock()
+	for key, val := range data {
+		_ = persister.Put([]byte(key), []byte(val))
+	}
+
+	return persister
+}
+
+func mergeConflictingPersisters(t *testing.T, args ArgsDataMerger) (types.Persister, error) {
+	dest := createPersisterMock(map[string]string{"key1": "dest1", "key2": "dest2"})
+	src1 := createPersisterMock(map[string]string{"key1": "dest1", "key2": "src1", "key3": "src1"})
+	src2 := createPersisterMock(map[string]string{"key2": "src2", "key3": "src2", "key4": "src2"})
+
+	dm, err := NewDataMergerWithArgs(args)
+	assert.Nil(t, err)
+
+	return dest, dm.MergeDBs(dest, src1, src2)
+}
+
+func requireValues(t *testing.T, persister types.Persister, expected map[string]string) {
+	for key, val := range expected {
+		recovered, err := persister.Get([]byte(key))
+		assert.Nil(t, err)
+		assert.Equal(t, val, string(recovered), "key %s", key)
+	}
+}
+
+func TestDataMerger_MergeDBsWithConflicts(t *testing.T) {
+	t.Parallel()
+
+	t.Run("last wins", func(t *testing.T) {
+		t.Parallel()
+
+		dest, err := mergeConflictingPersisters(t, createMockArgsDataMerger(LastWinsPolicy))
+		assert.Nil(t, err)
+		requireValues(t, dest, map[string]string{"key1": "dest1", "key2": "src2", "key3": "src2", "key4": "src2"})
+	})
+	t.Run("first wins", func(t *testing.T) {
+		t.Parallel()
+
+		dest, err := mergeConflictingPersisters(t, createMockArgsDataMerger(FirstWinsPolicy))
+		assert.Nil(t, err)
+		requireValues(t, dest, map[string]string{"key1": "dest1", "key2": "dest2", "key3": "src1", "key4": "src2"})
+	})
+	t.Run("fail on conflict", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := mergeConflictingPersisters(t, createMockArgsDataMerger(FailOnConflictPolicy))
+		assert.True(t, errors.Is(err, errConflictFound))
+		assert.True(t, strings.Contains(err.Error(), "for key "+hex.EncodeToString([]byte("key2"))+", source index 1"))
+	})
+	t.Run("comparator", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsDataMerger(ComparatorPolicy)
+		args.Comparator = func(key []byte, destValue []byte, sourceValue []byte) bool {
+			return string(key) == "key3"
+		}
+		dest, err := mergeConflictingPersisters(t, args)
+		assert.Nil(t, err)
+		requireValues(t, dest, map[string]string{"key1": "dest1", "key2": "dest2", "key3": "src2", "key4": "src2"})
+	})
+	t.Run("should write the report", func(t *testing.T) {
+		t.Parallel()
+
+		reportPath := filepath.Join(t.TempDir(), "report.json")
+		args := createMockArgsDataMerger(LastWinsPolicy)
+		args.ReportPath = reportPath
+		_, err := mergeConflictingPersisters(t, args)
+		assert.Nil(t, err)
+
+		reportBytes, err := ioutil.ReadFile(reportPath)
+		assert.Nil(t, err)
+		report := &conflictReport{}
+		assert.Nil(t, json.Unmarshal(reportBytes, report))
+
+		expectedReport := &conflictReport{
+			Policy:       LastWinsPolicy,
+			NumSources:   3,
+			NumConflicts: 2,
+			Conflicts: []*keyConflict{
+				{
+					Key: hex.EncodeToString([]byte("key2")),
+					Values: []conflictValue{
+						{SourceIndex: destinationSourceIndex, ValueHash: computeValueHash([]byte("dest2"))},
+						{SourceIndex: 1, ValueHash: computeValueHash([]byte("src1"))},
+						{SourceIndex: 2, ValueHash: computeValueHash([]byte("src2"))},
+					},
+					KeptValueHash: computeValueHash([]byte("src2")),
+				},
+				{
+					Key: hex.EncodeToString([]byte("key3")),
+					Values: []conflictValue{
+						{SourceIndex: 1, ValueHash: computeValueHash([]byte("src1"))},
+						{SourceIndex: 2, ValueHash: computeValueHash([]byte("src2"))},
+					},
+					KeptValueHash: computeValueHash([]byte("src2")),
+				},
+			},
+		}
+		assert.Equal(t, expectedReport, report)
+	})
+}
+
+func TestDataMerger_MergeDBsInBatchesShouldKeepTheSourcesOrder(t *testing.T) {
+	t.Parallel()
+
+	numSources := 5
+	numKeys := 23
+	sources := make([]types.Persister, 0, numSources)
+	for i := 0; i < numSources; i++ {
+		data := make(map[string]string)
+		for j := 0; j < numKeys; j++ {
+			data[fmt.Sprintf("key%d", j)] = fmt.Sprintf("val%d-%d", i, j)
+		}
+		data[fmt.Sprintf("source%d", i)] = "val"
+		sources = append(sources, createPersisterMock(data))
+	}
+
+	for _, numParallelReaders := range []int{1, 2, numSources + 1} {
+		args := createMockArgsDataMerger(LastWinsPolicy)
+		args.BatchSize = 4
+		args.NumParallelReaders = numParallelReaders
+		dm, _ := NewDataMergerWithArgs(args)
+		dm.progressLogInterval = 0
+
+		dest := mock.NewPersisterMock()
+		err := dm.MergeDBs(dest, sources...)
+		assert.Nil(t, err)
+
+		for j := 0; j < numKeys; j++ {
+			val, errGet := dest.Get([]byte(fmt.Sprintf("key%d", j)))
+			assert.Nil(t, errGet)
+			assert.Equal(t, fmt.Sprintf("val%d-%d", numSources-1, j), string(val))
+		}
+		for i := 0; i < numSources; i++ {
+			assert.Nil(t, dest.Has([]byte(fmt.Sprintf("source%d", i))))
+		}
+	}
+}
+
+func TestDataMerger_MergeDBsPutErrorShouldStopTheReaders(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("expected error")
+	numRangedKeys := uint32(0)
+	source := &mock.PersisterStub{
+		RangeKeysCalled: func(handler func(key []byte, val []byte) bool) {
+			for i := 0; i < 1000; i++ {
+				atomic.AddUint32(&numRangedKeys, 1)
+				if !handler([]byte(fmt.Sprintf("key%d", i)), []byte("val")) {
+					return
+				}
+			}
+		},
+	}
+	dest := &mock.PersisterStub{
+		PutCalled: func(key, val []byte) error {
+			return expectedErr
+		},
+	}
+
+	dm, _ := NewDataMergerWithArgs(createMockArgsDataMerger(LastWinsPolicy))
+	err := dm.MergeDBs(dest, source, source, source)
+	assert.Equal(t, expectedErr, err)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.Less(t, atomic.LoadUint32(&numRangedKeys), uint32(1000))
+}
+
+func TestDataMerger_MergeDBsShouldReadTheDestinationOnlyIfNeeded(t *testing.T) {
+	t.Parallel()
+
+	source := createPersisterMock(map[string]string{"key1": "val1", "key2": "val2", "key3": "val3"})
+
+	t.Run("last wins without a report should not read the destination", func(t *testing.T) {
+		t.Parallel()
+
+		written := make([]string, 0)
+		dest := &mock.PersisterStub{
+			GetCalled: func(key []byte) ([]byte, error) {
+				assert.Fail(t, "should not have been called")
+				return nil, nil
+			},
+			PutCalled: func(key, val []byte) error {
+				written = append(written, string(key))
+				return nil
+			},
+		}
+
+		dm, _ := NewDataMergerWithArgs(createMockArgsDataMerger(LastWinsPolicy))
+		err := dm.MergeDBs(dest, source)
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{"key1", "key2", "key3"}, written)
+	})
+	t.Run("first wins should write only the new keys", func(t *testing.T) {
+		t.Parallel()
+
+		numGetCalls := 0
+		written := make([]string, 0)
+		dest := &mock.PersisterStub{
+			GetCalled: func(key []byte) ([]byte, error) {
+				numGetCalls++
+				if string(key) == "key2" {
+					return []byte("dest2"), nil
+				}
+				return nil, errors.New("not found")
+			},
+			PutCalled: func(key, val []byte) error {
+				written = append(written, string(key))
+				return nil
+			},
+		}
+
+		dm, _ := NewDataMergerWithArgs(createMockArgsDataMerger(FirstWinsPolicy))
+		err := dm.MergeDBs(dest, source)
+		assert.Nil(t, err)
+		assert.Equal(t, 3, numGetCalls)
+		assert.ElementsMatch(t, []string{"key1", "key3"}, written)
+	})
+}
+
+func TestDataMerger_MergeDBsPutErrorShouldWaitForTheReaders(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("expected error")
+	numActiveReaders := int32(0)
+	createSource := func() types.Persister {
+		return &mock.PersisterStub{
+			RangeKeysCalled: func(handler func(key []byte, val []byte) bool) {
+				atomic.AddInt32(&numActiveReaders, 1)
+				defer func() {
+					time.Sleep(50 * time.Millisecond)
+					atomic.AddInt32(&numActiveReaders, -1)
+				}()
+
+				for i := 0; i < 1000; i++ {
+					if !handler([]byte(fmt.Sprintf("key%d", i)), []byte("val")) {
+						return
+					}
+				}
+			},
+		}
+	}
+	numPuts := int32(0)
+	dest := &mock.PersisterStub{
+		PutCalled: func(key, val []byte) error {
+			if atomic.AddInt32(&numPuts, 1) > 5 {
+				return expectedErr
+			}
+			return nil
+		},
+	}
+
+	dm, _ := NewDataMergerWithArgs(createMockArgsDataMerger(LastWinsPolicy))
+	err := dm.MergeDBs(dest, createSource(), createSource(), createSource())
+	assert.Equal(t, expectedErr, err)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&numActiveReaders))
+}
@@ -5,3 +5,11 @@ import "errors"
 var errNilPersister = errors.New("nil persister")
 var errInvalidNumberOfPersisters = errors.New("invalid number of persisters")
 var errNilComponent = errors.New("nil component")
+var errNoRootHash = errors.New("no root hash provided")
+var errMissingTrieNode = errors.New("missing trie node")
+var errInvalidTrieNode = errors.New("invalid trie node")
+var errInvalidConflictPolicy = errors.New("invalid conflict policy")
+var errNilConflictComparator = errors.New("nil conflict comparator")
+var errConflictFound = errors.New("conflict found")
+var errInvalidValue = errors.New("invalid value")
+var errUnsupportedDBType = errors.New("unsupported DB type")
@@ -12,15 +12,17 @@ const minNumOfPersisters = 2
 
 // ArgsFullDBMerger is the DTO used in the NewFullDBMerger constructor function
 type ArgsFullDBMerger struct {
-	DataMergerInstance  DataMerger
-	PersisterCreator    PersisterCreator
-	OsOperationsHandler OsOperationsHandler
+	DataMergerInstance          DataMerger
+	SourcePersisterCreator      PersisterCreator
+	DestinationPersisterCreator PersisterCreator
+	OsOperationsHandler         OsOperationsHandler
 }
 
 type fullDBMerger struct {
-	dataMergerInstance  DataMerger
-	persisterCreator    PersisterCreator
-	osOperationsHandler OsOperationsHandler
+	dataMergerInstance          DataMerger
+	sourcePersisterCreator      PersisterCreator
+	destinationPersisterCreator PersisterCreator
+	osOperationsHandler         OsOperationsHandler
 }
 
 // NewFullDBMerger creates a new instance of type fullDBMerger
@@ -28,17 +30,21 @@ func NewFullDBMerger(args ArgsFullDBMerger) (*fullDBMerger, error) {
 	if check.IfNil(args.DataMergerInstance) {
 		return nil, fmt.Errorf("%w, DataMergerInstance", errNilComponent)
 	}
-	if check.IfNil(args.PersisterCreator) {
-		return nil, fmt.Errorf("%w, PersisterCreator", errNilComponent)
+	if check.IfNil(args.SourcePersisterCreator) {
+		return nil, fmt.Errorf("%w, SourcePersisterCreator", errNilComponent)
+	}
+	if check.IfNil(args.DestinationPersisterCreator) {
+		return nil, fmt.Errorf("%w, DestinationPersisterCreator", errNilComponent)
 	}
 	if check.IfNil(args.OsOperationsHandler) {
 		return nil, fmt.Errorf("%w, OsOperationsHandler", errNilComponent)
 	}
 
 	return &fullDBMerger{
-		dataMergerInstance:  args.DataMergerInstance,
-		persisterCreator:    args.PersisterCreator,
-		osOperationsHandler: args.OsOperationsHandler,
+		dataMergerInstance:          args.DataMergerInstance,
+		sourcePersisterCreator:      args.SourcePersisterCreator,
+		destinationPersisterCreator: args.DestinationPersisterCreator,
+		osOperationsHandler:         args.OsOperationsHandler,
 	}, nil
 }
 
@@ -58,18 +64,21 @@ func (fdm *fullDBMerger) MergeDBs(destinationPath string, sourcePaths ...string)
 		return nil, err
 	}
 
-	destPersister, err := fdm.persisterCreator.CreatePersister(destinationPath)
+	destPersister, err := fdm.destinationPersisterCreator.CreatePersister(destinationPath)
 	if err != nil {
 		return nil, fmt.Errorf("%w for destination persister", err)
 	}
 
 	sourcePersisters, err := fdm.createSourcePersisters(sourcePaths...)
 	if err != nil {
+		_ = destPersister.Close()
 		return nil, err
 	}
 
 	err = fdm.dataMergerInstance.MergeDBs(destPersister, sourcePersisters...)
 	if err != nil {
+		_ = destPersister.Close()
+		_ = fdm.closeSourcePersisters(sourcePersisters)
 		return nil, err
 	}
 
@@ -84,8 +93,9 @@ func (fdm *fullDBMerger) MergeDBs(destinationPath string, sourcePaths ...string)
 func (fdm *fullDBMerger) createSourcePersisters(sourcePaths ...string) ([]types.Persister, error) {
 	sourcePersisters := make([]types.Persister, 0, len(sourcePaths)-1)
 	for i := 1; i < len(sourcePaths); i++ {
-		srcPersister, errPersister := fdm.persisterCreator.CreatePersister(sourcePaths[i])
+		srcPersister, errPersister := fdm.sourcePersisterCreator.CreatePersister(sourcePaths[i])
 		if errPersister != nil {
+			_ = fdm.closeSourcePersisters(sourcePersisters)
 			return nil, fmt.Errorf("%w for source persister with index %d", errPersister, i)
 		}
 
@@ -3,6 +3,7 @@ package storer
 import (
 	"errors"
 	"strings"
+	"sync"
 	"testing"
 
 	"github.com/multiversx/mx-chain-core-go/core/check"
@@ -13,9 +14,10 @@ import (
 
 func createMockArgsFullDBMerger() ArgsFullDBMerger {
 	return ArgsFullDBMerger{
-		DataMergerInstance:  &mock.DataMergerStub{},
-		PersisterCreator:    &mock.PersisterCreatorStub{},
-		OsOperationsHandler: &mock.OsOperationsHandlerStub{},
+		DataMergerInstance:          &mock.DataMergerStub{},
+		SourcePersisterCreator:      &mock.PersisterCreatorStub{},
+		DestinationPersisterCreator: &mock.PersisterCreatorStub{},
+		OsOperationsHandler:         &mock.OsOperationsHandlerStub{},
 	}
 }
 
@@ -33,16 +35,27 @@ func TestNewFullDBMerger(t *testing.T) {
 		assert.True(t, errors.Is(err, errNilComponent))
 		assert.True(t, strings.Contains(err.Error(), "DataMergerInstance"))
 	})
-	t.Run("nil PersisterCreator", func(t *testing.T) {
+	t.Run("nil SourcePersisterCreator", func(t *testing.T) {
 		t.Parallel()
 
 		args := createMockArgsFullDBMerger()
-		args.PersisterCreator = nil
+		args.SourcePersisterCreator = nil
 		merger, err := NewFullDBMerger(args)
 
 		assert.True(t, check.IfNil(merger))
 		assert.True(t, errors.Is(err, errNilComponent))
-		assert.True(t, strings.Contains(err.Error(), "PersisterCreator"))
+		assert.True(t, strings.Contains(err.Error(), "SourcePersisterCreator"))
+	})
+	t.Run("nil DestinationPersisterCreator", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsFullDBMerger()
+		args.DestinationPersisterCreator = nil
+		merger, err := NewFullDBMerger(args)
+
+		assert.True(t, check.IfNil(merger))
+		assert.True(t, errors.Is(err, errNilComponent))
+		assert.True(t, strings.Contains(err.Error(), "DestinationPersisterCreator"))
 	})
 	t.Run("nil OsOperationsHandler", func(t *testing.T) {
 		t.Parallel()
@@ -122,12 +135,13 @@ func TestDataMerger_MergeDBs(t *testing.T) {
 
 		expectedErr := errors.New("expected error")
 		args := createMockArgsFullDBMerger()
-		args.PersisterCreator = &mock.PersisterCreatorStub{
+		args.DestinationPersisterCreator = &mock.PersisterCreatorStub{
+			CreatePersisterCalled: func(path string) (types.Persister, error) {
+				return nil, expectedErr
+			},
+		}
+		args.SourcePersisterCreator = &mock.PersisterCreatorStub{
 			CreatePersisterCalled: func(path string) (types.Persister, error) {
-				if path == "dest" {
-					return nil, expectedErr
-				}
-
 				return mock.NewPersisterMock(), nil
 			},
 		}
@@ -143,12 +157,13 @@ func TestDataMerger_MergeDBs(t *testing.T) {
 
 		expectedErr := errors.New("expected error")
 		args := createMockArgsFullDBMerger()
-		args.PersisterCreator = &mock.PersisterCreatorStub{
+		args.SourcePersisterCreator = &mock.PersisterCreatorStub{
+			CreatePersisterCalled: func(path string) (types.Persister, error) {
+				return nil, expectedErr
+			},
+		}
+		args.DestinationPersisterCreator = &mock.PersisterCreatorStub{
 			CreatePersisterCalled: func(path string) (types.Persister, error) {
-				if strings.Contains(path, "src") {
-					return nil, expectedErr
-				}
-
 				return mock.NewPersisterMock(), nil
 			},
 		}
@@ -164,11 +179,12 @@ func TestDataMerger_MergeDBs(t *testing.T) {
 
 		expectedErr := errors.New("expected error")
 		args := createMockArgsFullDBMerger()
-		args.PersisterCreator = &mock.PersisterCreatorStub{
+		args.SourcePersisterCreator = &mock.PersisterCreatorStub{
 			CreatePersisterCalled: func(path string) (types.Persister, error) {
 				return mock.NewPersisterMock(), nil
 			},
 		}
+		args.DestinationPersisterCreator = args.SourcePersisterCreator
 		args.DataMergerInstance = &mock.DataMergerStub{
 			MergeDBsCalled: func(dest types.Persister, sources ...types.Persister) error {
 				return expectedErr
@@ -180,6 +196,38 @@ func TestDataMerger_MergeDBs(t *testing.T) {
 		assert.True(t, check.IfNil(destPersister))
 		assert.True(t, errors.Is(err, expectedErr))
 	})
+	t.Run("data merge errors should close the persisters", func(t *testing.T) {
+		t.Parallel()
+
+		expectedErr := errors.New("expected error")
+		closedPaths := make([]string, 0)
+		mut := sync.Mutex{}
+		persisterCreator := &mock.PersisterCreatorStub{
+			CreatePersisterCalled: func(path string) (types.Persister, error) {
+				return &mock.PersisterStub{
+					CloseCalled: func() error {
+						mut.Lock()
+						closedPaths = append(closedPaths, path)
+						mut.Unlock()
+						return nil
+					},
+				}, nil
+			},
+		}
+		args := createMockArgsFullDBMerger()
+		args.SourcePersisterCreator = persisterCreator
+		args.DestinationPersisterCreator = persisterCreator
+		args.DataMergerInstance = &mock.DataMergerStub{
+			MergeDBsCalled: func(dest types.Persister, sources ...types.Persister) error {
+				return expectedErr
+			},
+		}
+		merger, _ := NewFullDBMerger(args)
+
+		_, err := merger.MergeDBs("dest", "src1", "src2", "src3")
+		assert.True(t, errors.Is(err, expectedErr))
+		assert.Equal(t, []string{"dest", "src2", "src3"}, closedPaths)
+	})
 	t.Run("should work", func(t *testing.T) {
 		t.Parallel()
 
@@ -195,7 +243,15 @@ func TestDataMerger_MergeDBs(t *testing.T) {
 				return nil
 			},
 		}
-		args.PersisterCreator = &mock.PersisterCreatorStub{
+		args.DestinationPersisterCreator = &mock.PersisterCreatorStub{
+			CreatePersisterCalled: func(path string) (types.Persister, error) {
+				assert.Equal(t, "dest", path)
+				numPersistersCreated++
+
+				return mock.NewPersisterMock(), nil
+			},
+		}
+		args.SourcePersisterCreator = &mock.PersisterCreatorStub{
 			CreatePersisterCalled: func(path string) (types.Persister, error) {
 				numPersistersCreated++
 				persisterMock := mock.NewPersisterMock()
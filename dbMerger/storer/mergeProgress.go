@@ -0,0 +1,57 @@
+package storer
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/core"
+)
+
+// mergeProgress counts the written key-value pairs and logs the throughput at most once every log interval
+type mergeProgress struct {
+	logInterval time.Duration
+	startTime   time.Time
+	lastLogTime time.Time
+	numKeys     uint64
+	numBytes    uint64
+}
+
+func newMergeProgress(logInterval time.Duration) *mergeProgress {
+	now := time.Now()
+
+	return &mergeProgress{
+		logInterval: logInterval,
+		startTime:   now,
+		lastLogTime: now,
+	}
+}
+
+func (mp *mergeProgress) addBatch(batch []keyValue, sourceIndex int) {
+	for _, kv := range batch {
+		mp.numBytes += uint64(len(kv.key) + len(kv.value))
+	}
+	mp.numKeys += uint64(len(batch))
+
+	if time.Since(mp.lastLogTime) < mp.logInterval {
+		return
+	}
+
+	mp.lastLogTime = time.Now()
+	log.Info("merging data", append([]interface{}{"source index", sourceIndex}, mp.logArgs()...)...)
+}
+
+func (mp *mergeProgress) logArgs() []interface{} {
+	elapsed := time.Since(mp.startTime)
+	seconds := elapsed.Seconds()
+	if seconds <= 0 {
+		seconds = 1
+	}
+
+	return []interface{}{
+		"num keys", mp.numKeys,
+		"size", core.ConvertBytes(mp.numBytes),
+		"keys/s", fmt.Sprintf("%.0f", float64(mp.numKeys)/seconds),
+		"bytes/s", core.ConvertBytes(uint64(float64(mp.numBytes) / seconds)),
+		"elapsed", elapsed.Truncate(time.Millisecond),
+	}
+}
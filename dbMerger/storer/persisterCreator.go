@@ -1,27 +1,98 @@
 package storer
 
 import (
+	"fmt"
+
 	"github.com/multiversx/mx-chain-storage-go/leveldb"
+	"github.com/multiversx/mx-chain-storage-go/storageUnit"
 	"github.com/multiversx/mx-chain-storage-go/types"
-)
-
-const (
-	batchDelaySeconds = 2
-	maxBatchSize      = 10000
-	maxOpenFiles      = 10
+	"github.com/multiversx/mx-chain-tools-go/dbmerger/config"
 )
 
 type persisterCreator struct {
+	config config.PersisterConfig
 }
 
-// NewPersisterCreator will create a new persister creator instance
+// NewPersisterCreator will create a new persister creator instance, using the default level DB options
 func NewPersisterCreator() *persisterCreator {
-	return &persisterCreator{}
+	return &persisterCreator{
+		config: config.DefaultPersisterConfig(),
+	}
+}
+
+// NewPersisterCreatorWithConfig will create a new persister creator instance, using the provided DB and cache options
+func NewPersisterCreatorWithConfig(cfg config.PersisterConfig) (*persisterCreator, error) {
+	switch cfg.DB.Type {
+	case storageUnit.LvlDB, storageUnit.LvlDBSerial:
+	default:
+		return nil, fmt.Errorf("%w %s", errUnsupportedDBType, cfg.DB.Type)
+	}
+	if cfg.DB.BatchDelaySeconds < 1 {
+		return nil, fmt.Errorf("%w, BatchDelaySeconds %d", errInvalidValue, cfg.DB.BatchDelaySeconds)
+	}
+	if cfg.DB.MaxBatchSize < 1 {
+		return nil, fmt.Errorf("%w, MaxBatchSize %d", errInvalidValue, cfg.DB.MaxBatchSize)
+	}
+	if cfg.DB.MaxOpenFiles < 1 {
+		return nil, fmt.Errorf("%w, MaxOpenFiles %d", errInvalidValue, cfg.DB.MaxOpenFiles)
+	}
+
+	return &persisterCreator{
+		config: cfg,
+	}, nil
+}
+
+// CreatePersisterCreators will create the source and the destination persister creators, using the options from the
+// provided TOML file or the default options if the path is empty
+func CreatePersisterCreators(configFilePath string) (PersisterCreator, PersisterCreator, error) {
+	cfg := config.DefaultConfig()
+	if len(configFilePath) > 0 {
+		var err error
+		cfg, err = config.LoadConfig(configFilePath)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w while loading the config file %s", err, configFilePath)
+		}
+	}
+
+	sourcePersisterCreator, err := NewPersisterCreatorWithConfig(cfg.Source)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w for the source persisters", err)
+	}
+	destinationPersisterCreator, err := NewPersisterCreatorWithConfig(cfg.Destination)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w for the destination persister", err)
+	}
+
+	return sourcePersisterCreator, destinationPersisterCreator, nil
 }
 
 // CreatePersister will try to create a new persister instance provided the directory path
 func (creator *persisterCreator) CreatePersister(path string) (types.Persister, error) {
-	return leveldb.NewDB(path, batchDelaySeconds, maxBatchSize, maxOpenFiles)
+	persister, err := creator.createDB(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(creator.config.Cache.Type) == 0 {
+		return persister, nil
+	}
+
+	cacher, err := storageUnit.NewCache(creator.config.Cache)
+	if err != nil {
+		_ = persister.Close()
+		return nil, fmt.Errorf("%w while creating the cache for path %s", err, path)
+	}
+
+	return newCachedPersister(persister, cacher), nil
+}
+
+func (creator *persisterCreator) createDB(path string) (types.Persister, error) {
+	dbConfig := creator.config.DB
+	switch dbConfig.Type {
+	case storageUnit.LvlDBSerial:
+		return leveldb.NewSerialDB(path, dbConfig.BatchDelaySeconds, dbConfig.MaxBatchSize, dbConfig.MaxOpenFiles)
+	default:
+		return leveldb.NewDB(path, dbConfig.BatchDelaySeconds, dbConfig.MaxBatchSize, dbConfig.MaxOpenFiles)
+	}
 }
 
 // IsInterfaceNil returns true if there is no value under the interface
@@ -1,10 +1,17 @@
 package storer
 
 import (
+	"errors"
 	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/multiversx/mx-chain-core-go/core/check"
+	"github.com/multiversx/mx-chain-storage-go/storageUnit"
+	"github.com/multiversx/mx-chain-tools-go/dbmerger/config"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -27,3 +34,177 @@ func TestPersisterCreator_CreatePersister(t *testing.T) {
 
 	_ = persister.Destroy()
 }
+
+func TestNewPersisterCreatorWithConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unsupported DB type should error", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.DefaultPersisterConfig()
+		cfg.DB.Type = storageUnit.MemoryDB
+		creator, err := NewPersisterCreatorWithConfig(cfg)
+		assert.True(t, check.IfNil(creator))
+		assert.True(t, errors.Is(err, errUnsupportedDBType))
+	})
+	t.Run("invalid BatchDelaySeconds should error", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.DefaultPersisterConfig()
+		cfg.DB.BatchDelaySeconds = 0
+		creator, err := NewPersisterCreatorWithConfig(cfg)
+		assert.True(t, check.IfNil(creator))
+		assert.True(t, errors.Is(err, errInvalidValue))
+		assert.True(t, strings.Contains(err.Error(), "BatchDelaySeconds"))
+	})
+	t.Run("invalid MaxBatchSize should error", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.DefaultPersisterConfig()
+		cfg.DB.MaxBatchSize = 0
+		creator, err := NewPersisterCreatorWithConfig(cfg)
+		assert.True(t, check.IfNil(creator))
+		assert.True(t, errors.Is(err, errInvalidValue))
+		assert.True(t, strings.Contains(err.Error(), "MaxBatchSize"))
+	})
+	t.Run("invalid MaxOpenFiles should error", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.DefaultPersisterConfig()
+		cfg.DB.MaxOpenFiles = 0
+		creator, err := NewPersisterCreatorWithConfig(cfg)
+		assert.True(t, check.IfNil(creator))
+		assert.True(t, errors.Is(err, errInvalidValue))
+		assert.True(t, strings.Contains(err.Error(), "MaxOpenFiles"))
+	})
+	t.Run("should work", func(t *testing.T) {
+		t.Parallel()
+
+		creator, err := NewPersisterCreatorWithConfig(config.DefaultPersisterConfig())
+		assert.False(t, check.IfNil(creator))
+		assert.Nil(t, err)
+	})
+}
+
+func TestPersisterCreator_CreatePersisterWithConfig(t *testing.T) {
+	t.Parallel()
+
+	t.Run("serial level DB", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.DefaultPersisterConfig()
+		cfg.DB.Type = storageUnit.LvlDBSerial
+		creator, _ := NewPersisterCreatorWithConfig(cfg)
+
+		persister, err := creator.CreatePersister(t.TempDir())
+		assert.Nil(t, err)
+		assert.Equal(t, "*leveldb.SerialDB", fmt.Sprintf("%T", persister))
+		_ = persister.Close()
+	})
+	t.Run("invalid cache config should error", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.DefaultPersisterConfig()
+		cfg.Cache.Type = "invalid"
+		creator, _ := NewPersisterCreatorWithConfig(cfg)
+
+		persister, err := creator.CreatePersister(t.TempDir())
+		assert.True(t, check.IfNil(persister))
+		assert.True(t, strings.Contains(err.Error(), "while creating the cache for path"))
+	})
+	t.Run("level DB with cache", func(t *testing.T) {
+		t.Parallel()
+
+		cfg := config.DefaultPersisterConfig()
+		cfg.Cache = storageUnit.CacheConfig{
+			Type:     storageUnit.LRUCache,
+			Capacity: 10,
+		}
+		creator, _ := NewPersisterCreatorWithConfig(cfg)
+
+		dbPath := t.TempDir()
+		persister, err := creator.CreatePersister(dbPath)
+		assert.Nil(t, err)
+		assert.Equal(t, "*storer.cachedPersister", fmt.Sprintf("%T", persister))
+
+		assert.Nil(t, persister.Put([]byte("key"), []byte("value")))
+		assert.Nil(t, persister.Close())
+
+		persister, err = creator.CreatePersister(dbPath)
+		assert.Nil(t, err)
+		val, err := persister.Get([]byte("key"))
+		assert.Nil(t, err)
+		assert.Equal(t, []byte("value"), val)
+
+		assert.Nil(t, persister.Remove([]byte("key")))
+		assert.NotNil(t, persister.Has([]byte("key")))
+		_ = persister.Close()
+	})
+}
+
+func TestCreatePersisterCreators(t *testing.T) {
+	t.Parallel()
+
+	t.Run("missing config file should error", func(t *testing.T) {
+		t.Parallel()
+
+		sourceCreator, destinationCreator, err := CreatePersisterCreators(filepath.Join(t.TempDir(), "missing.toml"))
+		assert.True(t, check.IfNil(sourceCreator))
+		assert.True(t, check.IfNil(destinationCreator))
+		assert.True(t, strings.Contains(err.Error(), "while loading the config file"))
+	})
+	t.Run("invalid destination config should error", func(t *testing.T) {
+		t.Parallel()
+
+		configFile := filepath.Join(t.TempDir(), "config.toml")
+		assert.Nil(t, ioutil.WriteFile(configFile, []byte("[Destination]\n    [Destination.DB]\n        MaxOpenFiles = -1\n"), 0644))
+
+		_, _, err := CreatePersisterCreators(configFile)
+		assert.True(t, errors.Is(err, errInvalidValue))
+		assert.True(t, strings.Contains(err.Error(), "for the destination persister"))
+	})
+	t.Run("should apply the configured DB options", func(t *testing.T) {
+		t.Parallel()
+
+		configFile := filepath.Join(t.TempDir(), "config.toml")
+		configContent := `
+[Destination]
+    [Destination.DB]
+        BatchDelaySeconds = 7
+        MaxBatchSize = 3
+`
+		assert.Nil(t, ioutil.WriteFile(configFile, []byte(configContent), 0644))
+
+		sourceCreator, destinationCreator, err := CreatePersisterCreators(configFile)
+		assert.Nil(t, err)
+
+		source, err := sourceCreator.CreatePersister(t.TempDir())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(config.DefaultPersisterConfig().DB.MaxBatchSize), getIntField(source, "maxBatchSize"))
+		_ = source.Close()
+
+		destination, err := destinationCreator.CreatePersister(t.TempDir())
+		assert.Nil(t, err)
+		assert.Equal(t, "*leveldb.DB", fmt.Sprintf("%T", destination))
+		assert.Equal(t, int64(3), getIntField(destination, "maxBatchSize"))
+		assert.Equal(t, int64(7), getIntField(destination, "batchDelaySeconds"))
+		_ = destination.Close()
+	})
+	t.Run("empty path should use the defaults", func(t *testing.T) {
+		t.Parallel()
+
+		sourceCreator, destinationCreator, err := CreatePersisterCreators("")
+		assert.Nil(t, err)
+
+		destination, err := destinationCreator.CreatePersister(t.TempDir())
+		assert.Nil(t, err)
+		assert.Equal(t, int64(config.DefaultPersisterConfig().DB.MaxBatchSize), getIntField(destination, "maxBatchSize"))
+		_ = destination.Close()
+		assert.False(t, check.IfNil(sourceCreator))
+	})
+}
+
+// getIntField reads an unexported int field of the persister, the level DB not exposing its options
+func getIntField(persister interface{}, fieldName string) int64 {
+	return reflect.ValueOf(persister).Elem().FieldByName(fieldName).Int()
+}
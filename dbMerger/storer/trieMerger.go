@@ -0,0 +1,241 @@
+package storer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	"github.com/multiversx/mx-chain-core-go/hashing"
+	"github.com/multiversx/mx-chain-core-go/marshal"
+	"github.com/multiversx/mx-chain-go/state"
+	"github.com/multiversx/mx-chain-go/storage"
+	"github.com/multiversx/mx-chain-storage-go/types"
+)
+
+const (
+	minNumOfTrieSources = 1
+	emptyTrieHashLength = 32
+	logProgressNumNodes = 100000
+)
+
+// ArgsTrieMerger is the DTO used in the NewTrieMerger constructor function
+type ArgsTrieMerger struct {
+	SourcePersisterCreator      PersisterCreator
+	DestinationPersisterCreator PersisterCreator
+	OsOperationsHandler         OsOperationsHandler
+	Marshaller                  marshal.Marshalizer
+	Hasher                      hashing.Hasher
+	// WithDataTries should be set for an accounts trie, case when the data trie of every account is copied as well
+	WithDataTries bool
+}
+
+type trieMerger struct {
+	sourcePersisterCreator      PersisterCreator
+	destinationPersisterCreator PersisterCreator
+	osOperationsHandler         OsOperationsHandler
+	marshaller                  marshal.Marshalizer
+	hasher                      hashing.Hasher
+	withDataTries               bool
+}
+
+// trieWalkItem is a node that has to be copied. The leaves of the main trie hold accounts, which can have data tries
+type trieWalkItem struct {
+	hash       []byte
+	isMainTrie bool
+}
+
+// trieMergeStats holds the number of nodes, leaves and data tries copied by a merge
+type trieMergeStats struct {
+	numNodes     int
+	numLeaves    int
+	numDataTries int
+}
+
+// NewTrieMerger creates a new instance of type trieMerger
+func NewTrieMerger(args ArgsTrieMerger) (*trieMerger, error) {
+	if check.IfNil(args.SourcePersisterCreator) {
+		return nil, fmt.Errorf("%w, SourcePersisterCreator", errNilComponent)
+	}
+	if check.IfNil(args.DestinationPersisterCreator) {
+		return nil, fmt.Errorf("%w, DestinationPersisterCreator", errNilComponent)
+	}
+	if check.IfNil(args.OsOperationsHandler) {
+		return nil, fmt.Errorf("%w, OsOperationsHandler", errNilComponent)
+	}
+	if check.IfNil(args.Marshaller) {
+		return nil, fmt.Errorf("%w, Marshaller", errNilComponent)
+	}
+	if check.IfNil(args.Hasher) {
+		return nil, fmt.Errorf("%w, Hasher", errNilComponent)
+	}
+
+	return &trieMerger{
+		sourcePersisterCreator:      args.SourcePersisterCreator,
+		destinationPersisterCreator: args.DestinationPersisterCreator,
+		osOperationsHandler:         args.OsOperationsHandler,
+		marshaller:                  args.Marshaller,
+		hasher:                      args.Hasher,
+		withDataTries:               args.WithDataTries,
+	}, nil
+}
+
+// MergeTries will copy in a new persister only the trie nodes reachable from the provided root hashes, looking for
+// every node in the source persisters, in order. The stale nodes from the sources are not copied, so the destination
+// holds a compact state. An error is returned if a reachable node is missing from all the sources
+func (tm *trieMerger) MergeTries(destinationPath string, rootHashes [][]byte, sourcePaths ...string) (storage.Persister, error) {
+	if len(sourcePaths) < minNumOfTrieSources {
+		return nil, fmt.Errorf("%w, provided %d, minimum %d", errInvalidNumberOfPersisters, len(sourcePaths), minNumOfTrieSources)
+	}
+	if len(rootHashes) == 0 {
+		return nil, errNoRootHash
+	}
+
+	err := tm.osOperationsHandler.CheckIfDirectoryIsEmpty(destinationPath)
+	if err != nil {
+		return nil, err
+	}
+
+	sourcePersisters := make([]types.Persister, 0, len(sourcePaths))
+	defer func() {
+		closeErr := closePersisters(sourcePersisters)
+		if closeErr != nil {
+			log.Warn("cannot close the source persisters", "error", closeErr)
+		}
+	}()
+	for idx, sourcePath := range sourcePaths {
+		sourcePersister, errPersister := tm.sourcePersisterCreator.CreatePersister(sourcePath)
+		if errPersister != nil {
+			return nil, fmt.Errorf("%w for source persister with index %d", errPersister, idx)
+		}
+
+		sourcePersisters = append(sourcePersisters, sourcePersister)
+	}
+
+	destPersister, err := tm.destinationPersisterCreator.CreatePersister(destinationPath)
+	if err != nil {
+		return nil, fmt.Errorf("%w for destination persister", err)
+	}
+
+	stats, err := tm.copyReachableNodes(destPersister, sourcePersisters, rootHashes)
+	if err != nil {
+		_ = destPersister.Close()
+		return nil, err
+	}
+
+	log.Info("finished copying the trie nodes", "num root hashes", len(rootHashes), "num nodes", stats.numNodes,
+		"num leaves", stats.numLeaves, "num data tries", stats.numDataTries)
+
+	return destPersister, nil
+}
+
+// copyReachableNodes walks the tries from the provided roots, depth first, and copies every node once
+func (tm *trieMerger) copyReachableNodes(dest types.Persister, sources []types.Persister, rootHashes [][]byte) (*trieMergeStats, error) {
+	stats := &trieMergeStats{}
+	visited := make(map[string]struct{})
+	toVisit := make([]trieWalkItem, 0, len(rootHashes))
+	for _, rootHash := range rootHashes {
+		toVisit = append(toVisit, trieWalkItem{hash: rootHash, isMainTrie: true})
+	}
+
+	for len(toVisit) > 0 {
+		item := toVisit[len(toVisit)-1]
+		toVisit = toVisit[:len(toVisit)-1]
+
+		_, alreadyCopied := visited[string(item.hash)]
+		if alreadyCopied || isEmptyTrieHash(item.hash) {
+			continue
+		}
+		visited[string(item.hash)] = struct{}{}
+
+		encodedNode, err := tm.getNode(sources, item.hash)
+		if err != nil {
+			return nil, err
+		}
+		node, err := decodeTrieNode(tm.marshaller, encodedNode)
+		if err != nil {
+			return nil, fmt.Errorf("%w for node %s", err, hex.EncodeToString(item.hash))
+		}
+
+		err = dest.Put(item.hash, encodedNode)
+		if err != nil {
+			return nil, err
+		}
+		stats.numNodes++
+		if stats.numNodes%logProgressNumNodes == 0 {
+			log.Info("copying trie nodes", "num nodes", stats.numNodes, "num data tries", stats.numDataTries)
+		}
+
+		for _, childHash := range node.childrenHashes {
+			toVisit = append(toVisit, trieWalkItem{hash: childHash, isMainTrie: item.isMainTrie})
+		}
+		if !node.isLeaf {
+			continue
+		}
+
+		stats.numLeaves++
+		dataTrieRootHash := tm.getDataTrieRootHash(node.value, item.isMainTrie)
+		if len(dataTrieRootHash) > 0 {
+			stats.numDataTries++
+			toVisit = append(toVisit, trieWalkItem{hash: dataTrieRootHash, isMainTrie: false})
+		}
+	}
+
+	return stats, nil
+}
+
+// getNode returns the first node found in the sources for the provided hash. A node whose hash does not match is
+// considered corrupted and it is looked for in the next sources
+func (tm *trieMerger) getNode(sources []types.Persister, hash []byte) ([]byte, error) {
+	for _, source := range sources {
+		encodedNode, err := source.Get(hash)
+		if err != nil || len(encodedNode) == 0 {
+			continue
+		}
+		if !bytes.Equal(tm.hasher.Compute(string(encodedNode)), hash) {
+			log.Warn("trie node with a wrong hash, skipped", "hash", hex.EncodeToString(hash))
+			continue
+		}
+
+		return encodedNode, nil
+	}
+
+	return nil, fmt.Errorf("%w, hash %s", errMissingTrieNode, hex.EncodeToString(hash))
+}
+
+// getDataTrieRootHash returns the root hash of the data trie of the account held in a leaf of the main trie. The
+// leaves that are not accounts, like the code entries, do not have a data trie
+func (tm *trieMerger) getDataTrieRootHash(leafValue []byte, isMainTrie bool) []byte {
+	if !tm.withDataTries || !isMainTrie {
+		return nil
+	}
+
+	account := &state.UserAccountData{}
+	err := tm.marshaller.Unmarshal(account, leafValue)
+	if err != nil || len(account.RootHash) != tm.hasher.Size() {
+		return nil
+	}
+
+	return account.RootHash
+}
+
+func isEmptyTrieHash(hash []byte) bool {
+	return len(hash) == 0 || bytes.Equal(hash, make([]byte, emptyTrieHashLength))
+}
+
+func closePersisters(persisters []types.Persister) error {
+	var lastErrFound error
+	for _, persister := range persisters {
+		err := persister.Close()
+		if err != nil {
+			lastErrFound = err
+		}
+	}
+
+	return lastErrFound
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (tm *trieMerger) IsInterfaceNil() bool {
+	return tm == nil
+}
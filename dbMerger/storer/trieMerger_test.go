@@ -0,0 +1,316 @@
+package storer
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	"github.com/multiversx/mx-chain-core-go/hashing/blake2b"
+	"github.com/multiversx/mx-chain-core-go/marshal"
+	"github.com/multiversx/mx-chain-go/state"
+	"github.com/multiversx/mx-chain-go/trie"
+	"github.com/multiversx/mx-chain-storage-go/types"
+	"github.com/multiversx/mx-chain-tools-go/dbmerger/mock"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	testMarshaller = &marshal.GogoProtoMarshalizer{}
+	testHasher     = blake2b.NewBlake2b()
+)
+
+func createMockArgsTrieMerger() ArgsTrieMerger {
+	return ArgsTrieMerger{
+		SourcePersisterCreator:      &mock.PersisterCreatorStub{},
+		DestinationPersisterCreator: &mock.PersisterCreatorStub{},
+		OsOperationsHandler:         &mock.OsOperationsHandlerStub{},
+		Marshaller:                  testMarshaller,
+		Hasher:                      testHasher,
+		WithDataTries:               true,
+	}
+}
+
+// putTrieNode saves the node as the trie package does and returns its hash
+func putTrieNode(tb testing.TB, persister types.Persister, node interface{}, nodeType byte) []byte {
+	marshalledNode, err := testMarshaller.Marshal(node)
+	assert.Nil(tb, err)
+
+	encodedNode := append(marshalledNode, nodeType)
+	hash := testHasher.Compute(string(encodedNode))
+	assert.Nil(tb, persister.Put(hash, encodedNode))
+
+	return hash
+}
+
+func putLeafNode(tb testing.TB, persister types.Persister, key string, value []byte) []byte {
+	return putTrieNode(tb, persister, &trie.CollapsedLn{Key: []byte(key), Value: value}, leafNodeType)
+}
+
+func putBranchNode(tb testing.TB, persister types.Persister, childrenHashes ...[]byte) []byte {
+	encodedChildren := make([][]byte, 17)
+	copy(encodedChildren, childrenHashes)
+
+	return putTrieNode(tb, persister, &trie.CollapsedBn{EncodedChildren: encodedChildren}, branchNodeType)
+}
+
+func marshalAccount(tb testing.TB, rootHash []byte) []byte {
+	account := &state.UserAccountData{Address: []byte("address"), RootHash: rootHash}
+	accountBytes, err := testMarshaller.Marshal(account)
+	assert.Nil(tb, err)
+
+	return accountBytes
+}
+
+func createTrieMergerWithPersisters(tb testing.TB, dest types.Persister, sources ...types.Persister) *trieMerger {
+	persisters := map[string]types.Persister{"dest": dest}
+	for idx, source := range sources {
+		persisters[string(rune('a'+idx))] = source
+	}
+
+	args := createMockArgsTrieMerger()
+	args.SourcePersisterCreator = &mock.PersisterCreatorStub{
+		CreatePersisterCalled: func(path string) (types.Persister, error) {
+			return persisters[path], nil
+		},
+	}
+	args.DestinationPersisterCreator = args.SourcePersisterCreator
+	merger, err := NewTrieMerger(args)
+	assert.Nil(tb, err)
+
+	return merger
+}
+
+func TestNewTrieMerger(t *testing.T) {
+	t.Parallel()
+
+	t.Run("nil SourcePersisterCreator", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsTrieMerger()
+		args.SourcePersisterCreator = nil
+		merger, err := NewTrieMerger(args)
+
+		assert.True(t, check.IfNil(merger))
+		assert.True(t, errors.Is(err, errNilComponent))
+		assert.True(t, strings.Contains(err.Error(), "SourcePersisterCreator"))
+	})
+	t.Run("nil DestinationPersisterCreator", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsTrieMerger()
+		args.DestinationPersisterCreator = nil
+		merger, err := NewTrieMerger(args)
+
+		assert.True(t, check.IfNil(merger))
+		assert.True(t, errors.Is(err, errNilComponent))
+		assert.True(t, strings.Contains(err.Error(), "DestinationPersisterCreator"))
+	})
+	t.Run("nil OsOperationsHandler", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsTrieMerger()
+		args.OsOperationsHandler = nil
+		merger, err := NewTrieMerger(args)
+
+		assert.True(t, check.IfNil(merger))
+		assert.True(t, errors.Is(err, errNilComponent))
+		assert.True(t, strings.Contains(err.Error(), "OsOperationsHandler"))
+	})
+	t.Run("nil Marshaller", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsTrieMerger()
+		args.Marshaller = nil
+		merger, err := NewTrieMerger(args)
+
+		assert.True(t, check.IfNil(merger))
+		assert.True(t, errors.Is(err, errNilComponent))
+		assert.True(t, strings.Contains(err.Error(), "Marshaller"))
+	})
+	t.Run("nil Hasher", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsTrieMerger()
+		args.Hasher = nil
+		merger, err := NewTrieMerger(args)
+
+		assert.True(t, check.IfNil(merger))
+		assert.True(t, errors.Is(err, errNilComponent))
+		assert.True(t, strings.Contains(err.Error(), "Hasher"))
+	})
+	t.Run("should work", func(t *testing.T) {
+		t.Parallel()
+
+		args := createMockArgsTrieMerger()
+		merger, err := NewTrieMerger(args)
+
+		assert.False(t, check.IfNil(merger))
+		assert.Nil(t, err)
+	})
+}
+
+func TestTrieMerger_MergeTries(t *testing.T) {
+	t.Parallel()
+
+	t.Run("invalid number of source paths", func(t *testing.T) {
+		t.Parallel()
+
+		merger, _ := NewTrieMerger(createMockArgsTrieMerger())
+
+		destPersister, err := merger.MergeTries("dest", [][]byte{[]byte("root")})
+		assert.True(t, check.IfNil(destPersister))
+		assert.True(t, errors.Is(err, errInvalidNumberOfPersisters))
+	})
+	t.Run("no root hash", func(t *testing.T) {
+		t.Parallel()
+
+		merger, _ := NewTrieMerger(createMockArgsTrieMerger())
+
+		destPersister, err := merger.MergeTries("dest", nil, "a")
+		assert.True(t, check.IfNil(destPersister))
+		assert.Equal(t, errNoRootHash, err)
+	})
+	t.Run("destination directory is not empty", func(t *testing.T) {
+		t.Parallel()
+
+		expectedErr := errors.New("expected error")
+		args := createMockArgsTrieMerger()
+		args.OsOperationsHandler = &mock.OsOperationsHandlerStub{
+			CheckIfDirectoryIsEmptyCalled: func(directory string) error {
+				return expectedErr
+			},
+		}
+		merger, _ := NewTrieMerger(args)
+
+		destPersister, err := merger.MergeTries("dest", [][]byte{[]byte("root")}, "a")
+		assert.True(t, check.IfNil(destPersister))
+		assert.Equal(t, expectedErr, err)
+	})
+	t.Run("should copy only the reachable nodes and the data tries", func(t *testing.T) {
+		t.Parallel()
+
+		source := mock.NewPersisterMock()
+		dataLeaf := putLeafNode(t, source, "data key", []byte("data value"))
+		dataRoot := putBranchNode(t, source, dataLeaf)
+		accountLeaf := putLeafNode(t, source, "account", marshalAccount(t, dataRoot))
+		codeLeaf := putLeafNode(t, source, "code", []byte("code"))
+		rootHash := putBranchNode(t, source, accountLeaf, codeLeaf)
+		staleRootHash := putBranchNode(t, source, codeLeaf)
+		staleLeaf := putLeafNode(t, source, "stale", []byte("stale value"))
+
+		dest := mock.NewPersisterMock()
+		merger := createTrieMergerWithPersisters(t, dest, source)
+		destPersister, err := merger.MergeTries("dest", [][]byte{rootHash}, "a")
+		assert.Nil(t, err)
+		assert.Equal(t, dest, destPersister)
+
+		for _, hash := range [][]byte{rootHash, accountLeaf, codeLeaf, dataRoot, dataLeaf} {
+			assert.Nil(t, dest.Has(hash))
+		}
+		for _, hash := range [][]byte{staleRootHash, staleLeaf} {
+			assert.NotNil(t, dest.Has(hash))
+		}
+	})
+	t.Run("should not copy the data tries if not required", func(t *testing.T) {
+		t.Parallel()
+
+		source := mock.NewPersisterMock()
+		dataLeaf := putLeafNode(t, source, "data key", []byte("data value"))
+		accountLeaf := putLeafNode(t, source, "account", marshalAccount(t, dataLeaf))
+		rootHash := putBranchNode(t, source, accountLeaf)
+
+		dest := mock.NewPersisterMock()
+		merger := createTrieMergerWithPersisters(t, dest, source)
+		merger.withDataTries = false
+		_, err := merger.MergeTries("dest", [][]byte{rootHash}, "a")
+		assert.Nil(t, err)
+
+		assert.Nil(t, dest.Has(accountLeaf))
+		assert.NotNil(t, dest.Has(dataLeaf))
+	})
+	t.Run("should look for the nodes in all the sources", func(t *testing.T) {
+		t.Parallel()
+
+		source1 := mock.NewPersisterMock()
+		source2 := mock.NewPersisterMock()
+		leaf1 := putLeafNode(t, source1, "key1", []byte("value1"))
+		leaf2 := putLeafNode(t, source2, "key2", []byte("value2"))
+		rootHash := putBranchNode(t, source2, leaf1, leaf2)
+		// a corrupted node in the first source is skipped
+		_ = source1.Put(rootHash, []byte("corrupted"))
+
+		dest := mock.NewPersisterMock()
+		merger := createTrieMergerWithPersisters(t, dest, source1, source2)
+		_, err := merger.MergeTries("dest", [][]byte{rootHash, make([]byte, emptyTrieHashLength)}, "a", "b")
+		assert.Nil(t, err)
+
+		encodedRoot, _ := source2.Get(rootHash)
+		copiedRoot, _ := dest.Get(rootHash)
+		assert.Equal(t, encodedRoot, copiedRoot)
+		assert.Nil(t, dest.Has(leaf1))
+		assert.Nil(t, dest.Has(leaf2))
+	})
+	t.Run("missing node should error", func(t *testing.T) {
+		t.Parallel()
+
+		source := mock.NewPersisterMock()
+		rootHash := putBranchNode(t, source, testHasher.Compute("missing"))
+
+		closeCalled := false
+		dest := mock.NewPersisterMock()
+		dest.CloseCalled = func() error {
+			closeCalled = true
+			return nil
+		}
+		merger := createTrieMergerWithPersisters(t, dest, source)
+		destPersister, err := merger.MergeTries("dest", [][]byte{rootHash}, "a")
+		assert.True(t, check.IfNil(destPersister))
+		assert.True(t, errors.Is(err, errMissingTrieNode))
+		assert.True(t, closeCalled)
+	})
+}
+
+func TestDecodeTrieNode(t *testing.T) {
+	t.Parallel()
+
+	t.Run("empty node should error", func(t *testing.T) {
+		t.Parallel()
+
+		node, err := decodeTrieNode(testMarshaller, nil)
+		assert.Nil(t, node)
+		assert.True(t, errors.Is(err, errInvalidTrieNode))
+	})
+	t.Run("unknown node type should error", func(t *testing.T) {
+		t.Parallel()
+
+		node, err := decodeTrieNode(testMarshaller, []byte{1, 2, 3})
+		assert.Nil(t, node)
+		assert.True(t, errors.Is(err, errInvalidTrieNode))
+	})
+	t.Run("extension node", func(t *testing.T) {
+		t.Parallel()
+
+		persister := mock.NewPersisterMock()
+		hash := putTrieNode(t, persister, &trie.CollapsedEn{Key: []byte("key"), EncodedChild: []byte("child")}, extensionNodeType)
+		encodedNode, _ := persister.Get(hash)
+
+		node, err := decodeTrieNode(testMarshaller, encodedNode)
+		assert.Nil(t, err)
+		assert.Equal(t, [][]byte{[]byte("child")}, node.childrenHashes)
+		assert.False(t, node.isLeaf)
+	})
+	t.Run("leaf node", func(t *testing.T) {
+		t.Parallel()
+
+		persister := mock.NewPersisterMock()
+		hash := putLeafNode(t, persister, "key", []byte("value"))
+		encodedNode, _ := persister.Get(hash)
+
+		node, err := decodeTrieNode(testMarshaller, encodedNode)
+		assert.Nil(t, err)
+		assert.Empty(t, node.childrenHashes)
+		assert.Equal(t, []byte("value"), node.value)
+		assert.True(t, node.isLeaf)
+	})
+}
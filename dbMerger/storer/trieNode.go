@@ -0,0 +1,67 @@
+package storer
+
+import (
+	"fmt"
+
+	"github.com/multiversx/mx-chain-core-go/marshal"
+	"github.com/multiversx/mx-chain-go/trie"
+)
+
+// the node types, as appended by the trie package after every marshalled node
+const (
+	extensionNodeType = iota
+	leafNodeType
+	branchNodeType
+)
+
+// trieNode holds the hashes of the children of a decoded trie node, or its value if it is a leaf
+type trieNode struct {
+	childrenHashes [][]byte
+	value          []byte
+	isLeaf         bool
+}
+
+// decodeTrieNode decodes a node as it is saved in the trie storage: the marshalled node followed by its type
+func decodeTrieNode(marshaller marshal.Marshalizer, encodedNode []byte) (*trieNode, error) {
+	if len(encodedNode) < 1 {
+		return nil, fmt.Errorf("%w, empty encoded node", errInvalidTrieNode)
+	}
+
+	nodeType := encodedNode[len(encodedNode)-1]
+	marshalledNode := encodedNode[:len(encodedNode)-1]
+	switch nodeType {
+	case extensionNodeType:
+		extension := &trie.CollapsedEn{}
+		err := marshaller.Unmarshal(extension, marshalledNode)
+		if err != nil {
+			return nil, fmt.Errorf("%w while decoding an extension node", err)
+		}
+
+		return &trieNode{childrenHashes: [][]byte{extension.EncodedChild}}, nil
+	case leafNodeType:
+		leaf := &trie.CollapsedLn{}
+		err := marshaller.Unmarshal(leaf, marshalledNode)
+		if err != nil {
+			return nil, fmt.Errorf("%w while decoding a leaf node", err)
+		}
+
+		return &trieNode{value: leaf.Value, isLeaf: true}, nil
+	case branchNodeType:
+		branch := &trie.CollapsedBn{}
+		err := marshaller.Unmarshal(branch, marshalledNode)
+		if err != nil {
+			return nil, fmt.Errorf("%w while decoding a branch node", err)
+		}
+
+		childrenHashes := make([][]byte, 0, len(branch.EncodedChildren))
+		for _, childHash := range branch.EncodedChildren {
+			if len(childHash) > 0 {
+				childrenHashes = append(childrenHashes, childHash)
+			}
+		}
+
+		return &trieNode{childrenHashes: childrenHashes}, nil
+	default:
+		return nil, fmt.Errorf("%w, unknown node type %d", errInvalidTrieNode, nodeType)
+	}
+}
@@ -0,0 +1,295 @@
+package checkpoint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	logger "github.com/multiversx/mx-chain-logger-go"
+)
+
+var log = logger.GetOrCreate("checkpoint")
+
+// defaultSaveInterval is the minimum time between two saves of the file caused by acknowledged bulks. The completed
+// indices and intervals are saved right away
+const defaultSaveInterval = 5 * time.Second
+
+// Progress holds the progress of a copy operation (a whole index or only a timestamp interval of an index). Only the
+// timestamp intervals can be resumed from the last acknowledged bulk, using LastTimestamp. The documents having that
+// timestamp are copied again on resume, so DocsBeforeLastTimestamp is the number of documents still counted then
+type Progress struct {
+	Completed               bool   `json:"completed"`
+	LastTimestamp           int64  `json:"lastTimestamp,omitempty"`
+	DocsWritten             uint64 `json:"docsWritten"`
+	DocsBeforeLastTimestamp uint64 `json:"docsBeforeLastTimestamp,omitempty"`
+}
+
+// BulkInfo holds the information about a bulk of documents that was acknowledged by the destination
+type BulkInfo struct {
+	NumDocs       uint64
+	LastTimestamp int64
+	// NumDocsAtLastTimestamp is the number of documents of the bulk having the LastTimestamp
+	NumDocsAtLastTimestamp uint64
+}
+
+// IntervalProgress holds the progress of a timestamp interval of an index
+type IntervalProgress struct {
+	Start int64 `json:"start"`
+	Stop  int64 `json:"stop"`
+	Progress
+}
+
+// IndexProgress holds the progress of an index
+type IndexProgress struct {
+	Progress
+	Intervals []*IntervalProgress `json:"intervals,omitempty"`
+}
+
+type fileCheckpoint struct {
+	mut          sync.Mutex
+	filePath     string
+	indices      map[string]*IndexProgress
+	saveInterval time.Duration
+	lastSave     time.Time
+}
+
+// NewFileCheckpoint will create a new instance of a checkpoint handler that persists the progress in the provided file.
+// If resume is set, the progress saved by a previous run is loaded from the file and every partially copied interval
+// is rewound to the position it is resumed from
+func NewFileCheckpoint(filePath string, resume bool) (*fileCheckpoint, error) {
+	if filePath == "" {
+		return nil, errors.New("empty checkpoint file path")
+	}
+
+	fc := &fileCheckpoint{
+		filePath:     filePath,
+		indices:      make(map[string]*IndexProgress),
+		saveInterval: defaultSaveInterval,
+	}
+	if !resume {
+		return fc, nil
+	}
+
+	fileBytes, err := os.ReadFile(filePath)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Info("checkpoint file does not exist, starting from scratch", "path", filePath)
+		return fc, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading the checkpoint file %s", err, filePath)
+	}
+
+	err = json.Unmarshal(fileBytes, &fc.indices)
+	if err != nil {
+		return nil, fmt.Errorf("%w while decoding the checkpoint file %s", err, filePath)
+	}
+
+	for _, indexProgress := range fc.indices {
+		for _, interv := range indexProgress.Intervals {
+			if !interv.Completed {
+				interv.DocsWritten = interv.DocsBeforeLastTimestamp
+			}
+		}
+	}
+
+	log.Info("resuming from checkpoint file", "path", filePath, "num indices", len(fc.indices))
+
+	return fc, nil
+}
+
+// GetIndexProgress returns the progress of the provided index
+func (fc *fileCheckpoint) GetIndexProgress(index string) Progress {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	indexProgress, found := fc.indices[index]
+	if !found {
+		return Progress{}
+	}
+
+	return indexProgress.Progress
+}
+
+// UpdateIndexProgress will record that the provided bulk was acknowledged for the provided index
+func (fc *fileCheckpoint) UpdateIndexProgress(index string, bulk BulkInfo) error {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	indexProgress := fc.getOrCreateIndex(index)
+	updateProgress(&indexProgress.Progress, bulk)
+
+	return fc.saveIfDueUnprotected()
+}
+
+// RestartIndex will reset the progress of the provided index, which is copied again from its beginning
+func (fc *fileCheckpoint) RestartIndex(index string) error {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	indexProgress := fc.getOrCreateIndex(index)
+	indexProgress.Progress = Progress{}
+
+	return fc.saveUnprotected()
+}
+
+// MarkIndexCompleted will mark the provided index as completed
+func (fc *fileCheckpoint) MarkIndexCompleted(index string) error {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	fc.getOrCreateIndex(index).Completed = true
+
+	return fc.saveUnprotected()
+}
+
+// GetIntervals returns the timestamp intervals that were planned for the provided index
+func (fc *fileCheckpoint) GetIntervals(index string) []IntervalProgress {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	indexProgress, found := fc.indices[index]
+	if !found {
+		return nil
+	}
+
+	intervals := make([]IntervalProgress, 0, len(indexProgress.Intervals))
+	for _, interv := range indexProgress.Intervals {
+		intervals = append(intervals, *interv)
+	}
+
+	return intervals
+}
+
+// SetIntervals will save the timestamp intervals planned for the provided index
+func (fc *fileCheckpoint) SetIntervals(index string, intervals []IntervalProgress) error {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	indexProgress := fc.getOrCreateIndex(index)
+	indexProgress.Intervals = make([]*IntervalProgress, 0, len(intervals))
+	for i := range intervals {
+		interv := intervals[i]
+		indexProgress.Intervals = append(indexProgress.Intervals, &interv)
+	}
+
+	return fc.saveUnprotected()
+}
+
+// GetIntervalProgress returns the progress of the provided timestamp interval
+func (fc *fileCheckpoint) GetIntervalProgress(index string, start, stop int64) Progress {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	interv := fc.getIntervalUnprotected(index, start, stop)
+	if interv == nil {
+		return Progress{}
+	}
+
+	return interv.Progress
+}
+
+// UpdateIntervalProgress will record that the provided bulk was acknowledged for the provided timestamp interval
+func (fc *fileCheckpoint) UpdateIntervalProgress(index string, start, stop int64, bulk BulkInfo) error {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	interv := fc.getOrCreateIntervalUnprotected(index, start, stop)
+	updateProgress(&interv.Progress, bulk)
+
+	return fc.saveIfDueUnprotected()
+}
+
+// MarkIntervalCompleted will mark the provided timestamp interval as completed
+func (fc *fileCheckpoint) MarkIntervalCompleted(index string, start, stop int64) error {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	fc.getOrCreateIntervalUnprotected(index, start, stop).Completed = true
+
+	return fc.saveUnprotected()
+}
+
+func (fc *fileCheckpoint) getOrCreateIndex(index string) *IndexProgress {
+	indexProgress, found := fc.indices[index]
+	if !found {
+		indexProgress = &IndexProgress{}
+		fc.indices[index] = indexProgress
+	}
+
+	return indexProgress
+}
+
+func (fc *fileCheckpoint) getIntervalUnprotected(index string, start, stop int64) *IntervalProgress {
+	indexProgress, found := fc.indices[index]
+	if !found {
+		return nil
+	}
+
+	for _, interv := range indexProgress.Intervals {
+		if interv.Start == start && interv.Stop == stop {
+			return interv
+		}
+	}
+
+	return nil
+}
+
+func (fc *fileCheckpoint) getOrCreateIntervalUnprotected(index string, start, stop int64) *IntervalProgress {
+	interv := fc.getIntervalUnprotected(index, start, stop)
+	if interv != nil {
+		return interv
+	}
+
+	interv = &IntervalProgress{
+		Start: start,
+		Stop:  stop,
+	}
+	indexProgress := fc.getOrCreateIndex(index)
+	indexProgress.Intervals = append(indexProgress.Intervals, interv)
+
+	return interv
+}
+
+// saveIfDueUnprotected saves the progress only if the last save is older than the save interval, so the file is not
+// rewritten after every bulk. A run stopped in between is resumed from an older, but consistent, progress
+func (fc *fileCheckpoint) saveIfDueUnprotected() error {
+	if time.Since(fc.lastSave) < fc.saveInterval {
+		return nil
+	}
+
+	return fc.saveUnprotected()
+}
+
+func (fc *fileCheckpoint) saveUnprotected() error {
+	fc.lastSave = time.Now()
+
+	fileBytes, err := json.MarshalIndent(fc.indices, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	// write into a temporary file first so a crash while writing will not corrupt the previous checkpoint
+	tmpFilePath := fc.filePath + ".tmp"
+	err = os.WriteFile(tmpFilePath, fileBytes, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFilePath, fc.filePath)
+}
+
+func updateProgress(progress *Progress, bulk BulkInfo) {
+	if bulk.LastTimestamp > progress.LastTimestamp {
+		progress.DocsBeforeLastTimestamp = progress.DocsWritten + bulk.NumDocs - bulk.NumDocsAtLastTimestamp
+		progress.LastTimestamp = bulk.LastTimestamp
+	}
+	progress.DocsWritten += bulk.NumDocs
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (fc *fileCheckpoint) IsInterfaceNil() bool {
+	return fc == nil
+}
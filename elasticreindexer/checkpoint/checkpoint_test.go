@@ -0,0 +1,92 @@
+package checkpoint
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewFileCheckpoint(t *testing.T) {
+	t.Parallel()
+
+	fc, err := NewFileCheckpoint("", false)
+	require.Nil(t, fc)
+	require.Error(t, err)
+
+	fc, err = NewFileCheckpoint(filepath.Join(t.TempDir(), "missing.json"), true)
+	require.NoError(t, err)
+	require.Equal(t, Progress{}, fc.GetIndexProgress("index"))
+}
+
+func TestFileCheckpoint_ResumeShouldLoadSavedProgress(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "checkpoint.json")
+	fc, _ := NewFileCheckpoint(filePath, false)
+	fc.saveInterval = 0
+
+	require.NoError(t, fc.UpdateIndexProgress("accounts", BulkInfo{NumDocs: 10}))
+	require.NoError(t, fc.MarkIndexCompleted("accounts"))
+
+	require.NoError(t, fc.SetIntervals("blocks", []IntervalProgress{{Start: 1, Stop: 10}, {Start: 10, Stop: 20}}))
+	require.NoError(t, fc.MarkIntervalCompleted("blocks", 1, 10))
+	require.NoError(t, fc.UpdateIntervalProgress("blocks", 10, 20, BulkInfo{NumDocs: 5, LastTimestamp: 15}))
+	require.NoError(t, fc.UpdateIntervalProgress("blocks", 10, 20, BulkInfo{NumDocs: 3, LastTimestamp: 17}))
+
+	resumed, err := NewFileCheckpoint(filePath, true)
+	require.NoError(t, err)
+	require.Equal(t, Progress{Completed: true, DocsWritten: 10}, resumed.GetIndexProgress("accounts"))
+	require.Len(t, resumed.GetIntervals("blocks"), 2)
+	require.True(t, resumed.GetIntervalProgress("blocks", 1, 10).Completed)
+
+	progress := resumed.GetIntervalProgress("blocks", 10, 20)
+	require.False(t, progress.Completed)
+	require.Equal(t, uint64(8), progress.DocsWritten)
+	require.Equal(t, int64(17), progress.LastTimestamp)
+
+	notResumed, err := NewFileCheckpoint(filePath, false)
+	require.NoError(t, err)
+	require.Empty(t, notResumed.GetIntervals("blocks"))
+}
+
+func TestFileCheckpoint_ResumeShouldRewindTheIntervalsToTheirLastTimestamp(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "checkpoint.json")
+	fc, _ := NewFileCheckpoint(filePath, false)
+	fc.saveInterval = 0
+
+	require.NoError(t, fc.UpdateIntervalProgress("blocks", 10, 20, BulkInfo{NumDocs: 5, LastTimestamp: 12, NumDocsAtLastTimestamp: 2}))
+	require.NoError(t, fc.UpdateIntervalProgress("blocks", 10, 20, BulkInfo{NumDocs: 4, LastTimestamp: 12, NumDocsAtLastTimestamp: 4}))
+	progress := fc.GetIntervalProgress("blocks", 10, 20)
+	require.Equal(t, uint64(9), progress.DocsWritten)
+	require.Equal(t, uint64(3), progress.DocsBeforeLastTimestamp)
+
+	require.NoError(t, fc.UpdateIndexProgress("accounts", BulkInfo{NumDocs: 7}))
+	require.NoError(t, fc.RestartIndex("accounts"))
+	require.Equal(t, Progress{}, fc.GetIndexProgress("accounts"))
+
+	// the 6 documents having the timestamp 12 are copied again, so they are not counted twice
+	resumed, err := NewFileCheckpoint(filePath, true)
+	require.NoError(t, err)
+	progress = resumed.GetIntervalProgress("blocks", 10, 20)
+	require.Equal(t, uint64(3), progress.DocsWritten)
+	require.Equal(t, int64(12), progress.LastTimestamp)
+}
+
+func TestFileCheckpoint_BulksShouldBeSavedAtMostOncePerInterval(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "checkpoint.json")
+	fc, _ := NewFileCheckpoint(filePath, false)
+
+	require.NoError(t, fc.UpdateIndexProgress("accounts", BulkInfo{NumDocs: 10}))
+	require.NoError(t, fc.UpdateIndexProgress("accounts", BulkInfo{NumDocs: 5}))
+	saved, _ := NewFileCheckpoint(filePath, true)
+	require.Equal(t, uint64(10), saved.GetIndexProgress("accounts").DocsWritten)
+
+	require.NoError(t, fc.MarkIndexCompleted("accounts"))
+	saved, _ = NewFileCheckpoint(filePath, true)
+	require.Equal(t, Progress{Completed: true, DocsWritten: 15}, saved.GetIndexProgress("accounts"))
+}
@@ -0,0 +1,159 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+
+	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/deduplicator"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/elastic"
+	"github.com/pelletier/go-toml"
+	"github.com/urfave/cli"
+)
+
+type Cfg struct {
+	ClusterConfig struct {
+		URL         string           `toml:"url"`
+		Username    string           `toml:"username"`
+		Password    string           `toml:"password"`
+		APIKey      string           `toml:"api-key"`
+		BearerToken string           `toml:"bearer-token"`
+		TLS         config.TLSConfig `toml:"tls"`
+		Aliases     []string         `toml:"aliases"`
+		PageSize    int              `toml:"page-size"`
+		KeepRule    string           `toml:"keep-rule"`
+		KeepField   string           `toml:"keep-field"`
+	} `toml:"config"`
+}
+
+var (
+	log = logger.GetOrCreate("main")
+
+	// configFileFlag defines the path of the config file
+	configFileFlag = cli.StringFlag{
+		Name:  "config",
+		Usage: "The path of the config file",
+		Value: "./config.toml",
+	}
+	// deleteFlag defines a bool flag for deleting the duplicated documents
+	deleteFlag = cli.BoolFlag{
+		Name:  "delete",
+		Usage: "If set, the duplicated documents are deleted in bulk, keeping the copy chosen by the keep-rule from the config file. Otherwise, they are only reported",
+	}
+	// reportFileFlag defines the path of the file where the report is written
+	reportFileFlag = cli.StringFlag{
+		Name:  "report-file",
+		Usage: "The path of the file where the JSON report of the duplicated documents is written",
+		Value: "./duplicates-report.json",
+	}
+)
+
+const helpTemplate = `NAME:
+   {{.Name}} - {{.Usage}}
+USAGE:
+   {{.HelpName}} {{if .VisibleFlags}}[global options]{{end}}
+   {{if len .Authors}}
+AUTHOR:
+   {{range .Authors}}{{ . }}{{end}}
+   {{end}}{{if .Commands}}
+GLOBAL OPTIONS:
+   {{range .VisibleFlags}}{{.}}
+   {{end}}
+VERSION:
+   {{.Version}}
+   {{end}}
+`
+
+func main() {
+	app := cli.NewApp()
+	cli.AppHelpTemplate = helpTemplate
+	app.Name = "Deduplicator"
+	app.Version = "v1.0.0"
+	app.Usage = "Finds and removes the documents with the same _id from the consecutive indices behind an alias"
+	app.Flags = []cli.Flag{
+		configFileFlag,
+		deleteFlag,
+		reportFileFlag,
+	}
+	app.Authors = []cli.Author{
+		{
+			Name:  "The MultiversX Team",
+			Email: "contact@multiversx.com",
+		},
+	}
+
+	app.Action = deduplicate
+
+	err := app.Run(os.Args)
+	if err != nil {
+		log.Error("cannot run app", "error", err)
+		os.Exit(1)
+	}
+}
+
+func deduplicate(ctx *cli.Context) {
+	cfg, err := loadConfigFile(ctx.String(configFileFlag.Name))
+	if err != nil {
+		log.Error("cannot load config file", "error", err)
+		return
+	}
+
+	databaseClient, err := elastic.NewElasticClient(config.ElasticInstanceConfig{
+		URL:         cfg.ClusterConfig.URL,
+		Username:    cfg.ClusterConfig.Username,
+		Password:    cfg.ClusterConfig.Password,
+		APIKey:      cfg.ClusterConfig.APIKey,
+		BearerToken: cfg.ClusterConfig.BearerToken,
+		TLS:         cfg.ClusterConfig.TLS,
+	})
+	if err != nil {
+		log.Error("cannot create elastic client", "error", err)
+		return
+	}
+
+	dedup, err := deduplicator.NewDeduplicator(deduplicator.ArgsDeduplicator{
+		Client:    databaseClient,
+		KeepRule:  cfg.ClusterConfig.KeepRule,
+		KeepField: cfg.ClusterConfig.KeepField,
+		PageSize:  cfg.ClusterConfig.PageSize,
+		Delete:    ctx.Bool(deleteFlag.Name),
+	})
+	if err != nil {
+		log.Error("cannot create deduplicator", "error", err)
+		return
+	}
+
+	report, err := dedup.Process(cfg.ClusterConfig.Aliases...)
+	if err != nil {
+		log.Error("cannot check the duplicated documents", "error", err)
+		return
+	}
+
+	reportFile := ctx.String(reportFileFlag.Name)
+	err = report.SaveToFile(reportFile)
+	if err != nil {
+		log.Error("cannot save the report", "error", err)
+		return
+	}
+
+	for _, aliasReport := range report.Aliases {
+		log.Info("duplicated documents", "alias", aliasReport.Alias, "num", aliasReport.NumDuplicates)
+	}
+	log.Info("report saved", "file", reportFile, "deleted", report.Deleted)
+}
+
+func loadConfigFile(filePath string) (*Cfg, error) {
+	tomlBytes, err := ioutil.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	var cfg Cfg
+	err = toml.Unmarshal(tomlBytes, &cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cfg, nil
+}
@@ -1,33 +1,83 @@
 package main
 
 import (
+	"context"
 	"io/ioutil"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
 
 	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/metrics"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/process"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/transform"
 	"github.com/pelletier/go-toml"
 	"github.com/urfave/cli"
 )
 
-const tomlFile = "./config.toml"
-
 var (
 	log = logger.GetOrCreate("main")
 
+	// configFileFlag defines the path of the configuration file
+	configFileFlag = cli.StringFlag{
+		Name:  "config",
+		Usage: "The path of the configuration file",
+		Value: "./config.toml",
+	}
+
 	// overwrite defines a bool flag for overwriting destination's data and skipping mappings and aliases checks
 	overwriteFlag = cli.BoolFlag{
 		Name:  "overwrite",
-		Usage: "If set, the reindexing tool will skip the creation of the index and mapping and will overwrite any existing data.",
+		Usage: "If set, the reindexing tool will skip the creation of the index and mapping and will overwrite any existing data. It cannot be used in the reindex read mode.",
 	}
 	// skipMappingsFlag defines a bool flag for skipping the copying of the source's mapping into the destination
 	skipMappingsFlag = cli.BoolFlag{
 		Name:  "skip-mappings",
 		Usage: "If set, the reindexing tool will skip the copying of the mappings",
 	}
+	// forceMappingsFlag defines a bool flag for starting the copy even if the mapping check found blocking conflicts
+	forceMappingsFlag = cli.BoolFlag{
+		Name:  "force-mappings",
+		Usage: "If set, the reindexing tool will start even if the source mappings have blocking conflicts with the destination mappings",
+	}
+	// resumeFlag defines a bool flag for resuming the reindexing from the checkpoint file
+	resumeFlag = cli.BoolFlag{
+		Name:  "resume",
+		Usage: "If set, the reindexing tool will skip the indices and intervals already copied in a previous run, as saved in the checkpoint file. The partially copied intervals are resumed from their last acknowledged bulk, while the partially copied indices without timestamp are copied again from the beginning",
+	}
+	// checkpointFileFlag defines the path of the file where the reindexing progress is saved
+	checkpointFileFlag = cli.StringFlag{
+		Name:  "checkpoint-file",
+		Usage: "The path of the file where the reindexing progress is saved",
+		Value: "./checkpoint.json",
+	}
+	// verifyFlag defines a bool flag for comparing the source and destination clusters instead of copying the data
+	verifyFlag = cli.BoolFlag{
+		Name:  "verify",
+		Usage: "If set, the reindexing tool will not copy any data, but it will compare the counts and a sample of documents of the source and destination clusters",
+	}
+	// verifyReportFlag defines the path of the file where the verification report is written
+	verifyReportFlag = cli.StringFlag{
+		Name:  "verify-report",
+		Usage: "The path of the file where the verification report is written. The report is written as HTML if the file has the .html extension, or as JSON otherwise",
+		Value: "./verify-report.json",
+	}
+	// followFlag defines a bool flag for keeping the destination cluster in sync after the initial copy
+	followFlag = cli.BoolFlag{
+		Name:  "follow",
+		Usage: "If set, after the initial copy the reindexing tool will keep copying the new documents until it is stopped with a signal",
+	}
 )
 
+// customTransformers holds the factories of the custom transformation steps, by their type. A custom step implements
+// the transform.Transformer interface and can be used in the [config.transforms] section like the built-in ones
+var customTransformers = map[string]transform.TransformerFactory{}
+
 const helpTemplate = `NAME:
    {{.Name}} - {{.Usage}}
 USAGE:
@@ -45,14 +95,29 @@ VERSION:
 `
 
 func main() {
+	err := newApp().Run(os.Args)
+	if err != nil {
+		log.Error(err.Error())
+		os.Exit(1)
+	}
+}
+
+func newApp() *cli.App {
 	app := cli.NewApp()
 	cli.AppHelpTemplate = helpTemplate
 	app.Name = "Elasticsearch reindexing CLI App"
 	app.Version = "v1.0.0"
 	app.Usage = "This is the entry point for Elasticsearch reindexing tool"
 	app.Flags = []cli.Flag{
+		configFileFlag,
 		overwriteFlag,
 		skipMappingsFlag,
+		forceMappingsFlag,
+		resumeFlag,
+		checkpointFileFlag,
+		verifyFlag,
+		verifyReportFlag,
+		followFlag,
 	}
 	app.Authors = []cli.Author{
 		{
@@ -63,33 +128,76 @@ func main() {
 
 	app.Action = startReindexing
 
-	err := app.Run(os.Args)
-	if err != nil {
-		log.Error(err.Error())
-		os.Exit(1)
-	}
+	return app
 }
 
 func startReindexing(ctx *cli.Context) {
-	cfg, err := loadConfig()
+	cfg, err := loadConfig(ctx.String(configFileFlag.Name))
 	if err != nil {
 		log.Error("cannot load configuration", "error", err)
 		return
 	}
+	if ctx.Bool(overwriteFlag.Name) && cfg.Indexers.Reader.Mode == process.ReindexReadMode {
+		log.Error("the --overwrite flag cannot be used in the reindex read mode, as the destination is not cleared before the cluster copies the documents")
+		return
+	}
 
-	reindexer, err := process.CreateReindexer(cfg)
+	outputs := process.OutputConfigs(cfg.Indexers)
+	checkpointHandlers := make([]process.CheckpointHandler, 0, len(outputs))
+	for _, output := range outputs {
+		checkpointFile := filePathForOutput(ctx.String(checkpointFileFlag.Name), output.Name, len(outputs))
+		checkpointHandler, errCheckpoint := checkpoint.NewFileCheckpoint(checkpointFile, ctx.Bool(resumeFlag.Name))
+		if errCheckpoint != nil {
+			log.Error("cannot create checkpoint handler", "output", output.Name, "error", errCheckpoint)
+			return
+		}
+
+		checkpointHandlers = append(checkpointHandlers, checkpointHandler)
+	}
+	// the intervals planned for the indices with timestamp are saved in the checkpoint of the first output
+	checkpointHandler := checkpointHandlers[0]
+
+	metricsCollector := metrics.NewCollector()
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	defer stopMetrics()
+
+	metricsCollector.StartSummaryLoop(metricsCtx, time.Duration(cfg.Indexers.Metrics.SummaryIntervalInSeconds)*time.Second)
+	if cfg.Indexers.Metrics.ListenAddress != "" {
+		err = metrics.StartServer(metricsCtx, cfg.Indexers.Metrics.ListenAddress, metricsCollector)
+		if err != nil {
+			log.Error("cannot start the metrics server", "error", err)
+			return
+		}
+	}
+
+	reindexer, err := process.CreateReindexer(cfg, checkpointHandlers, customTransformers, metricsCollector)
 	if err != nil {
 		log.Error("cannot create reindexer", "error", err)
 		return
 	}
 
-	multiWriteReindexer, err := process.NewReindexerMultiWrite(reindexer, cfg.Indexers.IndicesConfig)
+	if ctx.Bool(verifyFlag.Name) {
+		for _, output := range outputs {
+			verify(cfg, output, reindexer, filePathForOutput(ctx.String(verifyReportFlag.Name), output.Name, len(outputs)))
+		}
+		return
+	}
+
+	multiWriteReindexer, err := process.NewReindexerMultiWrite(reindexer, cfg.Indexers.IndicesConfig, checkpointHandler)
 	if err != nil {
 		log.Error("cannot create multi-write reindexer", "error", err)
 		return
 	}
 
 	skipMappings := ctx.Bool(skipMappingsFlag.Name)
+	if cfg.Indexers.MappingCheck.Enabled {
+		err = multiWriteReindexer.CheckMappings(skipMappings, ctx.Bool(forceMappingsFlag.Name))
+		if err != nil {
+			log.Error(err.Error())
+			return
+		}
+	}
+
 	err = multiWriteReindexer.ProcessNoTimestamp(ctx.Bool(overwriteFlag.Name), skipMappings)
 	if err != nil {
 		log.Error(err.Error())
@@ -101,10 +209,67 @@ func startReindexing(ctx *cli.Context) {
 		log.Error(err.Error())
 		return
 	}
+
+	if ctx.Bool(followFlag.Name) {
+		follow(cfg, reindexer, checkpointHandler)
+	}
+}
+
+func follow(cfg *config.GeneralConfig, reindexer process.ReindexerHandler, checkpointHandler process.CheckpointHandler) {
+	follower, err := process.NewFollower(reindexer, checkpointHandler, cfg.Indexers.IndicesConfig, cfg.Indexers.Follow)
+	if err != nil {
+		log.Error("cannot create follower", "error", err)
+		return
+	}
+
+	followCtx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, syscall.SIGINT, syscall.SIGTERM)
+	go func() {
+		<-sigs
+		log.Info("terminating at user's signal...")
+		cancel()
+	}()
+
+	follower.Follow(followCtx)
+}
+
+func verify(cfg *config.GeneralConfig, output config.ElasticInstanceConfig, reindexer process.ReindexerHandler, reportFilePath string) {
+	verifier, err := process.CreateVerifier(cfg, output, reindexer, customTransformers)
+	if err != nil {
+		log.Error("cannot create verifier", "output", output.Name, "error", err)
+		return
+	}
+
+	report, err := verifier.Verify()
+	if err != nil {
+		log.Error("cannot verify indices", "output", output.Name, "error", err)
+		return
+	}
+
+	err = report.SaveToFile(reportFilePath)
+	if err != nil {
+		log.Error("cannot save verification report", "output", output.Name, "error", err)
+		return
+	}
+
+	log.Info("verification done", "output", output.Name, "passed", report.Passed, "report", reportFilePath)
+}
+
+// filePathForOutput returns the provided file path with the name of the output added before the extension, when there
+// are more outputs, e.g. checkpoint-replica.json
+func filePathForOutput(filePath string, outputName string, numOutputs int) string {
+	if numOutputs < 2 {
+		return filePath
+	}
+
+	extension := filepath.Ext(filePath)
+
+	return strings.TrimSuffix(filePath, extension) + "-" + outputName + extension
 }
 
-func loadConfig() (*config.GeneralConfig, error) {
-	tomlBytes, err := loadBytesFromFile(tomlFile)
+func loadConfig(configFile string) (*config.GeneralConfig, error) {
+	tomlBytes, err := loadBytesFromFile(configFile)
 	if err != nil {
 		return nil, err
 	}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/fakeelastic"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	testStartTime = 1596117600
+	numTestDocs   = 40
+)
+
+const testConfig = `[config]
+    [config.input]
+        url = %q
+    [config.output]
+        url = %q
+    %s
+    [config.reader]
+        mode = %q
+        page-size = 7
+    [config.verify]
+        num-intervals = 4
+        sample-size = 10
+        [config.verify.bucket-fields]
+            accounts = "shardID"
+    [config.mapping-check]
+        enabled = true
+    [config.indices]
+        indices-no-timestamp = ["accounts"]
+        [config.indices.num-slices]
+            accounts = 2
+        [config.indices.with-timestamp]
+            enabled = true
+            num-parallel-writes = 2
+            blockchain-start-time = %d
+            indices-with-timestamp = ["blocks"]
+`
+
+func createSource(t *testing.T) *fakeelastic.Server {
+	source := fakeelastic.NewServer()
+	t.Cleanup(source.Close)
+
+	require.NoError(t, source.CreateIndex("accounts-000001", `{
+		"mappings":{"properties":{"address":{"type":"keyword"},"shardID":{"type":"long"}}},
+		"aliases":{"accounts":{"is_write_index":true}}
+	}`))
+	require.NoError(t, source.CreateIndex("blocks-000001", `{
+		"mappings":{"properties":{"nonce":{"type":"long"},"timestamp":{"type":"date"}}},
+		"aliases":{"blocks":{}}
+	}`))
+	for i := 0; i < numTestDocs; i++ {
+		require.NoError(t, source.IndexDocument("accounts", fmt.Sprintf("addr%d", i), fmt.Sprintf(`{"address":"addr%d","shardID":%d}`, i, i%3)))
+		require.NoError(t, source.IndexDocument("blocks", fmt.Sprintf("hash%d", i), fmt.Sprintf(`{"nonce":%d,"timestamp":%d}`, i, testStartTime+i*3600)))
+	}
+
+	return source
+}
+
+func createConfigFile(t *testing.T, sourceURL string, outputURL string, mode string, additionalOutputs string) string {
+	configFile := filepath.Join(t.TempDir(), "config.toml")
+	configBytes := []byte(fmt.Sprintf(testConfig, sourceURL, outputURL, additionalOutputs, mode, testStartTime))
+	require.NoError(t, ioutil.WriteFile(configFile, configBytes, 0644))
+
+	return configFile
+}
+
+func requireSameDocuments(t *testing.T, source *fakeelastic.Server, destination *fakeelastic.Server) {
+	for _, index := range []string{"accounts", "blocks"} {
+		require.Equal(t, source.Documents(index), destination.Documents(index), "index %s", index)
+		require.Equal(t, index+"-000001", destination.WriteIndex(index))
+		require.JSONEq(t, source.Mapping(index+"-000001"), destination.Mapping(index+"-000001"))
+	}
+}
+
+func TestElasticReindexer_ScrollMode(t *testing.T) {
+	source := createSource(t)
+	destination := fakeelastic.NewServer()
+	defer destination.Close()
+
+	configFile := createConfigFile(t, source.URL(), destination.URL(), "scroll", "")
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	err := newApp().Run([]string{"elasticreindexer", "--config", configFile, "--checkpoint-file", checkpointFile})
+	require.NoError(t, err)
+
+	requireSameDocuments(t, source, destination)
+}
+
+func TestElasticReindexer_ReindexModeWithOverwriteShouldNotCopy(t *testing.T) {
+	source := createSource(t)
+	destination := fakeelastic.NewServer()
+	defer destination.Close()
+
+	configFile := createConfigFile(t, source.URL(), destination.URL(), "reindex", "")
+	checkpointFile := filepath.Join(t.TempDir(), "checkpoint.json")
+	err := newApp().Run([]string{"elasticreindexer", "--config", configFile, "--checkpoint-file", checkpointFile, "--overwrite"})
+	require.NoError(t, err)
+
+	require.Empty(t, destination.Indices())
+}
+
+func TestElasticReindexer_PointInTimeModeWithMoreOutputsAndVerify(t *testing.T) {
+	source := createSource(t)
+	destination := fakeelastic.NewServer()
+	defer destination.Close()
+	replica := fakeelastic.NewServer()
+	defer replica.Close()
+
+	outputs := fmt.Sprintf(`[[config.outputs]]
+        name = "main"
+        url = %q
+    [[config.outputs]]
+        name = "replica"
+        url = %q`, destination.URL(), replica.URL())
+	configFile := createConfigFile(t, source.URL(), destination.URL(), "pit", outputs)
+	workingDir := t.TempDir()
+	checkpointFile := filepath.Join(workingDir, "checkpoint.json")
+	err := newApp().Run([]string{"elasticreindexer", "--config", configFile, "--checkpoint-file", checkpointFile})
+	require.NoError(t, err)
+
+	requireSameDocuments(t, source, destination)
+	requireSameDocuments(t, source, replica)
+
+	reportFile := filepath.Join(workingDir, "report.json")
+	err = newApp().Run([]string{"elasticreindexer", "--config", configFile, "--checkpoint-file", checkpointFile, "--verify", "--verify-report", reportFile})
+	require.NoError(t, err)
+
+	for _, outputName := range []string{"main", "replica"} {
+		report, errRead := ioutil.ReadFile(filePathForOutput(reportFile, outputName, 2))
+		require.NoError(t, errRead)
+		require.True(t, gjson.GetBytes(report, "passed").Bool(), string(report))
+		require.Equal(t, int64(2), gjson.GetBytes(report, "indices.#").Int(), string(report))
+	}
+}
@@ -2,31 +2,60 @@ package main
 
 import (
 	"bytes"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"os"
 	"path"
+	"strings"
 
 	logger "github.com/multiversx/mx-chain-logger-go"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/elastic"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/indices"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/reader"
 	"github.com/pelletier/go-toml"
 	"github.com/urfave/cli"
 )
 
 const (
-	indicesFolder  = "indices"
-	policiesFolder = "policies"
-	configFileName = "cluster.toml"
+	indicesFolder            = "indices"
+	policiesFolder           = "policies"
+	openSearchPoliciesFolder = "policies-opensearch"
+	configFileName           = "cluster.toml"
 )
 
+type writeIndexGetter interface {
+	GetWriteIndex(alias string) (string, error)
+}
+
+type backendGetter interface {
+	Backend() string
+}
+
+type elasticClient interface {
+	indices.ClusterHandler
+	writeIndexGetter
+	backendGetter
+	DoesTemplateExist(index string) bool
+	DoesAliasExist(alias string) bool
+	DoesIndexExist(index string) bool
+	CreateIndexWithMapping(targetIndex string, body *bytes.Buffer) error
+	PutAlias(index string, alias string) error
+	SetWriteIndexTrue(alias string, index string) error
+	AttachPolicy(index string, policyName string) error
+	Rollover(alias string) (string, string, error)
+}
+
 type Cfg struct {
 	ClusterConfig struct {
-		URL            string   `toml:"url"`
-		Username       string   `toml:"username"`
-		Password       string   `toml:"password"`
-		EnabledIndices []string `toml:"enabled-indices"`
+		URL            string           `toml:"url"`
+		Username       string           `toml:"username"`
+		Password       string           `toml:"password"`
+		APIKey         string           `toml:"api-key"`
+		BearerToken    string           `toml:"bearer-token"`
+		TLS            config.TLSConfig `toml:"tls"`
+		EnabledIndices []string         `toml:"enabled-indices"`
 		Policies       struct {
 			Enable            bool     `toml:"enable"`
 			IndicesWithPolicy []string `toml:"indices-with-policy"`
@@ -43,6 +72,31 @@ var (
 		Usage: "The path to the config folder",
 		Value: "./config",
 	}
+	// planFlag defines a bool flag for printing the differences between the cluster and the local files
+	planFlag = cli.BoolFlag{
+		Name:  "plan",
+		Usage: "If set, the tool will not create anything, but it will print the differences between the templates, policies and mappings from the cluster and the local files",
+	}
+	// applyFlag defines a bool flag for updating the templates, policies and mappings that differ from the local files
+	applyFlag = cli.BoolFlag{
+		Name:  "apply",
+		Usage: "If set, the tool will print the differences like --plan and then it will update the templates and policies that differ from the local files and add the new fields to the existing mappings",
+	}
+	// allowDestructiveFlag defines a bool flag for applying the template changes that modify or remove mapping fields
+	allowDestructiveFlag = cli.BoolFlag{
+		Name:  "allow-destructive",
+		Usage: "If set together with --apply, the templates having changed or removed mapping fields are updated too. The existing indices are never changed destructively",
+	}
+	// planFileFlag defines the path of the file where the plan is saved as JSON
+	planFileFlag = cli.StringFlag{
+		Name:  "plan-file",
+		Usage: "If set together with --plan or --apply, the plan is also saved as JSON in this file",
+	}
+	// rolloverIndicesFlag defines the aliases rolled over by the rollover command
+	rolloverIndicesFlag = cli.StringFlag{
+		Name:  "indices",
+		Usage: "Comma separated list of the aliases to roll over. If empty, all the enabled indices from the config file are rolled over",
+	}
 )
 
 const helpTemplate = `NAME:
@@ -62,6 +116,16 @@ VERSION:
 `
 
 func main() {
+	_ = logger.SetLogLevel("*:DEBUG")
+
+	err := newApp().Run(os.Args)
+	if err != nil {
+		log.Error("cannot run app", "error", err)
+		os.Exit(1)
+	}
+}
+
+func newApp() *cli.App {
 	app := cli.NewApp()
 	cli.AppHelpTemplate = helpTemplate
 	app.Name = "Index cr"
@@ -69,6 +133,10 @@ func main() {
 	app.Usage = "Elasticsearch indices creator tool"
 	app.Flags = []cli.Flag{
 		configPath,
+		planFlag,
+		applyFlag,
+		allowDestructiveFlag,
+		planFileFlag,
 	}
 	app.Authors = []cli.Author{
 		{
@@ -77,16 +145,17 @@ func main() {
 		},
 	}
 
-	_ = logger.SetLogLevel("*:DEBUG")
-
 	app.Action = createIndexesAndMappings
-
-	err := app.Run(os.Args)
-	if err != nil {
-		log.Error("cannot run app", "error", err)
-		os.Exit(1)
+	app.Commands = []cli.Command{
+		{
+			Name:   "rollover",
+			Usage:  "Creates the next generation of the indices behind the aliases and moves the write aliases on them",
+			Flags:  []cli.Flag{configPath, rolloverIndicesFlag},
+			Action: rolloverIndices,
+		},
 	}
 
+	return app
 }
 
 func createIndexesAndMappings(ctx *cli.Context) {
@@ -97,22 +166,40 @@ func createIndexesAndMappings(ctx *cli.Context) {
 		return
 	}
 
-	pathToMappings := path.Join(cfgPath, indicesFolder)
+	databaseClient, err := createClient(cfg)
+	if err != nil {
+		log.Error("cannot create elastic client", "error", err)
+		return
+	}
 
-	indexTemplateMap, err := reader.GetElasticTemplates(pathToMappings, cfg.ClusterConfig.EnabledIndices)
+	pathToMappings := path.Join(cfgPath, indicesFolder)
+	indexTemplateMap, err := loadTemplates(databaseClient, pathToMappings, cfg.ClusterConfig.EnabledIndices)
 	if err != nil {
 		log.Error("cannot load templates", "error", err)
 		return
 	}
 
-	err = createIndies(cfg, indexTemplateMap)
+	indexPolicyMap, err := loadPoliciesIfEnabled(databaseClient, cfg, pathToMappings)
+	if err != nil {
+		log.Error("cannot load policies", "error", err)
+		return
+	}
+
+	if ctx.Bool(planFlag.Name) || ctx.Bool(applyFlag.Name) {
+		err = planAndApply(ctx, databaseClient, indexTemplateMap, indexPolicyMap)
+		if err != nil {
+			log.Error("cannot plan the changes", "error", err)
+		}
+		return
+	}
+
+	err = createIndies(databaseClient, indexTemplateMap)
 	if err != nil {
 		log.Error("cannot create indices", "error", err)
 		return
 	}
 
-	pathToPolicies := path.Join(pathToMappings, policiesFolder)
-	err = createPoliciesIfEnabled(cfg, pathToPolicies)
+	err = createPolicies(databaseClient, indexPolicyMap)
 	if err != nil {
 		log.Error("cannot create indices policies", "error", err)
 	}
@@ -120,16 +207,90 @@ func createIndexesAndMappings(ctx *cli.Context) {
 	log.Info("all indices were created")
 }
 
-func createIndies(cfg *Cfg, indexTemplateMap map[string]*bytes.Buffer) error {
-	databaseClient, err := elastic.NewElasticClient(config.ElasticInstanceConfig{
-		URL:      cfg.ClusterConfig.URL,
-		Username: cfg.ClusterConfig.Username,
-		Password: cfg.ClusterConfig.Password,
+// rolloverIndices creates the next generation of every provided alias, e.g. blocks-000002 after blocks-000001, using the
+// index template for its settings and mappings, and moves the write alias on it
+func rolloverIndices(ctx *cli.Context) {
+	cfgPath := ctx.String(configPath.Name)
+	cfg, err := loadConfigFile(cfgPath)
+	if err != nil {
+		log.Error("cannot load config file", "error", err)
+		return
+	}
+
+	databaseClient, err := createClient(cfg)
+	if err != nil {
+		log.Error("cannot create elastic client", "error", err)
+		return
+	}
+
+	aliases := cfg.ClusterConfig.EnabledIndices
+	if ctx.String(rolloverIndicesFlag.Name) != "" {
+		aliases = strings.Split(ctx.String(rolloverIndicesFlag.Name), ",")
+	}
+
+	for _, alias := range aliases {
+		alias = strings.TrimSpace(alias)
+		if alias == "" {
+			continue
+		}
+
+		oldIndex, newIndex, errRollover := databaseClient.Rollover(alias)
+		if errRollover != nil {
+			log.Error("cannot roll over alias", "alias", alias, "error", errRollover)
+			return
+		}
+
+		log.Info("databaseClient.Rollover", "alias", alias, "old write index", oldIndex, "new write index", newIndex)
+	}
+}
+
+// planAndApply prints the differences between the cluster and the local files and, if the apply flag is set, updates
+// the cluster
+func planAndApply(
+	ctx *cli.Context,
+	databaseClient indices.ClusterHandler,
+	indexTemplateMap map[string]*bytes.Buffer,
+	indexPolicyMap map[string]*bytes.Buffer,
+) error {
+	planner, err := indices.NewPlanner(indices.ArgsPlanner{
+		Client:    databaseClient,
+		Templates: indexTemplateMap,
+		Policies:  indexPolicyMap,
 	})
 	if err != nil {
 		return err
 	}
 
+	plan, err := planner.Plan()
+	if err != nil {
+		return err
+	}
+
+	plan.Print(os.Stdout)
+	planFile := ctx.String(planFileFlag.Name)
+	if planFile != "" {
+		err = plan.SaveToFile(planFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	if !ctx.Bool(applyFlag.Name) {
+		log.Info("plan done", "has changes", plan.HasChanges())
+		return nil
+	}
+
+	err = planner.Apply(plan, ctx.Bool(allowDestructiveFlag.Name))
+	if err != nil {
+		return err
+	}
+
+	log.Info("all the changes were applied")
+
+	return nil
+}
+
+func createIndies(databaseClient elasticClient, indexTemplateMap map[string]*bytes.Buffer) error {
 	for index, indexData := range indexTemplateMap {
 		doesTemplateExists := databaseClient.DoesTemplateExist(index)
 		if !doesTemplateExists {
@@ -141,68 +302,153 @@ func createIndies(cfg *Cfg, indexTemplateMap map[string]*bytes.Buffer) error {
 			log.Info("databaseClient.PutIndexTemplate", "index", index)
 		}
 
-		indexWithSuffix := fmt.Sprintf("%s-%s", index, "000001")
-		alreadyExists := databaseClient.DoesIndexExist(index)
+		// after rollovers the alias has more backing indices, so only a missing alias needs the first generation
+		aliasExists := databaseClient.DoesAliasExist(index)
+		if aliasExists {
+			continue
+		}
+
+		indexWithSuffix := firstGenerationIndex(index)
+		alreadyExists := databaseClient.DoesIndexExist(indexWithSuffix)
 		if !alreadyExists {
 			errCreate := databaseClient.CreateIndexWithMapping(indexWithSuffix, nil)
 			if errCreate != nil {
 				return fmt.Errorf("databaseClient.CreateIndexWithMapping index: %s, error: %w", index, errCreate)
 			}
 
-			log.Info("databaseClient.CreateIndexWithMapping", "index", index)
+			log.Info("databaseClient.CreateIndexWithMapping", "index", indexWithSuffix)
 		}
 
-		aliasExists := databaseClient.DoesAliasExist(index)
-		if !aliasExists {
-			errAlias := databaseClient.PutAlias(indexWithSuffix, index)
-			if errAlias != nil {
-				return fmt.Errorf("databaseClient.PutAlias index: %s, error: %w", index, errAlias)
-			}
-
-			log.Info("databaseClient.PutAlias", "index", index)
+		errAlias := databaseClient.PutAlias(indexWithSuffix, index)
+		if errAlias != nil {
+			return fmt.Errorf("databaseClient.PutAlias index: %s, error: %w", index, errAlias)
 		}
+
+		log.Info("databaseClient.PutAlias", "index", index)
 	}
 
 	return nil
 }
 
-func createPoliciesIfEnabled(cfg *Cfg, pathToPolicies string) error {
-	if !cfg.ClusterConfig.Policies.Enable {
-		return nil
+func createPolicies(databaseClient elasticClient, indexPolicyMap map[string]*bytes.Buffer) error {
+	for index, policy := range indexPolicyMap {
+		writeIndex, errWriteIndex := getWriteIndex(databaseClient, index)
+		if errWriteIndex != nil {
+			return errWriteIndex
+		}
+
+		err := databaseClient.SetWriteIndexTrue(index, writeIndex)
+		if err != nil {
+			return fmt.Errorf("databaseClient.SetWriteIndexTrue index: %s, error: %w", index, err)
+		}
+		log.Info("databaseClient.SetWriteIndexTrue", "index", index, "write index", writeIndex)
+
+		policyName := indices.PolicyName(index)
+		err = databaseClient.PutPolicy(policyName, policy)
+		if err != nil {
+			return fmt.Errorf("databaseClient.PutPolicy index: %s, error: %w", index, err)
+		}
+
+		log.Info("databaseClient.PutPolicy", "index", index)
+
+		err = databaseClient.AttachPolicy(writeIndex, policyName)
+		if err != nil {
+			return fmt.Errorf("databaseClient.AttachPolicy index: %s, error: %w", writeIndex, err)
+		}
 	}
 
-	databaseClient, err := elastic.NewElasticClient(config.ElasticInstanceConfig{
-		URL:      cfg.ClusterConfig.URL,
-		Username: cfg.ClusterConfig.Username,
-		Password: cfg.ClusterConfig.Password,
+	return nil
+}
+
+func createClient(cfg *Cfg) (elasticClient, error) {
+	return elastic.NewElasticClient(config.ElasticInstanceConfig{
+		URL:         cfg.ClusterConfig.URL,
+		Username:    cfg.ClusterConfig.Username,
+		Password:    cfg.ClusterConfig.Password,
+		APIKey:      cfg.ClusterConfig.APIKey,
+		BearerToken: cfg.ClusterConfig.BearerToken,
+		TLS:         cfg.ClusterConfig.TLS,
 	})
+}
+
+// loadTemplates reads the index templates and, on OpenSearch, replaces their lifecycle settings with the Index State
+// Management ones
+func loadTemplates(databaseClient backendGetter, pathToMappings string, enabledIndices []string) (map[string]*bytes.Buffer, error) {
+	indexTemplateMap, err := reader.GetElasticTemplates(pathToMappings, enabledIndices)
 	if err != nil {
-		return err
+		return nil, err
+	}
+	if databaseClient.Backend() != elastic.BackendOpenSearch {
+		return indexTemplateMap, nil
 	}
 
-	indexPolicyMap, err := reader.GetElasticTemplates(pathToPolicies, cfg.ClusterConfig.Policies.IndicesWithPolicy)
-	if err != nil {
-		return err
+	for index, template := range indexTemplateMap {
+		converted, errConvert := elastic.ConvertToOpenSearchTemplate(template.Bytes())
+		if errConvert != nil {
+			return nil, fmt.Errorf("%w while converting the template of index %s", errConvert, index)
+		}
+		indexTemplateMap[index] = bytes.NewBuffer(converted)
 	}
 
-	for index, policy := range indexPolicyMap {
-		indexWithSuffix := fmt.Sprintf("%s-%s", index, "000001")
-		err = databaseClient.SetWriteIndexTrue(index, indexWithSuffix)
-		if err != nil {
-			return fmt.Errorf("databaseClient.SetWriteIndexTrue index: %s, error: %w", index, err)
+	return indexTemplateMap, nil
+}
+
+// loadPoliciesIfEnabled reads the lifecycle policies. On OpenSearch, the policies are converted from the Elasticsearch
+// lifecycle policies, unless the policies-opensearch folder holds an override for the index
+func loadPoliciesIfEnabled(databaseClient backendGetter, cfg *Cfg, pathToMappings string) (map[string]*bytes.Buffer, error) {
+	if !cfg.ClusterConfig.Policies.Enable {
+		return make(map[string]*bytes.Buffer), nil
+	}
+
+	indicesWithPolicy := cfg.ClusterConfig.Policies.IndicesWithPolicy
+	pathToPolicies := path.Join(pathToMappings, policiesFolder)
+	if databaseClient.Backend() != elastic.BackendOpenSearch {
+		return reader.GetElasticTemplates(pathToPolicies, indicesWithPolicy)
+	}
+
+	pathToOpenSearchPolicies := path.Join(pathToMappings, openSearchPoliciesFolder)
+	indexPolicyMap := make(map[string]*bytes.Buffer)
+	for _, index := range indicesWithPolicy {
+		policies, err := reader.GetElasticTemplates(pathToOpenSearchPolicies, []string{index})
+		if err == nil {
+			indexPolicyMap[index] = policies[index]
+			continue
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
 		}
-		log.Info("databaseClient.SetWriteIndexTrue", "index", index)
 
-		policyName := fmt.Sprintf("%s-%s", index, "policy")
-		err = databaseClient.PutPolicy(policyName, policy)
+		policies, err = reader.GetElasticTemplates(pathToPolicies, []string{index})
 		if err != nil {
-			return fmt.Errorf("databaseClient.PutPolicy index: %s, error: %w", index, err)
+			return nil, err
+		}
+		converted, err := elastic.ConvertToISMPolicy(policies[index].Bytes(), index+"-*")
+		if err != nil {
+			return nil, fmt.Errorf("%w while converting the policy of index %s", err, index)
 		}
+		log.Debug("converted lifecycle policy", "index", index)
+		indexPolicyMap[index] = bytes.NewBuffer(converted)
+	}
 
-		log.Info("databaseClient.PutPolicy", "index", index)
+	return indexPolicyMap, nil
+}
+
+// getWriteIndex returns the current write index behind the provided alias, or the first generation index if the
+// alias has more backing indices and none of them is marked as write index
+func getWriteIndex(databaseClient writeIndexGetter, alias string) (string, error) {
+	writeIndex, err := databaseClient.GetWriteIndex(alias)
+	if err != nil {
+		return "", fmt.Errorf("databaseClient.GetWriteIndex alias: %s, error: %w", alias, err)
+	}
+	if writeIndex == "" {
+		return firstGenerationIndex(alias), nil
 	}
 
-	return nil
+	return writeIndex, nil
+}
+
+func firstGenerationIndex(index string) string {
+	return fmt.Sprintf("%s-%s", index, "000001")
 }
 
 func loadConfigFile(pathStr string) (*Cfg, error) {
@@ -0,0 +1,119 @@
+package main
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/elastic"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/fakeelastic"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/indices"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+// createConfigFolder creates a config folder for the provided cluster, using the templates and policies of the
+// ./config folder
+func createConfigFolder(t *testing.T, clusterURL string, enabledIndices string) string {
+	configFolder := t.TempDir()
+	indicesPath, err := filepath.Abs(filepath.Join("config", indicesFolder))
+	require.NoError(t, err)
+	require.NoError(t, os.Symlink(indicesPath, filepath.Join(configFolder, indicesFolder)))
+
+	clusterConfig := fmt.Sprintf(`[config]
+    url             = %q
+    enabled-indices = %s
+    [config.policies]
+        enable = true
+        indices-with-policy = %s
+`, clusterURL, enabledIndices, enabledIndices)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(configFolder, configFileName), []byte(clusterConfig), 0644))
+
+	return configFolder
+}
+
+func TestIndicesCreator_CreateAndRollover(t *testing.T) {
+	server := fakeelastic.NewServer()
+	defer server.Close()
+	configFolder := createConfigFolder(t, server.URL(), `["blocks", "transactions"]`)
+
+	err := newApp().Run([]string{"indices-creator", "--config-path", configFolder})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"blocks-000001", "transactions-000001"}, server.Indices())
+	for _, index := range []string{"blocks", "transactions"} {
+		require.NotEmpty(t, server.IndexTemplate(index))
+		require.True(t, gjson.Get(server.Policy(indices.PolicyName(index)), "phases").Exists())
+		require.Equal(t, index+"-000001", server.WriteIndex(index))
+	}
+	require.JSONEq(t, gjson.Get(server.IndexTemplate("blocks"), "template.mappings").Raw, server.Mapping("blocks-000001"))
+
+	// a second run does not create anything new
+	err = newApp().Run([]string{"indices-creator", "--config-path", configFolder})
+	require.NoError(t, err)
+	require.Equal(t, []string{"blocks-000001", "transactions-000001"}, server.Indices())
+
+	err = newApp().Run([]string{"indices-creator", "rollover", "--config-path", configFolder, "--indices", "blocks"})
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"blocks-000001", "blocks-000002", "transactions-000001"}, server.Indices())
+	require.Equal(t, "blocks-000002", server.WriteIndex("blocks"))
+	require.Equal(t, server.Mapping("blocks-000001"), server.Mapping("blocks-000002"))
+}
+
+func TestIndicesCreator_PlanWithoutChanges(t *testing.T) {
+	server := fakeelastic.NewServer()
+	defer server.Close()
+	configFolder := createConfigFolder(t, server.URL(), `["blocks"]`)
+
+	err := newApp().Run([]string{"indices-creator", "--config-path", configFolder})
+	require.NoError(t, err)
+
+	planFile := filepath.Join(t.TempDir(), "plan.json")
+	err = newApp().Run([]string{"indices-creator", "--config-path", configFolder, "--plan", "--plan-file", planFile})
+	require.NoError(t, err)
+
+	plan, err := ioutil.ReadFile(planFile)
+	require.NoError(t, err)
+	require.Equal(t, int64(3), gjson.GetBytes(plan, "changes.#").Int(), string(plan))
+	for _, action := range gjson.GetBytes(plan, "changes.#.action").Array() {
+		require.Equal(t, indices.ActionNone, action.String(), string(plan))
+	}
+}
+
+type backendStub string
+
+func (bs backendStub) Backend() string {
+	return string(bs)
+}
+
+func TestLoadPoliciesIfEnabled_OpenSearch(t *testing.T) {
+	indicesPath := t.TempDir()
+	policiesPath := filepath.Join(indicesPath, policiesFolder)
+	openSearchPoliciesPath := filepath.Join(indicesPath, openSearchPoliciesFolder)
+	require.NoError(t, os.MkdirAll(policiesPath, 0755))
+	require.NoError(t, os.MkdirAll(openSearchPoliciesPath, 0755))
+
+	lifecyclePolicy := `{"policy":{"phases":{"hot":{"actions":{"rollover":{"max_size":"50gb"}}}}}}`
+	for _, index := range []string{"blocks", "transactions"} {
+		require.NoError(t, ioutil.WriteFile(filepath.Join(policiesPath, index+".json"), []byte(lifecyclePolicy), 0644))
+	}
+	override := `{"policy":{"description":"override","states":[]}}`
+	require.NoError(t, ioutil.WriteFile(filepath.Join(openSearchPoliciesPath, "blocks.json"), []byte(override), 0644))
+
+	cfg := &Cfg{}
+	cfg.ClusterConfig.Policies.Enable = true
+	cfg.ClusterConfig.Policies.IndicesWithPolicy = []string{"blocks", "transactions"}
+
+	policies, err := loadPoliciesIfEnabled(backendStub(elastic.BackendOpenSearch), cfg, indicesPath)
+	require.NoError(t, err)
+	require.Equal(t, override, policies["blocks"].String())
+	require.True(t, gjson.Get(policies["transactions"].String(), "policy.states").Exists())
+
+	// an override that cannot be read is reported instead of being replaced by the converted policy
+	require.NoError(t, os.Mkdir(filepath.Join(openSearchPoliciesPath, "transactions.json"), 0755))
+	_, err = loadPoliciesIfEnabled(backendStub(elastic.BackendOpenSearch), cfg, indicesPath)
+	require.Error(t, err)
+}
@@ -7,25 +7,147 @@ type GeneralConfig struct {
 
 // IndexersConfig holds the configuration related to indexers
 type IndexersConfig struct {
-	Input         ElasticInstanceConfig `toml:"input"`
-	Output        ElasticInstanceConfig `toml:"output"`
-	IndicesConfig IndicesConfig         `toml:"indices"`
+	Input  ElasticInstanceConfig `toml:"input"`
+	Output ElasticInstanceConfig `toml:"output"`
+	// Outputs are used instead of Output, if set, every page read from the input being written in all of them
+	Outputs       []ElasticInstanceConfig      `toml:"outputs"`
+	IndicesConfig IndicesConfig                `toml:"indices"`
+	Reader        ReaderConfig                 `toml:"reader"`
+	Verify        VerifyConfig                 `toml:"verify"`
+	Follow        FollowConfig                 `toml:"follow"`
+	Transforms    map[string][]TransformConfig `toml:"transforms"`
+	Metrics       MetricsConfig                `toml:"metrics"`
+	MappingCheck  MappingCheckConfig           `toml:"mapping-check"`
+}
+
+// MappingCheckConfig holds the configuration of the comparison between the source and the destination mappings,
+// done before any data is copied
+type MappingCheckConfig struct {
+	Enabled bool `toml:"enabled"`
+}
+
+// MetricsConfig holds the configuration related to the progress and throughput metrics
+type MetricsConfig struct {
+	ListenAddress            string `toml:"listen-address"`
+	SummaryIntervalInSeconds int    `toml:"summary-interval-in-seconds"`
+}
+
+// TransformConfig holds the configuration of a step from the transformation chain of an index
+type TransformConfig struct {
+	Type     string      `toml:"type"`
+	Field    string      `toml:"field"`
+	NewField string      `toml:"new-field"`
+	Value    interface{} `toml:"value"`
+	Exclude  bool        `toml:"exclude"`
+	Template string      `toml:"template"`
+}
+
+// FollowConfig holds the configuration related to keeping the destination cluster in sync after the initial copy
+type FollowConfig struct {
+	PollIntervalInSeconds   int `toml:"poll-interval-in-seconds"`
+	OverlapInSeconds        int `toml:"overlap-in-seconds"`
+	ResyncIntervalInMinutes int `toml:"resync-interval-in-minutes"`
+}
+
+// VerifyConfig holds the configuration related to the verification of the copied data
+type VerifyConfig struct {
+	NumIntervals int               `toml:"num-intervals"`
+	SampleSize   int               `toml:"sample-size"`
+	BucketFields map[string]string `toml:"bucket-fields"`
+}
+
+// ReaderConfig holds the configuration related to the way the documents are read from the input cluster
+type ReaderConfig struct {
+	Mode       string                  `toml:"mode"`
+	PageSize   int                     `toml:"page-size"`
+	ServerSide ServerSideReindexConfig `toml:"server-side"`
+}
+
+// ServerSideReindexConfig holds the configuration of the reindex mode, where the output cluster copies the documents
+// itself with _reindex tasks
+type ServerSideReindexConfig struct {
+	// Remote makes the output cluster pull the documents from the input cluster, which has to be in the
+	// reindex.remote.whitelist of the output cluster. Otherwise, both instances are the same cluster
+	Remote bool `toml:"remote"`
+	// Slices is the number of slices of a task, "auto" by default. A reindex from a remote cluster is not sliced
+	Slices                string `toml:"slices"`
+	PollIntervalInSeconds int    `toml:"poll-interval-in-seconds"`
 }
 
 // ElasticInstanceConfig holds the configuration needed for connecting to an Elasticsearch instance
 type ElasticInstanceConfig struct {
-	URL      string `toml:"url"`
-	Username string `toml:"username"`
-	Password string `toml:"password"`
+	// Name identifies an output in the logs and in the names of its checkpoint and verification report files
+	Name string `toml:"name"`
+	Type string `toml:"type"`
+	URL  string `toml:"url"`
+	// Username, Password, APIKey and BearerToken are read from an environment variable if they are "env:NAME", or
+	// from a file if they are "file:PATH". Only one authentication method can be used
+	Username    string          `toml:"username"`
+	Password    string          `toml:"password"`
+	APIKey      string          `toml:"api-key"`
+	BearerToken string          `toml:"bearer-token"`
+	TLS         TLSConfig       `toml:"tls"`
+	File        FileConfig      `toml:"file"`
+	BulkRetry   BulkRetryConfig `toml:"bulk-retry"`
+}
+
+// TLSConfig holds the TLS settings of the connection to an Elasticsearch instance
+type TLSConfig struct {
+	// CACertFile is a PEM bundle with the certificate authorities trusted besides the system ones
+	CACertFile string `toml:"ca-cert-file"`
+	// CertFile and KeyFile are the PEM client certificate and key used for mutual TLS
+	CertFile string `toml:"cert-file"`
+	KeyFile  string `toml:"key-file"`
+	// InsecureSkipVerify disables the verification of the server certificate, to be used only with test clusters
+	InsecureSkipVerify bool `toml:"insecure-skip-verify"`
+}
+
+// BulkRetryConfig holds the configuration related to retrying the items of a bulk request that were rejected
+type BulkRetryConfig struct {
+	MaxRetries          int    `toml:"max-retries"`
+	InitialBackoffInMs  int    `toml:"initial-backoff-in-ms"`
+	MaxBackoffInSeconds int    `toml:"max-backoff-in-seconds"`
+	DeadLetterFile      string `toml:"dead-letter-file"`
+}
+
+// FileConfig holds the configuration needed when the instance is a local directory with NDJSON files instead of
+// an Elasticsearch cluster
+type FileConfig struct {
+	Path          string `toml:"path"`
+	Compress      bool   `toml:"compress"`
+	ChunkSizeInMB int    `toml:"chunk-size-in-mb"`
+	PageSize      int    `toml:"page-size"`
 }
 
 // IndicesConfig holds the configuration for the indices
 type IndicesConfig struct {
-	Indices       []string `toml:"indices-no-timestamp"`
+	Indices       []string                       `toml:"indices-no-timestamp"`
+	NumSlices     map[string]int                 `toml:"num-slices"`
+	Overrides     map[string]IndexOverrideConfig `toml:"overrides"`
 	WithTimestamp struct {
 		Enabled              bool     `toml:"enabled"`
 		BlockchainStartTime  int64    `toml:"blockchain-start-time"`
 		NumParallelWrites    int      `toml:"num-parallel-writes"`
 		IndicesWithTimestamp []string `toml:"indices-with-timestamp"`
+		// BalancedIntervals splits the time range by the number of documents instead of in equal time slices
+		BalancedIntervals bool `toml:"balanced-intervals"`
+		// TargetDocsPerInterval is the number of documents of a balanced interval. If 0, the number of documents is
+		// divided by num-parallel-writes
+		TargetDocsPerInterval uint64 `toml:"target-docs-per-interval"`
 	} `toml:"with-timestamp"`
 }
+
+// IndexOverrideConfig holds the settings of an index that override the general ones
+type IndexOverrideConfig struct {
+	// Query is a JSON query clause applied on the source documents, e.g. {"term":{"shardID":1}}
+	Query string `toml:"query"`
+	// TargetIndex is the alias used in the destination instead of the source index name
+	TargetIndex string `toml:"target-index"`
+	// TargetPrefix is prepended to the source index name when TargetIndex is not set
+	TargetPrefix      string `toml:"target-prefix"`
+	BulkSizeInBytes   int    `toml:"bulk-size-in-bytes"`
+	PageSize          int    `toml:"page-size"`
+	NumParallelWrites int    `toml:"num-parallel-writes"`
+	StartTime         int64  `toml:"start-time"`
+	EndTime           int64  `toml:"end-time"`
+}
@@ -0,0 +1,350 @@
+package deduplicator
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// KeepNewer keeps the copy from the newer index, the duplicates being deleted from the older index
+	KeepNewer = "newer"
+	// KeepOlder keeps the copy from the older index, the duplicates being deleted from the newer index
+	KeepOlder = "older"
+	// KeepGreaterField keeps the copy with the greater value of the configured field. On equal values, the copy from
+	// the newer index is kept
+	KeepGreaterField = "greater-field"
+
+	defaultPageSize = 1000
+	// maxPageSize is the default max_result_window of an index, as the ids of a page are searched in the newer index
+	// with a single request
+	maxPageSize = 10000
+)
+
+var (
+	log = logger.GetOrCreate("deduplicator")
+
+	errNilElasticHandler = errors.New("nil elastic handler")
+	errInvalidKeepRule   = errors.New("invalid keep rule")
+)
+
+// ArgsDeduplicator holds the arguments needed for creating a new deduplicator
+type ArgsDeduplicator struct {
+	Client ElasticClientHandler
+	// KeepRule decides which copy of a duplicated document is kept: newer, older or greater-field
+	KeepRule string
+	// KeepField is the field compared by the greater-field rule, e.g. timestamp
+	KeepField string
+	PageSize  int
+	// Delete enables the deletion of the duplicated documents. If not set, the duplicates are only reported
+	Delete bool
+}
+
+type deduplicator struct {
+	client    ElasticClientHandler
+	keepRule  string
+	keepField string
+	pageSize  int
+	delete    bool
+}
+
+type document struct {
+	id        string
+	keepValue gjson.Result
+}
+
+// NewDeduplicator will create a new instance of a component that finds the documents having the same _id in the
+// consecutive indices behind an alias
+func NewDeduplicator(args ArgsDeduplicator) (*deduplicator, error) {
+	if check.IfNil(args.Client) {
+		return nil, errNilElasticHandler
+	}
+
+	switch args.KeepRule {
+	case "":
+		args.KeepRule = KeepNewer
+	case KeepNewer, KeepOlder:
+	case KeepGreaterField:
+		if args.KeepField == "" {
+			return nil, fmt.Errorf("%w: %s needs a keep field", errInvalidKeepRule, KeepGreaterField)
+		}
+	default:
+		return nil, fmt.Errorf("%w: %s", errInvalidKeepRule, args.KeepRule)
+	}
+
+	pageSize := args.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	if pageSize > maxPageSize {
+		return nil, fmt.Errorf("the page size cannot be greater than %d", maxPageSize)
+	}
+
+	return &deduplicator{
+		client:    args.Client,
+		keepRule:  args.KeepRule,
+		keepField: args.KeepField,
+		pageSize:  pageSize,
+		delete:    args.Delete,
+	}, nil
+}
+
+// Process compares every two consecutive indices behind the provided aliases, ordered by name, and returns the report
+// of the duplicated documents. All the documents of the older index are checked against the newer one
+func (d *deduplicator) Process(aliases ...string) (*Report, error) {
+	report := &Report{
+		Deleted: d.delete,
+		Aliases: make([]*AliasReport, 0, len(aliases)),
+	}
+
+	for _, alias := range aliases {
+		if alias == "" {
+			continue
+		}
+
+		aliasReport, err := d.processAlias(alias)
+		if err != nil {
+			return nil, fmt.Errorf("%w while processing alias %s", err, alias)
+		}
+		report.Aliases = append(report.Aliases, aliasReport)
+	}
+
+	return report, nil
+}
+
+func (d *deduplicator) processAlias(alias string) (*AliasReport, error) {
+	indices, err := d.getIndicesBehindAlias(alias)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("checking alias", "alias", alias, "indices", indices)
+
+	aliasReport := &AliasReport{
+		Alias:   alias,
+		Indices: indices,
+	}
+	for idx := 0; idx < len(indices)-1; idx++ {
+		pairReport, errCompare := d.compareIndices(indices[idx], indices[idx+1])
+		if errCompare != nil {
+			return nil, errCompare
+		}
+		aliasReport.addPair(pairReport)
+	}
+
+	return aliasReport, nil
+}
+
+func (d *deduplicator) getIndicesBehindAlias(alias string) ([]string, error) {
+	aliasesBody, err := d.client.GetAliases(alias)
+	if err != nil {
+		return nil, err
+	}
+	if aliasesBody == nil {
+		return nil, errors.New("the alias does not exist")
+	}
+
+	indices := make([]string, 0)
+	for index := range gjson.ParseBytes(aliasesBody).Map() {
+		indices = append(indices, index)
+	}
+	// the generations created by the rollover have increasing suffixes, e.g. blocks-000001, blocks-000002
+	sort.Strings(indices)
+
+	return indices, nil
+}
+
+func (d *deduplicator) compareIndices(olderIndex string, newerIndex string) (*PairReport, error) {
+	pairReport := &PairReport{
+		OlderIndex: olderIndex,
+		NewerIndex: newerIndex,
+	}
+
+	// the point in time keeps the older index unchanged while its duplicates are deleted
+	err := d.client.DoPitRequestAllDocuments(olderIndex, d.allDocumentsQuery(), d.pageSize, func(responseBytes []byte) error {
+		return d.handlePage(pairReport, responseBytes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("compared indices", "older", olderIndex, "newer", newerIndex,
+		"num checked", pairReport.NumChecked, "num duplicates", pairReport.NumDuplicates)
+
+	return pairReport, nil
+}
+
+func (d *deduplicator) handlePage(pairReport *PairReport, responseBytes []byte) error {
+	olderDocs := d.getDocuments(responseBytes)
+	pairReport.NumChecked += uint64(len(olderDocs))
+	if len(olderDocs) == 0 {
+		return nil
+	}
+
+	ids := make([]string, 0, len(olderDocs))
+	for _, doc := range olderDocs {
+		ids = append(ids, doc.id)
+	}
+	newerResponse, err := d.client.DoSearchRequest(pairReport.NewerIndex, d.idsQuery(ids))
+	if err != nil {
+		return err
+	}
+
+	newerDocs := make(map[string]*document)
+	for _, doc := range d.getDocuments(newerResponse) {
+		newerDocs[doc.id] = doc
+	}
+
+	deleteFromOlder := make([]string, 0)
+	deleteFromNewer := make([]string, 0)
+	for _, olderDoc := range olderDocs {
+		newerDoc, found := newerDocs[olderDoc.id]
+		if !found {
+			continue
+		}
+
+		pairReport.addDuplicate(olderDoc.id)
+		if d.shouldKeepOlder(olderDoc, newerDoc) {
+			deleteFromNewer = append(deleteFromNewer, olderDoc.id)
+			continue
+		}
+		deleteFromOlder = append(deleteFromOlder, olderDoc.id)
+	}
+
+	if !d.delete {
+		return nil
+	}
+
+	err = d.deleteDocuments(pairReport.OlderIndex, deleteFromOlder)
+	if err != nil {
+		return err
+	}
+	pairReport.NumDeletedFromOlder += uint64(len(deleteFromOlder))
+
+	err = d.deleteDocuments(pairReport.NewerIndex, deleteFromNewer)
+	if err != nil {
+		return err
+	}
+	pairReport.NumDeletedFromNewer += uint64(len(deleteFromNewer))
+
+	return nil
+}
+
+func (d *deduplicator) shouldKeepOlder(olderDoc *document, newerDoc *document) bool {
+	switch d.keepRule {
+	case KeepOlder:
+		return true
+	case KeepGreaterField:
+		return isGreater(olderDoc.keepValue, newerDoc.keepValue)
+	default:
+		return false
+	}
+}
+
+// isGreater compares the values numerically if both of them are numbers, or as strings otherwise. A missing value is
+// lower than any other value
+func isGreater(value gjson.Result, other gjson.Result) bool {
+	if !value.Exists() {
+		return false
+	}
+	if !other.Exists() {
+		return true
+	}
+	if value.Type == gjson.Number && other.Type == gjson.Number {
+		return value.Float() > other.Float()
+	}
+
+	return value.String() > other.String()
+}
+
+func (d *deduplicator) getDocuments(responseBytes []byte) []*document {
+	hits := gjson.GetBytes(responseBytes, "hits.hits").Array()
+	docs := make([]*document, 0, len(hits))
+	for _, hit := range hits {
+		doc := &document{
+			id: hit.Get("_id").String(),
+		}
+		if d.keepRule == KeepGreaterField {
+			doc.keepValue = hit.Get("_source").Get(d.keepField)
+		}
+		docs = append(docs, doc)
+	}
+
+	return docs
+}
+
+func (d *deduplicator) deleteDocuments(index string, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	buff := &bytes.Buffer{}
+	for _, id := range ids {
+		meta, err := json.Marshal(map[string]interface{}{
+			"delete": map[string]string{
+				"_index": index,
+				"_id":    id,
+			},
+		})
+		if err != nil {
+			return err
+		}
+		buff.Write(meta)
+		buff.WriteByte('\n')
+	}
+
+	log.Debug("deleting duplicates", "index", index, "num documents", len(ids))
+
+	return d.client.DoBulkRequest(buff, index)
+}
+
+func (d *deduplicator) sourceFilter() interface{} {
+	if d.keepRule == KeepGreaterField {
+		return []string{d.keepField}
+	}
+
+	return false
+}
+
+func (d *deduplicator) allDocumentsQuery() []byte {
+	query, _ := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"match_all": map[string]interface{}{},
+		},
+		"_source": d.sourceFilter(),
+		"sort": []interface{}{
+			map[string]interface{}{
+				"_shard_doc": map[string]interface{}{
+					"order": "asc",
+				},
+			},
+		},
+	})
+
+	return query
+}
+
+func (d *deduplicator) idsQuery(ids []string) []byte {
+	query, _ := json.Marshal(map[string]interface{}{
+		"query": map[string]interface{}{
+			"ids": map[string]interface{}{
+				"values": ids,
+			},
+		},
+		"_source": d.sourceFilter(),
+		"size":    len(ids),
+	})
+
+	return query
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (d *deduplicator) IsInterfaceNil() bool {
+	return d == nil
+}
@@ -0,0 +1,141 @@
+package deduplicator
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/deduplicator/mock"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const aliasesResponse = `{"blocks-000002":{"aliases":{"blocks":{"is_write_index":true}}},"blocks-000001":{"aliases":{"blocks":{"is_write_index":false}}}}`
+
+func createHits(docs map[string]int) []byte {
+	hits := make([]string, 0, len(docs))
+	for id, timestamp := range docs {
+		hits = append(hits, fmt.Sprintf(`{"_id":"%s","_source":{"timestamp":%d}}`, id, timestamp))
+	}
+
+	return []byte(fmt.Sprintf(`{"hits":{"hits":[%s]}}`, strings.Join(hits, ",")))
+}
+
+func createClientStub(olderDocs map[string]int, newerDocs map[string]int, deleted map[string][]string) *mock.ElasticClientStub {
+	return &mock.ElasticClientStub{
+		GetAliasesCalled: func(alias string) ([]byte, error) {
+			return []byte(aliasesResponse), nil
+		},
+		DoPitRequestAllDocumentsCalled: func(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error {
+			if index != "blocks-000001" {
+				return errors.New("unexpected index " + index)
+			}
+			return handlerFunc(createHits(olderDocs))
+		},
+		DoSearchRequestCalled: func(index string, body []byte) ([]byte, error) {
+			found := make(map[string]int)
+			for _, id := range gjson.GetBytes(body, "query.ids.values").Array() {
+				timestamp, ok := newerDocs[id.String()]
+				if ok {
+					found[id.String()] = timestamp
+				}
+			}
+			return createHits(found), nil
+		},
+		DoBulkRequestCalled: func(buff *bytes.Buffer, index string) error {
+			for _, line := range strings.Split(strings.TrimSpace(buff.String()), "\n") {
+				deleted[index] = append(deleted[index], gjson.Get(line, "delete._id").String())
+			}
+			return nil
+		},
+	}
+}
+
+func TestNewDeduplicator(t *testing.T) {
+	t.Parallel()
+
+	d, err := NewDeduplicator(ArgsDeduplicator{})
+	require.Nil(t, d)
+	require.Equal(t, errNilElasticHandler, err)
+
+	d, err = NewDeduplicator(ArgsDeduplicator{Client: &mock.ElasticClientStub{}, KeepRule: "random"})
+	require.Nil(t, d)
+	require.True(t, errors.Is(err, errInvalidKeepRule))
+
+	d, err = NewDeduplicator(ArgsDeduplicator{Client: &mock.ElasticClientStub{}, KeepRule: KeepGreaterField})
+	require.Nil(t, d)
+	require.True(t, errors.Is(err, errInvalidKeepRule))
+
+	d, err = NewDeduplicator(ArgsDeduplicator{Client: &mock.ElasticClientStub{}, PageSize: maxPageSize + 1})
+	require.Nil(t, d)
+	require.Error(t, err)
+
+	d, err = NewDeduplicator(ArgsDeduplicator{Client: &mock.ElasticClientStub{}})
+	require.NoError(t, err)
+	require.Equal(t, KeepNewer, d.keepRule)
+	require.Equal(t, defaultPageSize, d.pageSize)
+}
+
+func TestDeduplicator_ProcessOnlyReports(t *testing.T) {
+	t.Parallel()
+
+	deleted := make(map[string][]string)
+	client := createClientStub(map[string]int{"a": 1, "b": 2, "c": 3}, map[string]int{"c": 3, "d": 4}, deleted)
+	d, _ := NewDeduplicator(ArgsDeduplicator{Client: client})
+
+	report, err := d.Process("blocks")
+	require.NoError(t, err)
+	require.False(t, report.Deleted)
+	require.Len(t, report.Aliases, 1)
+	require.Equal(t, []string{"blocks-000001", "blocks-000002"}, report.Aliases[0].Indices)
+	require.Equal(t, uint64(1), report.Aliases[0].NumDuplicates)
+
+	pair := report.Aliases[0].Pairs[0]
+	require.Equal(t, uint64(3), pair.NumChecked)
+	require.Equal(t, []string{"c"}, pair.SampleIDs)
+	require.Equal(t, uint64(0), pair.NumDeletedFromOlder)
+	require.Empty(t, deleted)
+}
+
+func TestDeduplicator_ProcessDeleteKeepNewer(t *testing.T) {
+	t.Parallel()
+
+	deleted := make(map[string][]string)
+	client := createClientStub(map[string]int{"a": 1, "b": 2}, map[string]int{"a": 1, "b": 2}, deleted)
+	d, _ := NewDeduplicator(ArgsDeduplicator{Client: client, Delete: true})
+
+	report, err := d.Process("blocks")
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), report.Aliases[0].Pairs[0].NumDeletedFromOlder)
+	require.ElementsMatch(t, []string{"a", "b"}, deleted["blocks-000001"])
+	require.Empty(t, deleted["blocks-000002"])
+}
+
+func TestDeduplicator_ProcessDeleteKeepGreaterField(t *testing.T) {
+	t.Parallel()
+
+	deleted := make(map[string][]string)
+	client := createClientStub(map[string]int{"a": 5, "b": 2, "c": 3}, map[string]int{"a": 1, "b": 2, "c": 4}, deleted)
+	d, _ := NewDeduplicator(ArgsDeduplicator{Client: client, Delete: true, KeepRule: KeepGreaterField, KeepField: "timestamp"})
+
+	report, err := d.Process("blocks")
+	require.NoError(t, err)
+	pair := report.Aliases[0].Pairs[0]
+	require.Equal(t, uint64(3), pair.NumDuplicates)
+	require.Equal(t, uint64(2), pair.NumDeletedFromOlder)
+	require.Equal(t, uint64(1), pair.NumDeletedFromNewer)
+	require.ElementsMatch(t, []string{"b", "c"}, deleted["blocks-000001"])
+	require.Equal(t, []string{"a"}, deleted["blocks-000002"])
+}
+
+func TestDeduplicator_ProcessMissingAliasShouldErr(t *testing.T) {
+	t.Parallel()
+
+	d, _ := NewDeduplicator(ArgsDeduplicator{Client: &mock.ElasticClientStub{}})
+
+	report, err := d.Process("blocks")
+	require.Nil(t, report)
+	require.Error(t, err)
+}
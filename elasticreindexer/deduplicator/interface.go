@@ -0,0 +1,17 @@
+package deduplicator
+
+import "bytes"
+
+// ElasticClientHandler defines the behaviour of a client that reads the indices behind an alias and deletes documents
+type ElasticClientHandler interface {
+	GetAliases(alias string) ([]byte, error)
+	DoPitRequestAllDocuments(
+		index string,
+		body []byte,
+		pageSize int,
+		handlerFunc func(responseBytes []byte) error,
+	) error
+	DoSearchRequest(index string, body []byte) ([]byte, error)
+	DoBulkRequest(buff *bytes.Buffer, index string) error
+	IsInterfaceNil() bool
+}
@@ -0,0 +1,52 @@
+package mock
+
+import "bytes"
+
+// ElasticClientStub -
+type ElasticClientStub struct {
+	GetAliasesCalled               func(alias string) ([]byte, error)
+	DoPitRequestAllDocumentsCalled func(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error
+	DoSearchRequestCalled          func(index string, body []byte) ([]byte, error)
+	DoBulkRequestCalled            func(buff *bytes.Buffer, index string) error
+}
+
+// GetAliases -
+func (e *ElasticClientStub) GetAliases(alias string) ([]byte, error) {
+	if e.GetAliasesCalled != nil {
+		return e.GetAliasesCalled(alias)
+	}
+
+	return nil, nil
+}
+
+// DoPitRequestAllDocuments -
+func (e *ElasticClientStub) DoPitRequestAllDocuments(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error {
+	if e.DoPitRequestAllDocumentsCalled != nil {
+		return e.DoPitRequestAllDocumentsCalled(index, body, pageSize, handlerFunc)
+	}
+
+	return nil
+}
+
+// DoSearchRequest -
+func (e *ElasticClientStub) DoSearchRequest(index string, body []byte) ([]byte, error) {
+	if e.DoSearchRequestCalled != nil {
+		return e.DoSearchRequestCalled(index, body)
+	}
+
+	return nil, nil
+}
+
+// DoBulkRequest -
+func (e *ElasticClientStub) DoBulkRequest(buff *bytes.Buffer, index string) error {
+	if e.DoBulkRequestCalled != nil {
+		return e.DoBulkRequestCalled(buff, index)
+	}
+
+	return nil
+}
+
+// IsInterfaceNil -
+func (e *ElasticClientStub) IsInterfaceNil() bool {
+	return e == nil
+}
@@ -0,0 +1,55 @@
+package deduplicator
+
+import (
+	"encoding/json"
+	"os"
+)
+
+const maxSampleIDs = 100
+
+// Report holds the duplicated documents found behind every alias
+type Report struct {
+	Deleted bool           `json:"deleted"`
+	Aliases []*AliasReport `json:"aliases"`
+}
+
+// AliasReport holds the duplicated documents found between the consecutive indices behind an alias
+type AliasReport struct {
+	Alias         string        `json:"alias"`
+	Indices       []string      `json:"indices"`
+	NumDuplicates uint64        `json:"numDuplicates"`
+	Pairs         []*PairReport `json:"pairs,omitempty"`
+}
+
+// PairReport holds the documents that exist in two consecutive indices behind an alias
+type PairReport struct {
+	OlderIndex          string   `json:"olderIndex"`
+	NewerIndex          string   `json:"newerIndex"`
+	NumChecked          uint64   `json:"numChecked"`
+	NumDuplicates       uint64   `json:"numDuplicates"`
+	NumDeletedFromOlder uint64   `json:"numDeletedFromOlder"`
+	NumDeletedFromNewer uint64   `json:"numDeletedFromNewer"`
+	SampleIDs           []string `json:"sampleIDs,omitempty"`
+}
+
+func (ar *AliasReport) addPair(pairReport *PairReport) {
+	ar.Pairs = append(ar.Pairs, pairReport)
+	ar.NumDuplicates += pairReport.NumDuplicates
+}
+
+func (pr *PairReport) addDuplicate(id string) {
+	pr.NumDuplicates++
+	if len(pr.SampleIDs) < maxSampleIDs {
+		pr.SampleIDs = append(pr.SampleIDs, id)
+	}
+}
+
+// SaveToFile will write the report as JSON in the provided file
+func (r *Report) SaveToFile(filePath string) error {
+	reportBytes, err := json.MarshalIndent(r, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, reportBytes, 0644)
+}
@@ -0,0 +1,144 @@
+package elastic
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+)
+
+const (
+	// envSecretPrefix marks a credential read from the environment variable with the name following the prefix
+	envSecretPrefix = "env:"
+	// fileSecretPrefix marks a credential read from the file with the path following the prefix
+	fileSecretPrefix = "file:"
+)
+
+// credentials holds the resolved authentication settings of an Elasticsearch instance
+type credentials struct {
+	username    string
+	password    string
+	apiKey      string
+	bearerToken string
+}
+
+// ResolveSecret returns the provided value or, if it is "env:NAME", the value of the NAME environment variable, or if
+// it is "file:PATH", the content of the file from PATH without the trailing new lines
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, envSecretPrefix):
+		name := strings.TrimPrefix(value, envSecretPrefix)
+		secret, found := os.LookupEnv(name)
+		if !found {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+
+		return secret, nil
+	case strings.HasPrefix(value, fileSecretPrefix):
+		filePath := strings.TrimPrefix(value, fileSecretPrefix)
+		secret, err := os.ReadFile(filePath)
+		if err != nil {
+			return "", fmt.Errorf("%w while reading the secret file %s", err, filePath)
+		}
+
+		return strings.TrimRight(string(secret), "\r\n"), nil
+	default:
+		return value, nil
+	}
+}
+
+func resolveCredentials(cfg config.ElasticInstanceConfig) (credentials, error) {
+	var creds credentials
+	secrets := []struct {
+		name  string
+		value string
+		dest  *string
+	}{
+		{name: "username", value: cfg.Username, dest: &creds.username},
+		{name: "password", value: cfg.Password, dest: &creds.password},
+		{name: "api-key", value: cfg.APIKey, dest: &creds.apiKey},
+		{name: "bearer-token", value: cfg.BearerToken, dest: &creds.bearerToken},
+	}
+	for _, secret := range secrets {
+		resolved, err := ResolveSecret(secret.value)
+		if err != nil {
+			return credentials{}, fmt.Errorf("%w for %s", err, secret.name)
+		}
+
+		*secret.dest = resolved
+	}
+
+	numMethods := 0
+	for _, isSet := range []bool{creds.username != "", creds.apiKey != "", creds.bearerToken != ""} {
+		if isSet {
+			numMethods++
+		}
+	}
+	if numMethods > 1 {
+		return credentials{}, errors.New("only one of username/password, api-key and bearer-token can be set")
+	}
+
+	return creds, nil
+}
+
+// authorizationHeader returns the header of the bearer token authentication, which is not built in the client
+func (creds credentials) authorizationHeader() http.Header {
+	if creds.bearerToken == "" {
+		return nil
+	}
+
+	return http.Header{"Authorization": []string{"Bearer " + creds.bearerToken}}
+}
+
+// createTransport returns an HTTP transport with the provided TLS settings, or nil if none is set, case when the
+// default transport is used
+func createTransport(cfg config.TLSConfig) (http.RoundTripper, error) {
+	if cfg.CACertFile == "" && cfg.CertFile == "" && cfg.KeyFile == "" && !cfg.InsecureSkipVerify {
+		return nil, nil
+	}
+
+	tlsConfig := &tls.Config{
+		InsecureSkipVerify: cfg.InsecureSkipVerify,
+	}
+	if cfg.InsecureSkipVerify {
+		log.Warn("the certificate of the Elasticsearch instance is not verified, use insecure-skip-verify only with test clusters")
+	}
+
+	if cfg.CACertFile != "" {
+		caCert, err := os.ReadFile(cfg.CACertFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading the CA bundle %s", err, cfg.CACertFile)
+		}
+
+		rootCAs, err := x509.SystemCertPool()
+		if err != nil || rootCAs == nil {
+			rootCAs = x509.NewCertPool()
+		}
+		if !rootCAs.AppendCertsFromPEM(caCert) {
+			return nil, fmt.Errorf("no certificate found in the CA bundle %s", cfg.CACertFile)
+		}
+		tlsConfig.RootCAs = rootCAs
+	}
+
+	if cfg.CertFile != "" || cfg.KeyFile != "" {
+		if cfg.CertFile == "" || cfg.KeyFile == "" {
+			return nil, errors.New("both cert-file and key-file should be set for the client certificate")
+		}
+
+		clientCert, err := tls.LoadX509KeyPair(cfg.CertFile, cfg.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("%w while loading the client certificate", err)
+		}
+		tlsConfig.Certificates = []tls.Certificate{clientCert}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = tlsConfig
+
+	return transport, nil
+}
@@ -0,0 +1,113 @@
+package elastic
+
+import (
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveSecret(t *testing.T) {
+	t.Setenv("ELASTIC_TEST_PASSWORD", "from-env")
+	secretFile := filepath.Join(t.TempDir(), "password")
+	require.NoError(t, os.WriteFile(secretFile, []byte("from-file\n"), 0600))
+
+	secret, err := ResolveSecret("plain")
+	require.NoError(t, err)
+	require.Equal(t, "plain", secret)
+
+	secret, err = ResolveSecret("env:ELASTIC_TEST_PASSWORD")
+	require.NoError(t, err)
+	require.Equal(t, "from-env", secret)
+
+	secret, err = ResolveSecret("file:" + secretFile)
+	require.NoError(t, err)
+	require.Equal(t, "from-file", secret)
+
+	_, err = ResolveSecret("env:ELASTIC_TEST_MISSING")
+	require.Error(t, err)
+	_, err = ResolveSecret("file:" + filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
+
+func TestResolveCredentials_MoreMethodsShouldErr(t *testing.T) {
+	t.Parallel()
+
+	_, err := resolveCredentials(config.ElasticInstanceConfig{Username: "user", APIKey: "key"})
+	require.Error(t, err)
+
+	creds, err := resolveCredentials(config.ElasticInstanceConfig{BearerToken: "token"})
+	require.NoError(t, err)
+	require.Equal(t, "Bearer token", creds.authorizationHeader().Get("Authorization"))
+}
+
+func TestNewElasticClient_AuthenticationAndTLS(t *testing.T) {
+	t.Parallel()
+
+	createServer := func(t *testing.T, expectedAuthorization string) *httptest.Server {
+		server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != expectedAuthorization {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path == "/" {
+				_, _ = w.Write([]byte(`{"version":{"number":"7.17.0"}}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"count":3}`))
+		}))
+		t.Cleanup(server.Close)
+
+		return server
+	}
+
+	t.Run("api key and CA bundle", func(t *testing.T) {
+		t.Parallel()
+
+		server := createServer(t, "APIKey a2V5")
+		caFile := filepath.Join(t.TempDir(), "ca.pem")
+		caPem := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})
+		require.NoError(t, os.WriteFile(caFile, caPem, 0600))
+
+		client, err := NewElasticClient(config.ElasticInstanceConfig{
+			URL:    server.URL,
+			APIKey: "a2V5",
+			TLS:    config.TLSConfig{CACertFile: caFile},
+		})
+		require.NoError(t, err)
+
+		count, err := client.GetCount("blocks")
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), count)
+	})
+	t.Run("bearer token and insecure skip verify", func(t *testing.T) {
+		t.Parallel()
+
+		server := createServer(t, "Bearer token")
+		client, err := NewElasticClient(config.ElasticInstanceConfig{
+			URL:         server.URL,
+			BearerToken: "token",
+			TLS:         config.TLSConfig{InsecureSkipVerify: true},
+		})
+		require.NoError(t, err)
+
+		count, err := client.GetCount("blocks")
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), count)
+	})
+	t.Run("client certificate without key should err", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewElasticClient(config.ElasticInstanceConfig{
+			URL: "https://127.0.0.1:9200",
+			TLS: config.TLSConfig{CertFile: "cert.pem"},
+		})
+		require.Error(t, err)
+	})
+}
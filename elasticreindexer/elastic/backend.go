@@ -0,0 +1,120 @@
+package elastic
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// BackendElasticsearch defines an Elasticsearch cluster
+	BackendElasticsearch = "elasticsearch"
+	// BackendOpenSearch defines an OpenSearch cluster
+	BackendOpenSearch = "opensearch"
+)
+
+// backendInfo holds the distribution and the version of the cluster
+type backendInfo struct {
+	distribution string
+	version      string
+	major        int
+	minor        int
+}
+
+func (bi backendInfo) isOpenSearch() bool {
+	return bi.distribution == BackendOpenSearch
+}
+
+// isAtLeast returns true if the version of the cluster is greater or equal than the provided one
+func (bi backendInfo) isAtLeast(major int, minor int) bool {
+	if bi.major != major {
+		return bi.major > major
+	}
+
+	return bi.minor >= minor
+}
+
+// parseBackendInfo reads the distribution and the version from the response of the root endpoint. OpenSearch sets the
+// version.distribution field, while Elasticsearch does not
+func parseBackendInfo(infoBody []byte) backendInfo {
+	info := backendInfo{
+		distribution: BackendElasticsearch,
+		version:      gjson.GetBytes(infoBody, "version.number").String(),
+	}
+	if gjson.GetBytes(infoBody, "version.distribution").String() == BackendOpenSearch {
+		info.distribution = BackendOpenSearch
+	}
+
+	versionParts := strings.Split(info.version, ".")
+	if len(versionParts) > 0 {
+		info.major, _ = strconv.Atoi(versionParts[0])
+	}
+	if len(versionParts) > 1 {
+		info.minor, _ = strconv.Atoi(versionParts[1])
+	}
+
+	return info
+}
+
+// detectBackend asks the cluster for its distribution and version. If the cluster cannot be reached, it is considered
+// an Elasticsearch cluster, as the client was used before the detection was added
+func (esc *esClient) detectBackend() {
+	esc.backend = backendInfo{
+		distribution: BackendElasticsearch,
+	}
+
+	res, err := esc.client.Info()
+	if err != nil {
+		log.Warn("cannot detect the cluster distribution, Elasticsearch is assumed", "error", err)
+		return
+	}
+
+	infoBody, err := getBytesFromResponse(res)
+	if err != nil {
+		log.Warn("cannot detect the cluster distribution, Elasticsearch is assumed", "error", err)
+		return
+	}
+
+	esc.backend = parseBackendInfo(infoBody)
+	log.Debug("esClient.detectBackend", "distribution", esc.backend.distribution, "version", esc.backend.version)
+}
+
+// Backend returns the distribution of the cluster: elasticsearch or opensearch
+func (esc *esClient) Backend() string {
+	return esc.backend.distribution
+}
+
+// doRequest performs a request on an endpoint that is not covered by the Elasticsearch client, e.g. the OpenSearch
+// plugins. It returns the status code and the body of the response
+func (esc *esClient) doRequest(method string, path string, body []byte) (int, []byte, error) {
+	req, err := http.NewRequest(method, path, bytes.NewReader(body))
+	if err != nil {
+		return 0, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	res, err := esc.client.Perform(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer func() {
+		_ = res.Body.Close()
+	}()
+
+	respBytes, err := ioutil.ReadAll(res.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	if res.StatusCode >= http.StatusBadRequest && res.StatusCode != http.StatusNotFound && res.StatusCode != http.StatusConflict {
+		return res.StatusCode, nil, fmt.Errorf("error response: [%d] %s", res.StatusCode, respBytes)
+	}
+
+	return res.StatusCode, respBytes, nil
+}
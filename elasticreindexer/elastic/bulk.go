@@ -0,0 +1,342 @@
+package elastic
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultBulkMaxRetries          = 5
+	defaultBulkInitialBackoffInMs  = 500
+	defaultBulkMaxBackoffInSeconds = 60
+
+	errRejectedExecution = "es_rejected_execution_exception"
+)
+
+// bulkItem holds an action from a bulk request: the metadata line and, except for delete, the document line
+type bulkItem struct {
+	meta   []byte
+	source []byte
+}
+
+// splitBulkItems will split a bulk request body into its items
+func splitBulkItems(bulk []byte) []*bulkItem {
+	lines := bytes.Split(bulk, []byte("\n"))
+	items := make([]*bulkItem, 0, len(lines)/2)
+	for i := 0; i < len(lines); i++ {
+		if len(bytes.TrimSpace(lines[i])) == 0 {
+			continue
+		}
+
+		item := &bulkItem{
+			meta: lines[i],
+		}
+		if !gjson.GetBytes(lines[i], "delete").Exists() && i+1 < len(lines) {
+			i++
+			item.source = lines[i]
+		}
+
+		items = append(items, item)
+	}
+
+	return items
+}
+
+func joinBulkItems(items []*bulkItem) *bytes.Buffer {
+	buff := &bytes.Buffer{}
+	for _, item := range items {
+		buff.Write(item.meta)
+		buff.WriteByte('\n')
+		if item.source != nil {
+			buff.Write(item.source)
+			buff.WriteByte('\n')
+		}
+	}
+
+	return buff
+}
+
+func isRetryableStatus(status int) bool {
+	return status == http.StatusTooManyRequests || status == http.StatusServiceUnavailable
+}
+
+func isRetryableItem(itemResponse *bulkItemResponse) bool {
+	return isRetryableStatus(itemResponse.Status) || itemResponse.Error.Type == errRejectedExecution
+}
+
+type bulkRetryPolicy struct {
+	maxRetries     int
+	initialBackoff time.Duration
+	maxBackoff     time.Duration
+}
+
+func newBulkRetryPolicy(cfg config.BulkRetryConfig) bulkRetryPolicy {
+	policy := bulkRetryPolicy{
+		maxRetries:     cfg.MaxRetries,
+		initialBackoff: time.Duration(cfg.InitialBackoffInMs) * time.Millisecond,
+		maxBackoff:     time.Duration(cfg.MaxBackoffInSeconds) * time.Second,
+	}
+	if policy.maxRetries <= 0 {
+		policy.maxRetries = defaultBulkMaxRetries
+	}
+	if policy.initialBackoff <= 0 {
+		policy.initialBackoff = defaultBulkInitialBackoffInMs * time.Millisecond
+	}
+	if policy.maxBackoff <= 0 {
+		policy.maxBackoff = defaultBulkMaxBackoffInSeconds * time.Second
+	}
+
+	return policy
+}
+
+// backoff returns the exponential delay before the provided retry, starting with 1
+func (brp bulkRetryPolicy) backoff(retry int) time.Duration {
+	delay := brp.initialBackoff
+	for i := 1; i < retry && delay < brp.maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > brp.maxBackoff {
+		delay = brp.maxBackoff
+	}
+
+	return delay
+}
+
+// failedItem holds an item of a bulk request that failed, together with the response received for it
+type failedItem struct {
+	item     *bulkItem
+	response *bulkItemResponse
+}
+
+// deadLetterRecord defines a line from the dead-letter file
+type deadLetterRecord struct {
+	Index     string          `json:"index"`
+	Status    int             `json:"status"`
+	ErrorType string          `json:"errorType"`
+	Reason    string          `json:"reason"`
+	Meta      json.RawMessage `json:"meta"`
+	Document  json.RawMessage `json:"document,omitempty"`
+}
+
+// deadLetterWriter appends the items that failed permanently in a NDJSON file
+type deadLetterWriter struct {
+	mut      sync.Mutex
+	filePath string
+}
+
+func newDeadLetterWriter(filePath string) *deadLetterWriter {
+	if filePath == "" {
+		return nil
+	}
+
+	return &deadLetterWriter{
+		filePath: filePath,
+	}
+}
+
+func (dlw *deadLetterWriter) write(index string, failedItems []*failedItem) error {
+	buff := &bytes.Buffer{}
+	for _, failed := range failedItems {
+		record := &deadLetterRecord{
+			Index:     index,
+			Status:    failed.response.Status,
+			ErrorType: failed.response.Error.Type,
+			Reason:    failed.response.Error.Reason,
+			Meta:      failed.item.meta,
+			Document:  failed.item.source,
+		}
+		recordBytes, err := json.Marshal(record)
+		if err != nil {
+			return err
+		}
+
+		buff.Write(recordBytes)
+		buff.WriteByte('\n')
+	}
+
+	dlw.mut.Lock()
+	defer dlw.mut.Unlock()
+
+	file, err := os.OpenFile(dlw.filePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	_, err = file.Write(buff.Bytes())
+
+	return err
+}
+
+// DoBulkRequest will do a bulk of request to elastic server. Only the items rejected because the cluster is busy are
+// sent again, with an exponential backoff. The items that fail permanently are written in the dead-letter file, if
+// one is configured, otherwise an error is returned. A request still rejected as a whole after the last retry returns
+// an error, its items being written in the dead-letter file first
+func (esc *esClient) DoBulkRequest(buff *bytes.Buffer, index string) error {
+	pendingItems := splitBulkItems(buff.Bytes())
+	for retry := 0; len(pendingItems) > 0; retry++ {
+		if retry > 0 {
+			delay := esc.bulkRetryPolicy.backoff(retry)
+			log.Debug("esClient.DoBulkRequest: retrying items", "index", index, "num items", len(pendingItems), "retry", retry, "sleep duration", delay)
+			time.Sleep(delay)
+
+			if esc.bulkRetryHandler != nil {
+				esc.bulkRetryHandler(index, len(pendingItems))
+			}
+		}
+
+		bulkResponse, statusCode, err := esc.sendBulk(joinBulkItems(pendingItems), index)
+		if err != nil {
+			return err
+		}
+		if isRetryableStatus(statusCode) {
+			if retry >= esc.bulkRetryPolicy.maxRetries {
+				return esc.handleRejectedRequest(index, pendingItems, statusCode, retry)
+			}
+			continue
+		}
+		if !bulkResponse.Errors {
+			return nil
+		}
+		if len(bulkResponse.Items) != len(pendingItems) {
+			return fmt.Errorf("bulk response has %d items, expected %d", len(bulkResponse.Items), len(pendingItems))
+		}
+
+		retryableItems, failedItems := classifyBulkItems(pendingItems, bulkResponse)
+		if retry >= esc.bulkRetryPolicy.maxRetries {
+			failedItems = append(failedItems, retryableItems...)
+			retryableItems = nil
+		}
+
+		err = esc.handleFailedItems(index, failedItems, bulkResponse)
+		if err != nil {
+			return err
+		}
+
+		pendingItems = make([]*bulkItem, 0, len(retryableItems))
+		for _, retryable := range retryableItems {
+			pendingItems = append(pendingItems, retryable.item)
+		}
+	}
+
+	return nil
+}
+
+// SetBulkRetryHandler sets the handler called with the number of items of a bulk request that are sent again. It should
+// be called before any bulk request is done
+func (esc *esClient) SetBulkRetryHandler(handler func(index string, numItems int)) {
+	esc.bulkRetryHandler = handler
+}
+
+func (esc *esClient) sendBulk(buff *bytes.Buffer, index string) (*bulkRequestResponse, int, error) {
+	res, err := esc.client.Bulk(
+		bytes.NewReader(buff.Bytes()),
+		esc.client.Bulk.WithIndex(index),
+	)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	defer closeBody(res)
+
+	if isRetryableStatus(res.StatusCode) {
+		return nil, res.StatusCode, nil
+	}
+	if res.IsError() {
+		return nil, res.StatusCode, fmt.Errorf("%s", res.String())
+	}
+
+	bulkResponse := &bulkRequestResponse{}
+	err = json.NewDecoder(res.Body).Decode(bulkResponse)
+	if err != nil {
+		return nil, res.StatusCode, err
+	}
+
+	return bulkResponse, res.StatusCode, nil
+}
+
+func classifyBulkItems(items []*bulkItem, response *bulkRequestResponse) ([]*failedItem, []*failedItem) {
+	retryableItems := make([]*failedItem, 0)
+	failedItems := make([]*failedItem, 0)
+	for idx, item := range response.Items {
+		itemResponse := getItemResponse(item)
+		if itemResponse.Status < http.StatusBadRequest {
+			continue
+		}
+
+		failed := &failedItem{
+			item:     items[idx],
+			response: itemResponse,
+		}
+		if isRetryableItem(itemResponse) {
+			retryableItems = append(retryableItems, failed)
+			continue
+		}
+
+		failedItems = append(failedItems, failed)
+	}
+
+	return retryableItems, failedItems
+}
+
+func (esc *esClient) handleFailedItems(index string, failedItems []*failedItem, response *bulkRequestResponse) error {
+	if len(failedItems) == 0 {
+		return nil
+	}
+	if esc.deadLetter == nil {
+		return extractErrorFromBulkResponse(response)
+	}
+
+	log.Warn("esClient.DoBulkRequest: items failed permanently, writing them in the dead-letter file",
+		"index", index, "num items", len(failedItems), "file", esc.deadLetter.filePath)
+
+	err := esc.deadLetter.write(index, failedItems)
+	if err != nil {
+		return errors.New("cannot write in the dead-letter file: " + err.Error())
+	}
+
+	return nil
+}
+
+// handleRejectedRequest writes the items of a bulk request that was rejected as a whole after the last retry in the
+// dead-letter file, if one is configured, and returns the rejection error
+func (esc *esClient) handleRejectedRequest(index string, items []*bulkItem, statusCode int, numRetries int) error {
+	errRejected := fmt.Errorf("bulk request rejected with status code %d after %d retries", statusCode, numRetries)
+	if esc.deadLetter == nil {
+		return errRejected
+	}
+
+	failedItems := make([]*failedItem, 0, len(items))
+	for _, item := range items {
+		itemResponse := &bulkItemResponse{
+			Status: statusCode,
+		}
+		itemResponse.Error.Reason = errRejected.Error()
+
+		failedItems = append(failedItems, &failedItem{
+			item:     item,
+			response: itemResponse,
+		})
+	}
+
+	log.Warn("esClient.DoBulkRequest: bulk request rejected after the last retry, writing its items in the dead-letter file",
+		"index", index, "num items", len(failedItems), "file", esc.deadLetter.filePath)
+
+	err := esc.deadLetter.write(index, failedItems)
+	if err != nil {
+		return fmt.Errorf("%w, cannot write in the dead-letter file: %s", errRejected, err.Error())
+	}
+
+	return errRejected
+}
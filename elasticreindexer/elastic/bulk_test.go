@@ -0,0 +1,220 @@
+package elastic
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/elastic/go-elasticsearch/v7"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func createBulk(ids ...string) *bytes.Buffer {
+	buff := &bytes.Buffer{}
+	for _, id := range ids {
+		buff.WriteString(fmt.Sprintf(`{ "index" : { "_id" : "%s" } }`+"\n", id))
+		buff.WriteString(fmt.Sprintf(`{"value":"%s"}`+"\n", id))
+	}
+
+	return buff
+}
+
+// createBulkServer returns a server that answers every bulk item with the status returned by statusForID
+func createBulkServer(t *testing.T, statusForID func(id string, attempt int) int) (*httptest.Server, *[][]string) {
+	mut := sync.Mutex{}
+	requests := make([][]string, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the client asks for the cluster version when it is created
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"version":{"number":"7.17.0"}}`))
+			return
+		}
+
+		body, err := ioutil.ReadAll(r.Body)
+		require.NoError(t, err)
+
+		mut.Lock()
+		defer mut.Unlock()
+
+		ids := make([]string, 0)
+		items := make([]string, 0)
+		hasErrors := false
+		for _, item := range splitBulkItems(body) {
+			id := gjson.GetBytes(item.meta, "index._id").String()
+			ids = append(ids, id)
+
+			status := statusForID(id, len(requests))
+			if status >= http.StatusBadRequest {
+				hasErrors = true
+				errorType := "mapper_parsing_exception"
+				if status == http.StatusTooManyRequests {
+					errorType = errRejectedExecution
+				}
+				items = append(items, fmt.Sprintf(`{"index":{"_id":"%s","status":%d,"error":{"type":"%s","reason":"failed"}}}`, id, status, errorType))
+				continue
+			}
+			items = append(items, fmt.Sprintf(`{"index":{"_id":"%s","status":%d}}`, id, status))
+		}
+		requests = append(requests, ids)
+
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(fmt.Sprintf(`{"errors":%v,"items":[%s]}`, hasErrors, strings.Join(items, ","))))
+	}))
+
+	return server, &requests
+}
+
+func createClient(t *testing.T, url string, retryCfg config.BulkRetryConfig) *esClient {
+	client, err := NewElasticClient(config.ElasticInstanceConfig{
+		URL:       url,
+		BulkRetry: retryCfg,
+	})
+	require.NoError(t, err)
+
+	return client
+}
+
+func TestSplitBulkItems(t *testing.T) {
+	t.Parallel()
+
+	bulk := []byte(`{"index":{"_id":"1"}}` + "\n" + `{"a":1}` + "\n" + `{"delete":{"_id":"2"}}` + "\n" + `{"index":{"_id":"3"}}` + "\n" + `{"a":3}` + "\n")
+	items := splitBulkItems(bulk)
+	require.Len(t, items, 3)
+	require.Nil(t, items[1].source)
+	require.Equal(t, `{"a":3}`, string(items[2].source))
+	require.Equal(t, bulk, joinBulkItems(items).Bytes())
+}
+
+func TestBulkRetryPolicy_Backoff(t *testing.T) {
+	t.Parallel()
+
+	policy := newBulkRetryPolicy(config.BulkRetryConfig{
+		InitialBackoffInMs:  100,
+		MaxBackoffInSeconds: 1,
+	})
+	require.Equal(t, defaultBulkMaxRetries, policy.maxRetries)
+	require.Equal(t, 100*time.Millisecond, policy.backoff(1))
+	require.Equal(t, 200*time.Millisecond, policy.backoff(2))
+	require.Equal(t, 800*time.Millisecond, policy.backoff(4))
+	require.Equal(t, time.Second, policy.backoff(5))
+}
+
+func TestEsClient_DoBulkRequestRetriesOnlyRejectedItems(t *testing.T) {
+	t.Parallel()
+
+	server, requests := createBulkServer(t, func(id string, attempt int) int {
+		if id == "2" && attempt < 2 {
+			return http.StatusTooManyRequests
+		}
+		return http.StatusCreated
+	})
+	defer server.Close()
+
+	client := createClient(t, server.URL, config.BulkRetryConfig{InitialBackoffInMs: 1})
+	err := client.DoBulkRequest(createBulk("1", "2", "3"), "index")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"1", "2", "3"}, {"2"}, {"2"}}, *requests)
+}
+
+func TestEsClient_DoBulkRequestPermanentFailureWithoutDeadLetterFile(t *testing.T) {
+	t.Parallel()
+
+	server, requests := createBulkServer(t, func(id string, _ int) int {
+		if id == "2" {
+			return http.StatusBadRequest
+		}
+		return http.StatusCreated
+	})
+	defer server.Close()
+
+	client := createClient(t, server.URL, config.BulkRetryConfig{InitialBackoffInMs: 1})
+	err := client.DoBulkRequest(createBulk("1", "2", "3"), "index")
+	require.Error(t, err)
+	require.Len(t, *requests, 1)
+}
+
+func TestEsClient_DoBulkRequestWritesDeadLetterFile(t *testing.T) {
+	t.Parallel()
+
+	server, requests := createBulkServer(t, func(id string, _ int) int {
+		switch id {
+		case "2":
+			return http.StatusBadRequest
+		case "3":
+			return http.StatusTooManyRequests
+		default:
+			return http.StatusCreated
+		}
+	})
+	defer server.Close()
+
+	deadLetterFile := filepath.Join(t.TempDir(), "dead-letter.ndjson")
+	client := createClient(t, server.URL, config.BulkRetryConfig{
+		MaxRetries:         2,
+		InitialBackoffInMs: 1,
+		DeadLetterFile:     deadLetterFile,
+	})
+	err := client.DoBulkRequest(createBulk("1", "2", "3"), "index")
+	require.NoError(t, err)
+	require.Equal(t, [][]string{{"1", "2", "3"}, {"3"}, {"3"}}, *requests)
+
+	deadLetterBytes, err := os.ReadFile(deadLetterFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(deadLetterBytes)), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, "index", gjson.Get(lines[0], "index").String())
+	require.Equal(t, int64(http.StatusBadRequest), gjson.Get(lines[0], "status").Int())
+	require.Equal(t, "2", gjson.Get(lines[0], "meta.index._id").String())
+	require.Equal(t, "2", gjson.Get(lines[0], "document.value").String())
+	require.Equal(t, errRejectedExecution, gjson.Get(lines[1], "errorType").String())
+	require.Equal(t, "3", gjson.Get(lines[1], "meta.index._id").String())
+}
+
+func TestEsClient_DoBulkRequestRejectedRequestShouldWriteDeadLetterFile(t *testing.T) {
+	t.Parallel()
+
+	numRequests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			_, _ = w.Write([]byte(`{"version":{"number":"7.17.0"}}`))
+			return
+		}
+
+		numRequests++
+		w.WriteHeader(http.StatusTooManyRequests)
+	}))
+	defer server.Close()
+
+	deadLetterFile := filepath.Join(t.TempDir(), "dead-letter.ndjson")
+	client := createClient(t, server.URL, config.BulkRetryConfig{
+		MaxRetries:         1,
+		InitialBackoffInMs: 1,
+		DeadLetterFile:     deadLetterFile,
+	})
+	// the transport retries of the rejected requests would slow down the test
+	client.client, _ = elasticsearch.NewClient(elasticsearch.Config{
+		Addresses:    []string{server.URL},
+		DisableRetry: true,
+	})
+	err := client.DoBulkRequest(createBulk("1", "2"), "index")
+	require.Error(t, err)
+	require.Equal(t, 2, numRequests)
+
+	deadLetterBytes, err := os.ReadFile(deadLetterFile)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(deadLetterBytes)), "\n")
+	require.Len(t, lines, 2)
+	require.Equal(t, int64(http.StatusTooManyRequests), gjson.Get(lines[0], "status").Int())
+	require.Equal(t, "1", gjson.Get(lines[0], "meta.index._id").String())
+	require.Equal(t, "2", gjson.Get(lines[1], "document.value").String())
+}
@@ -10,27 +10,41 @@ const numOfErrorsToExtractBulkResponse = 5
 // bulkRequestResponse defines the structure of a bulk request response
 type bulkRequestResponse struct {
 	Errors bool `json:"errors"`
-	Items  []struct {
-		Index struct {
-			Status int `json:"status"`
-			Error  struct {
-				Type   string `json:"type"`
-				Reason string `json:"reason"`
-			} `json:"error"`
-		} `json:"index"`
-	} `json:"items"`
+	// every item is keyed by the action of the request item (index, create, update or delete)
+	Items []map[string]*bulkItemResponse `json:"items"`
+}
+
+// bulkItemResponse defines the structure of the response of an item from a bulk request
+type bulkItemResponse struct {
+	ID     string `json:"_id"`
+	Status int    `json:"status"`
+	Error  struct {
+		Type   string `json:"type"`
+		Reason string `json:"reason"`
+	} `json:"error"`
+}
+
+func getItemResponse(item map[string]*bulkItemResponse) *bulkItemResponse {
+	for _, itemResponse := range item {
+		if itemResponse != nil {
+			return itemResponse
+		}
+	}
+
+	return &bulkItemResponse{}
 }
 
 func extractErrorFromBulkResponse(response *bulkRequestResponse) error {
 	count := 0
 	errorsString := ""
 	for _, item := range response.Items {
-		if item.Index.Status < http.StatusBadRequest {
+		itemResponse := getItemResponse(item)
+		if itemResponse.Status < http.StatusBadRequest {
 			continue
 		}
 
 		count++
-		errorsString += fmt.Sprintf("{ status code: %d, error type: %s, reason: %s }\n", item.Index.Status, item.Index.Error.Type, item.Index.Error.Reason)
+		errorsString += fmt.Sprintf("{ status code: %d, error type: %s, reason: %s }\n", itemResponse.Status, itemResponse.Error.Type, itemResponse.Error.Reason)
 
 		if count == numOfErrorsToExtractBulkResponse {
 			break
@@ -4,11 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
 	"math"
 	"net/http"
 	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/elastic/go-elasticsearch/v7"
@@ -26,6 +28,8 @@ var (
 const (
 	stepDelayBetweenRequests = 500 * time.Millisecond
 	numRetriesBackOff        = 10
+	pitKeepAlive             = "10m"
+	defaultScrollSize        = 9000
 
 	errPolicyAlreadyExists = "document already exists"
 )
@@ -34,16 +38,33 @@ type esClient struct {
 	client *elasticsearch.Client
 
 	// countScroll is used to be incremented after each scroll so the scroll duration is different each time,
-	// bypassing any possible caching based on the same request
-	countScroll int
+	// bypassing any possible caching based on the same request. It is accessed atomically as scrolls can run in parallel
+	countScroll uint64
+
+	bulkRetryPolicy  bulkRetryPolicy
+	deadLetter       *deadLetterWriter
+	bulkRetryHandler func(index string, numItems int)
+	backend          backendInfo
 }
 
 // NewElasticClient will create a new instance of an esClient
 func NewElasticClient(cfg config.ElasticInstanceConfig) (*esClient, error) {
+	creds, err := resolveCredentials(cfg)
+	if err != nil {
+		return nil, err
+	}
+	transport, err := createTransport(cfg.TLS)
+	if err != nil {
+		return nil, err
+	}
+
 	elasticClient, err := elasticsearch.NewClient(elasticsearch.Config{
 		Addresses:     []string{cfg.URL},
-		Username:      cfg.Username,
-		Password:      cfg.Password,
+		Username:      creds.username,
+		Password:      creds.password,
+		APIKey:        creds.apiKey,
+		Header:        creds.authorizationHeader(),
+		Transport:     transport,
 		RetryOnStatus: httpStatusesForRetry,
 		RetryBackoff: func(i int) time.Duration {
 			// A simple exponential delay
@@ -57,10 +78,15 @@ func NewElasticClient(cfg config.ElasticInstanceConfig) (*esClient, error) {
 		return nil, err
 	}
 
-	return &esClient{
-		client:      elasticClient,
-		countScroll: 0,
-	}, nil
+	esc := &esClient{
+		client:          elasticClient,
+		countScroll:     0,
+		bulkRetryPolicy: newBulkRetryPolicy(cfg.BulkRetry),
+		deadLetter:      newDeadLetterWriter(cfg.BulkRetry.DeadLetterFile),
+	}
+	esc.detectBackend()
+
+	return esc, nil
 }
 
 // GetMultiple queries a multi search and returns the responses
@@ -128,7 +154,16 @@ func (esc *esClient) GetMapping(index string) (*bytes.Buffer, error) {
 		return nil, err
 	}
 
-	propertiesRes := gjson.Get(string(respBytes), fmt.Sprintf("%s-000001", index))
+	// the provided index can be an alias with more backing indices, case when the mapping of the write index is used
+	concreteIndex := index
+	if !gjson.GetBytes(respBytes, escapePath(index)).Exists() {
+		concreteIndex, err = esc.GetWriteIndex(index)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	propertiesRes := gjson.GetBytes(respBytes, escapePath(concreteIndex))
 
 	return bytes.NewBufferString(propertiesRes.Raw), nil
 }
@@ -246,16 +281,37 @@ func exists(res *esapi.Response, err error) bool {
 	}
 }
 
-// DoScrollRequestAllDocuments will perform a documents request using scroll api
+// DoSearchRequest will perform a search request with the provided body and return the response bytes
+func (esc *esClient) DoSearchRequest(index string, body []byte) ([]byte, error) {
+	res, err := esc.client.Search(
+		esc.client.Search.WithContext(context.Background()),
+		esc.client.Search.WithIndex(index),
+		esc.client.Search.WithBody(bytes.NewBuffer(body)),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return getBytesFromResponse(res)
+}
+
+// DoScrollRequestAllDocuments will perform a documents request using scroll api. The size from the body, if any, is
+// used as the size of a page
 func (esc *esClient) DoScrollRequestAllDocuments(
 	index string,
 	body []byte,
 	handlerFunc func(responseBytes []byte) error,
 ) error {
-	esc.countScroll++
+	size := defaultScrollSize
+	bodySize := gjson.GetBytes(body, "size")
+	if bodySize.Exists() {
+		size = int(bodySize.Int())
+	}
+
+	countScroll := atomic.AddUint64(&esc.countScroll, 1)
 	res, err := esc.client.Search(
-		esc.client.Search.WithSize(9000),
-		esc.client.Search.WithScroll(10*time.Minute+time.Duration(esc.countScroll)*time.Millisecond),
+		esc.client.Search.WithSize(size),
+		esc.client.Search.WithScroll(10*time.Minute+time.Duration(countScroll)*time.Millisecond),
 		esc.client.Search.WithContext(context.Background()),
 		esc.client.Search.WithIndex(index),
 		esc.client.Search.WithBody(bytes.NewBuffer(body)),
@@ -278,36 +334,139 @@ func (esc *esClient) DoScrollRequestAllDocuments(
 	return esc.iterateScroll(scrollID.String(), handlerFunc)
 }
 
-// DoBulkRequest will do a bulk of request to elastic server
-func (esc *esClient) DoBulkRequest(buff *bytes.Buffer, index string) error {
-	reader := bytes.NewReader(buff.Bytes())
+// DoPitRequestAllDocuments will perform a documents request using a point in time and search_after. The provided body
+// should contain a deterministic sort, the documents being fetched in pages of the provided size
+func (esc *esClient) DoPitRequestAllDocuments(
+	index string,
+	body []byte,
+	pageSize int,
+	handlerFunc func(responseBytes []byte) error,
+) error {
+	query := make(map[string]interface{})
+	decoder := json.NewDecoder(bytes.NewReader(body))
+	// the numbers have to be preserved as they are, the sort values of a page being used for the next one
+	decoder.UseNumber()
+	err := decoder.Decode(&query)
+	if err != nil {
+		return fmt.Errorf("%w while decoding the query body", err)
+	}
+	if esc.backend.isOpenSearch() {
+		withOpenSearchSort(query)
+	}
 
-	res, err := esc.client.Bulk(
-		reader,
-		esc.client.Bulk.WithIndex(index),
-	)
+	pitID, err := esc.openPointInTime(index)
 	if err != nil {
 		return err
 	}
-	if res.IsError() {
-		return fmt.Errorf("%s", res.String())
+	defer func() {
+		errClose := esc.closePointInTime(pitID)
+		if errClose != nil {
+			log.Warn("cannot close point in time", "error", errClose)
+		}
+	}()
+
+	query["size"] = pageSize
+	for {
+		query["pit"] = map[string]interface{}{
+			"id":         pitID,
+			"keep_alive": pitKeepAlive,
+		}
+
+		bodyBytes, errSearch := esc.doPitSearch(query)
+		if errSearch != nil {
+			return errSearch
+		}
+
+		numberOfHits := gjson.GetBytes(bodyBytes, "hits.hits.#").Int()
+		if numberOfHits < 1 {
+			return nil
+		}
+
+		err = handlerFunc(bodyBytes)
+		if err != nil {
+			return err
+		}
+
+		// the point in time id can change between the search requests
+		newPitID := gjson.GetBytes(bodyBytes, "pit_id").String()
+		if newPitID != "" {
+			pitID = newPitID
+		}
+		lastSortValue := gjson.GetBytes(bodyBytes, fmt.Sprintf("hits.hits.%d.sort", numberOfHits-1))
+		if !lastSortValue.Exists() {
+			return errors.New("the point in time search response does not contain sort values")
+		}
+		query["search_after"] = json.RawMessage(lastSortValue.Raw)
+
+		if numberOfHits < int64(pageSize) {
+			return nil
+		}
+
+		time.Sleep(stepDelayBetweenRequests)
 	}
+}
 
-	defer closeBody(res)
+func (esc *esClient) openPointInTime(index string) (string, error) {
+	if esc.backend.isOpenSearch() {
+		return esc.openOpenSearchPointInTime(index)
+	}
 
-	bodyBytes, err := ioutil.ReadAll(res.Body)
+	res, err := esc.client.OpenPointInTime(
+		esc.client.OpenPointInTime.WithIndex(index),
+		esc.client.OpenPointInTime.WithKeepAlive(pitKeepAlive),
+	)
 	if err != nil {
-		return err
+		return "", err
+	}
+
+	bodyBytes, err := getBytesFromResponse(res)
+	if err != nil {
+		return "", err
+	}
+
+	pitID := gjson.GetBytes(bodyBytes, "id").String()
+	if pitID == "" {
+		return "", fmt.Errorf("empty point in time id for index %s", index)
 	}
 
-	bulkResponse := &bulkRequestResponse{}
-	err = json.Unmarshal(bodyBytes, bulkResponse)
+	return pitID, nil
+}
+
+func (esc *esClient) doPitSearch(query map[string]interface{}) ([]byte, error) {
+	var buff bytes.Buffer
+	err := json.NewEncoder(&buff).Encode(query)
+	if err != nil {
+		return nil, fmt.Errorf("%w while encoding the point in time query", err)
+	}
+
+	// the search request should not specify the index, it is given by the point in time
+	res, err := esc.client.Search(
+		esc.client.Search.WithContext(context.Background()),
+		esc.client.Search.WithBody(&buff),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	return getBytesFromResponse(res)
+}
+
+func (esc *esClient) closePointInTime(pitID string) error {
+	if esc.backend.isOpenSearch() {
+		return esc.closeOpenSearchPointInTime(pitID)
+	}
+
+	body := fmt.Sprintf(`{"id":"%s"}`, pitID)
+	res, err := esc.client.ClosePointInTime(
+		esc.client.ClosePointInTime.WithBody(bytes.NewBufferString(body)),
+	)
 	if err != nil {
 		return err
 	}
+	defer closeBody(res)
 
-	if bulkResponse.Errors {
-		return extractErrorFromBulkResponse(bulkResponse)
+	if res.IsError() && res.StatusCode != http.StatusNotFound {
+		return fmt.Errorf("error response: %s", res)
 	}
 
 	return nil
@@ -347,10 +506,10 @@ func (esc *esClient) iterateScroll(
 }
 
 func (esc *esClient) getScrollResponse(scrollID string) ([]byte, error) {
-	esc.countScroll++
+	countScroll := atomic.AddUint64(&esc.countScroll, 1)
 	res, err := esc.client.Scroll(
 		esc.client.Scroll.WithScrollID(scrollID),
-		esc.client.Scroll.WithScroll(2*time.Minute+time.Duration(esc.countScroll)*time.Millisecond),
+		esc.client.Scroll.WithScroll(2*time.Minute+time.Duration(countScroll)*time.Millisecond),
 	)
 	if err != nil {
 		return nil, err
@@ -375,9 +534,189 @@ func (esc *esClient) clearScroll(scrollID string) error {
 	return nil
 }
 
-// PutAlias will set the provided alias to the provided index
+// PutAlias will set the provided alias to the provided index, as its write index, so the index can be rolled over later
 func (esc *esClient) PutAlias(index string, alias string) error {
-	res, err := esc.client.Indices.PutAlias([]string{index}, alias)
+	res, err := esc.client.Indices.PutAlias(
+		[]string{index},
+		alias,
+		esc.client.Indices.PutAlias.WithBody(bytes.NewBufferString(`{"is_write_index":true}`)),
+	)
+	if err != nil {
+		return err
+	}
+
+	defer closeBody(res)
+
+	if res.IsError() {
+		return fmt.Errorf("%s", res.String())
+	}
+
+	return nil
+}
+
+// GetIndexTemplate returns the index template with the provided name, or nil if the template does not exist
+func (esc *esClient) GetIndexTemplate(templateName string) ([]byte, error) {
+	res, err := esc.client.Indices.GetIndexTemplate(
+		esc.client.Indices.GetIndexTemplate.WithName(templateName),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		closeBody(res)
+		return nil, nil
+	}
+
+	respBytes, err := getBytesFromResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
+	template := gjson.GetBytes(respBytes, fmt.Sprintf(`index_templates.#(name=="%s").index_template`, templateName))
+	if !template.Exists() {
+		return nil, nil
+	}
+
+	return []byte(template.Raw), nil
+}
+
+// GetMatchingIndexTemplate returns the settings, the mappings and the aliases that the index templates matching the
+// provided index name would give to a new index, under the template key, or nil if no template matches
+func (esc *esClient) GetMatchingIndexTemplate(index string) ([]byte, error) {
+	res, err := esc.client.Indices.SimulateIndexTemplate(index)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		closeBody(res)
+		return nil, nil
+	}
+
+	return getBytesFromResponse(res)
+}
+
+// GetPolicy returns the lifecycle policy with the provided name, in the same format used for creating it, or nil if
+// the policy does not exist. On OpenSearch, the Index State Management policy is returned
+func (esc *esClient) GetPolicy(policyName string) ([]byte, error) {
+	if esc.backend.isOpenSearch() {
+		return esc.getISMPolicy(policyName)
+	}
+
+	res, err := esc.client.ILM.GetLifecycle(
+		esc.client.ILM.GetLifecycle.WithPolicy(policyName),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		closeBody(res)
+		return nil, nil
+	}
+
+	respBytes, err := getBytesFromResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := gjson.GetBytes(respBytes, escapePath(policyName)+".policy")
+	if !policy.Exists() {
+		return nil, nil
+	}
+
+	return []byte(fmt.Sprintf(`{"policy":%s}`, policy.Raw)), nil
+}
+
+// GetAliases returns the indices behind the provided alias, keyed by the index name, with the alias properties, or
+// nil if the alias does not exist
+func (esc *esClient) GetAliases(alias string) ([]byte, error) {
+	res, err := esc.client.Indices.GetAlias(
+		esc.client.Indices.GetAlias.WithName(alias),
+	)
+	if err != nil {
+		return nil, err
+	}
+	if res.StatusCode == http.StatusNotFound {
+		closeBody(res)
+		return nil, nil
+	}
+
+	return getBytesFromResponse(res)
+}
+
+// GetWriteIndex returns the index where the documents written through the provided alias are stored: the index marked
+// with is_write_index, or the only index behind the alias. It returns an empty string if the alias does not exist or if
+// it has no write index
+func (esc *esClient) GetWriteIndex(alias string) (string, error) {
+	aliasesBody, err := esc.GetAliases(alias)
+	if err != nil {
+		return "", err
+	}
+
+	return getWriteIndexFromAliases(aliasesBody, alias), nil
+}
+
+func getWriteIndexFromAliases(aliasesBody []byte, alias string) string {
+	indices := make([]string, 0)
+	for index, aliases := range gjson.ParseBytes(aliasesBody).Map() {
+		aliasProperties := aliases.Get("aliases." + escapePath(alias))
+		if !aliasProperties.Exists() {
+			continue
+		}
+		if aliasProperties.Get("is_write_index").Bool() {
+			return index
+		}
+
+		indices = append(indices, index)
+	}
+
+	if len(indices) == 1 {
+		return indices[0]
+	}
+
+	return ""
+}
+
+// Rollover will create the next generation of the indices behind the provided alias and will move the write alias on
+// it. It returns the old and the new write indices
+func (esc *esClient) Rollover(alias string) (string, string, error) {
+	res, err := esc.client.Indices.Rollover(alias)
+	if err != nil {
+		return "", "", err
+	}
+
+	respBytes, err := getBytesFromResponse(res)
+	if err != nil {
+		return "", "", err
+	}
+
+	response := gjson.ParseBytes(respBytes)
+
+	return response.Get("old_index").String(), response.Get("new_index").String(), nil
+}
+
+// GetIndexMapping returns the mappings of the provided concrete index
+func (esc *esClient) GetIndexMapping(index string) ([]byte, error) {
+	res, err := esc.client.Indices.GetMapping(
+		esc.client.Indices.GetMapping.WithIndex(index),
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	respBytes, err := getBytesFromResponse(res)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(gjson.GetBytes(respBytes, escapePath(index)+".mappings").Raw), nil
+}
+
+// PutMapping will add the provided mappings to the provided index
+func (esc *esClient) PutMapping(index string, body *bytes.Buffer) error {
+	res, err := esc.client.Indices.PutMapping(
+		body,
+		esc.client.Indices.PutMapping.WithIndex(index),
+	)
 	if err != nil {
 		return err
 	}
@@ -397,8 +736,13 @@ type responseCreatePolicy struct {
 	Status int         `json:"status"`
 }
 
-// PutPolicy will put in Elasticsearch cluster the provided policy with the given name
+// PutPolicy will put in Elasticsearch cluster the provided policy with the given name. On OpenSearch, the policy is
+// created as an Index State Management policy, a lifecycle policy being converted first
 func (esc *esClient) PutPolicy(policyName string, policy *bytes.Buffer) error {
+	if esc.backend.isOpenSearch() {
+		return esc.putISMPolicy(policyName, policy.Bytes())
+	}
+
 	res, err := esc.client.ILM.PutLifecycle(
 		policyName,
 		esc.client.ILM.PutLifecycle.WithBody(policy),
@@ -431,6 +775,12 @@ func (esc *esClient) IsInterfaceNil() bool {
 	return esc == nil
 }
 
+// escapePath escapes the characters of the provided key that have a special meaning in a gjson path
+func escapePath(key string) string {
+	replacer := strings.NewReplacer(".", `\.`, "*", `\*`, "?", `\?`)
+	return replacer.Replace(key)
+}
+
 func getBytesFromResponse(res *esapi.Response) ([]byte, error) {
 	if res.IsError() {
 		return nil, fmt.Errorf("error response: %s", res)
@@ -0,0 +1,20 @@
+package elastic
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetWriteIndexFromAliases(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, "", getWriteIndexFromAliases(nil, "blocks"))
+	require.Equal(t, "blocks-000001", getWriteIndexFromAliases([]byte(`{"blocks-000001":{"aliases":{"blocks":{}}}}`), "blocks"))
+
+	rolledOver := []byte(`{"blocks-000001":{"aliases":{"blocks":{"is_write_index":false}}},"blocks-000002":{"aliases":{"blocks":{"is_write_index":true}}}}`)
+	require.Equal(t, "blocks-000002", getWriteIndexFromAliases(rolledOver, "blocks"))
+
+	noWriteIndex := []byte(`{"blocks-000001":{"aliases":{"blocks":{}}},"blocks-000002":{"aliases":{"blocks":{}}}}`)
+	require.Equal(t, "", getWriteIndexFromAliases(noWriteIndex, "blocks"))
+}
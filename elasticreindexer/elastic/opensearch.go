@@ -0,0 +1,396 @@
+package elastic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	ismPoliciesPath = "/_plugins/_ism/policies/"
+	ismAddPath      = "/_plugins/_ism/add/"
+	osPitPath       = "/_search/point_in_time"
+
+	// policyNameSuffix is the suffix of the policies created by indices-creator, e.g. blocks-policy
+	policyNameSuffix = "-policy"
+	ismRolloverAlias = "plugins.index_state_management.rollover_alias"
+	ismPriority      = 100
+
+	errAlreadyManaged = "already being managed"
+)
+
+type object = map[string]interface{}
+
+// ilmPhases holds the lifecycle phases in the order they are executed
+var ilmPhases = []string{"hot", "warm", "cold", "frozen", "delete"}
+
+// ilmActions holds the lifecycle actions in the order they are executed inside a phase
+var ilmActions = []string{"set_priority", "rollover", "readonly", "allocate", "shrink", "forcemerge", "delete"}
+
+// ilmRolloverConditions maps the ILM rollover conditions to the ISM ones
+var ilmRolloverConditions = map[string]string{
+	"max_size":               "min_size",
+	"max_primary_shard_size": "min_primary_shard_size",
+	"max_docs":               "min_doc_count",
+	"max_age":                "min_index_age",
+}
+
+type ismPolicy struct {
+	Policy ismPolicyBody `json:"policy"`
+}
+
+type ismPolicyBody struct {
+	Description  string        `json:"description"`
+	DefaultState string        `json:"default_state"`
+	States       []ismState    `json:"states"`
+	ISMTemplate  []ismTemplate `json:"ism_template,omitempty"`
+}
+
+type ismState struct {
+	Name        string          `json:"name"`
+	Actions     []object        `json:"actions"`
+	Transitions []ismTransition `json:"transitions"`
+}
+
+type ismTransition struct {
+	StateName  string `json:"state_name"`
+	Conditions object `json:"conditions,omitempty"`
+}
+
+type ismTemplate struct {
+	IndexPatterns []string `json:"index_patterns"`
+	Priority      int      `json:"priority"`
+}
+
+// IsISMPolicy returns true if the provided policy is an OpenSearch Index State Management policy
+func IsISMPolicy(policy []byte) bool {
+	return gjson.GetBytes(policy, "policy.states").Exists()
+}
+
+// ConvertToISMPolicy converts an Elasticsearch lifecycle policy into an OpenSearch Index State Management policy. Every
+// phase becomes a state, the min_age of the next phase becoming the condition of the transition. The policy is
+// attached to the new indices matching the provided pattern, if any
+func ConvertToISMPolicy(ilmPolicy []byte, indexPattern string) ([]byte, error) {
+	phases := gjson.GetBytes(ilmPolicy, "policy.phases")
+	if !phases.Exists() {
+		return nil, fmt.Errorf("the lifecycle policy has no phases")
+	}
+
+	states := make([]ismState, 0)
+	minAges := make([]string, 0)
+	for _, phaseName := range ilmPhases {
+		phase := phases.Get(phaseName)
+		if !phase.Exists() {
+			continue
+		}
+
+		actions, err := convertILMActions(phase.Get("actions"))
+		if err != nil {
+			return nil, fmt.Errorf("%w in phase %s", err, phaseName)
+		}
+		states = append(states, ismState{
+			Name:        phaseName,
+			Actions:     actions,
+			Transitions: make([]ismTransition, 0),
+		})
+		minAges = append(minAges, phase.Get("min_age").String())
+	}
+	if len(states) == 0 {
+		return nil, fmt.Errorf("the lifecycle policy has no known phases")
+	}
+
+	for idx := 0; idx < len(states)-1; idx++ {
+		transition := ismTransition{
+			StateName: states[idx+1].Name,
+		}
+		minAge := minAges[idx+1]
+		if minAge != "" && minAge != "0ms" && minAge != "0" {
+			transition.Conditions = object{"min_index_age": minAge}
+		}
+		states[idx].Transitions = append(states[idx].Transitions, transition)
+	}
+
+	policy := ismPolicy{
+		Policy: ismPolicyBody{
+			Description:  "Converted from an Elasticsearch lifecycle policy",
+			DefaultState: states[0].Name,
+			States:       states,
+		},
+	}
+	if indexPattern != "" {
+		policy.Policy.ISMTemplate = []ismTemplate{{IndexPatterns: []string{indexPattern}, Priority: ismPriority}}
+	}
+
+	return json.MarshalIndent(policy, "", "  ")
+}
+
+func convertILMActions(actions gjson.Result) ([]object, error) {
+	for name := range actions.Map() {
+		if !containsString(ilmActions, name) {
+			return nil, fmt.Errorf("unsupported lifecycle action %s", name)
+		}
+	}
+
+	converted := make([]object, 0)
+	for _, name := range ilmActions {
+		action := actions.Get(name)
+		if !action.Exists() {
+			continue
+		}
+
+		ismAction, err := convertILMAction(name, action)
+		if err != nil {
+			return nil, err
+		}
+		converted = append(converted, ismAction)
+	}
+
+	return converted, nil
+}
+
+func convertILMAction(name string, action gjson.Result) (object, error) {
+	switch name {
+	case "set_priority":
+		return object{"index_priority": object{"priority": action.Get("priority").Value()}}, nil
+	case "rollover":
+		conditions := object{}
+		for ilmCondition, value := range action.Map() {
+			ismCondition, found := ilmRolloverConditions[ilmCondition]
+			if !found {
+				return nil, fmt.Errorf("unsupported rollover condition %s", ilmCondition)
+			}
+			conditions[ismCondition] = value.Value()
+		}
+		return object{"rollover": conditions}, nil
+	case "readonly":
+		return object{"read_only": object{}}, nil
+	case "allocate":
+		replicas := action.Get("number_of_replicas")
+		if !replicas.Exists() {
+			return nil, fmt.Errorf("only the number_of_replicas of the allocate action is supported")
+		}
+		return object{"replica_count": object{"number_of_replicas": replicas.Value()}}, nil
+	case "shrink":
+		return object{"shrink": object{"num_new_shards": action.Get("number_of_shards").Value()}}, nil
+	case "forcemerge":
+		return object{"force_merge": object{"max_num_segments": action.Get("max_num_segments").Value()}}, nil
+	default:
+		return object{"delete": object{}}, nil
+	}
+}
+
+// ConvertToOpenSearchTemplate replaces the lifecycle settings of an index template with the Index State Management
+// ones: the policy is attached through the ism_template of the policy, so only the rollover alias is kept
+func ConvertToOpenSearchTemplate(template []byte) ([]byte, error) {
+	body := make(object)
+	decoder := json.NewDecoder(bytes.NewReader(template))
+	decoder.UseNumber()
+	err := decoder.Decode(&body)
+	if err != nil {
+		return nil, err
+	}
+
+	templateBody, _ := body["template"].(object)
+	settings, _ := templateBody["settings"].(object)
+	if settings == nil {
+		return template, nil
+	}
+
+	rolloverAlias, found := settings["index.lifecycle.rollover_alias"]
+	delete(settings, "index.lifecycle.name")
+	delete(settings, "index.lifecycle.rollover_alias")
+
+	indexSettings, _ := settings["index"].(object)
+	lifecycle, _ := indexSettings["lifecycle"].(object)
+	if lifecycle != nil {
+		if alias, ok := lifecycle["rollover_alias"]; ok {
+			rolloverAlias, found = alias, true
+		}
+		delete(indexSettings, "lifecycle")
+	}
+
+	if found {
+		settings[ismRolloverAlias] = rolloverAlias
+	}
+
+	return json.MarshalIndent(body, "", "  ")
+}
+
+// normalizeISMPolicy removes the fields added by OpenSearch to a stored policy, so it can be compared with the local one
+func normalizeISMPolicy(policy object) {
+	delete(policy, "policy_id")
+	delete(policy, "last_updated_time")
+	delete(policy, "schema_version")
+	if policy["error_notification"] == nil {
+		delete(policy, "error_notification")
+	}
+
+	templates, _ := policy["ism_template"].([]interface{})
+	for _, template := range templates {
+		templateObj, _ := template.(object)
+		delete(templateObj, "last_updated_time")
+	}
+
+	states, _ := policy["states"].([]interface{})
+	for _, state := range states {
+		stateObj, _ := state.(object)
+		actions, _ := stateObj["actions"].([]interface{})
+		for _, action := range actions {
+			actionObj, _ := action.(object)
+			if isDefaultISMRetry(actionObj["retry"]) {
+				delete(actionObj, "retry")
+			}
+			rollover, _ := actionObj["rollover"].(object)
+			if rollover != nil && rollover["copy_alias"] == false {
+				delete(rollover, "copy_alias")
+			}
+		}
+	}
+}
+
+func isDefaultISMRetry(retry interface{}) bool {
+	retryObj, _ := retry.(object)
+	if retryObj == nil {
+		return false
+	}
+
+	return fmt.Sprintf("%v", retryObj["count"]) == "3" && retryObj["backoff"] == "exponential" && retryObj["delay"] == "1m"
+}
+
+func (esc *esClient) getISMPolicy(policyName string) ([]byte, error) {
+	status, respBytes, err := esc.doRequest(http.MethodGet, ismPoliciesPath+url.PathEscape(policyName), nil)
+	if err != nil {
+		return nil, err
+	}
+	if status == http.StatusNotFound {
+		return nil, nil
+	}
+
+	policy := make(object)
+	decoder := json.NewDecoder(bytes.NewReader(respBytes))
+	decoder.UseNumber()
+	err = decoder.Decode(&struct {
+		Policy *object `json:"policy"`
+	}{Policy: &policy})
+	if err != nil {
+		return nil, err
+	}
+
+	normalizeISMPolicy(policy)
+
+	return json.Marshal(object{"policy": policy})
+}
+
+// putISMPolicy creates or updates an Index State Management policy. A lifecycle policy is converted first, being
+// attached to the indices named after the policy, e.g. blocks-* for blocks-policy
+func (esc *esClient) putISMPolicy(policyName string, policy []byte) error {
+	if !IsISMPolicy(policy) {
+		indexPattern := ""
+		if strings.HasSuffix(policyName, policyNameSuffix) {
+			indexPattern = strings.TrimSuffix(policyName, policyNameSuffix) + "-*"
+		}
+
+		var err error
+		policy, err = ConvertToISMPolicy(policy, indexPattern)
+		if err != nil {
+			return fmt.Errorf("%w while converting the lifecycle policy %s", err, policyName)
+		}
+	}
+
+	// an existing policy can be updated only with its current sequence number and primary term
+	policyPath := ismPoliciesPath + url.PathEscape(policyName)
+	status, respBytes, err := esc.doRequest(http.MethodGet, policyPath, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusNotFound {
+		policyPath += fmt.Sprintf("?if_seq_no=%d&if_primary_term=%d",
+			gjson.GetBytes(respBytes, "_seq_no").Int(), gjson.GetBytes(respBytes, "_primary_term").Int())
+	}
+
+	status, respBytes, err = esc.doRequest(http.MethodPut, policyPath, policy)
+	if err != nil {
+		return err
+	}
+	if status >= http.StatusBadRequest {
+		return fmt.Errorf("error esClient.PutPolicy: [%d] %s", status, respBytes)
+	}
+
+	return nil
+}
+
+// AttachPolicy attaches the provided policy to an existing index. Elasticsearch attaches the lifecycle policy through
+// the index.lifecycle.name setting of the template, so this is needed only on OpenSearch, where the ism_template of
+// the policy applies only to the indices created after the policy
+func (esc *esClient) AttachPolicy(index string, policyName string) error {
+	if !esc.backend.isOpenSearch() {
+		return nil
+	}
+
+	body := []byte(fmt.Sprintf(`{"policy_id":%q}`, policyName))
+	_, respBytes, err := esc.doRequest(http.MethodPost, ismAddPath+url.PathEscape(index), body)
+	if err != nil {
+		return err
+	}
+	if !gjson.GetBytes(respBytes, "failures").Bool() {
+		return nil
+	}
+
+	for _, failed := range gjson.GetBytes(respBytes, "failed_indices").Array() {
+		reason := failed.Get("reason").String()
+		if !strings.Contains(reason, errAlreadyManaged) {
+			return fmt.Errorf("cannot attach policy %s to index %s: %s", policyName, index, reason)
+		}
+	}
+
+	return nil
+}
+
+func (esc *esClient) openOpenSearchPointInTime(index string) (string, error) {
+	// the point in time search was added in OpenSearch 2.4, with other endpoints than the Elasticsearch ones
+	if !esc.backend.isAtLeast(2, 4) {
+		return "", fmt.Errorf("the point in time search needs OpenSearch 2.4 or newer, the cluster has %s, use the scroll read mode", esc.backend.version)
+	}
+
+	path := fmt.Sprintf("/%s%s?keep_alive=%s", url.PathEscape(index), osPitPath, pitKeepAlive)
+	_, respBytes, err := esc.doRequest(http.MethodPost, path, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return gjson.GetBytes(respBytes, "pit_id").String(), nil
+}
+
+func (esc *esClient) closeOpenSearchPointInTime(pitID string) error {
+	body, _ := json.Marshal(object{"pit_id": []string{pitID}})
+	_, _, err := esc.doRequest(http.MethodDelete, osPitPath, body)
+
+	return err
+}
+
+// withOpenSearchSort replaces the _shard_doc tiebreaker, which is specific to Elasticsearch, with the _id field
+func withOpenSearchSort(query object) {
+	sort, _ := query["sort"].([]interface{})
+	for idx, sortField := range sort {
+		sortObj, _ := sortField.(object)
+		shardDoc, found := sortObj["_shard_doc"]
+		if found {
+			sort[idx] = object{"_id": shardDoc}
+		}
+	}
+}
+
+func containsString(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,152 @@
+package elastic
+
+import (
+	"bytes"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const ilmPolicy = `{"policy":{"phases":{
+	"delete":{"min_age":"30d","actions":{"delete":{}}},
+	"hot":{"min_age":"0ms","actions":{"rollover":{"max_size":"50GB","max_docs":50000000}}}
+}}}`
+
+func TestParseBackendInfo(t *testing.T) {
+	t.Parallel()
+
+	info := parseBackendInfo([]byte(`{"version":{"number":"7.17.3"}}`))
+	require.False(t, info.isOpenSearch())
+	require.Equal(t, 7, info.major)
+
+	info = parseBackendInfo([]byte(`{"version":{"distribution":"opensearch","number":"2.11.0"}}`))
+	require.True(t, info.isOpenSearch())
+	require.True(t, info.isAtLeast(2, 4))
+	require.False(t, info.isAtLeast(2, 12))
+	require.False(t, parseBackendInfo([]byte(`{"version":{"distribution":"opensearch","number":"1.3.0"}}`)).isAtLeast(2, 4))
+}
+
+func TestConvertToISMPolicy(t *testing.T) {
+	t.Parallel()
+
+	ismPolicy, err := ConvertToISMPolicy([]byte(ilmPolicy), "blocks-*")
+	require.NoError(t, err)
+	require.True(t, IsISMPolicy(ismPolicy))
+	require.False(t, IsISMPolicy([]byte(ilmPolicy)))
+
+	policy := gjson.GetBytes(ismPolicy, "policy")
+	require.Equal(t, "hot", policy.Get("default_state").String())
+	require.Equal(t, "50GB", policy.Get("states.0.actions.0.rollover.min_size").String())
+	require.Equal(t, int64(50000000), policy.Get("states.0.actions.0.rollover.min_doc_count").Int())
+	require.Equal(t, "delete", policy.Get("states.0.transitions.0.state_name").String())
+	require.Equal(t, "30d", policy.Get("states.0.transitions.0.conditions.min_index_age").String())
+	require.True(t, policy.Get("states.1.actions.0.delete").Exists())
+	require.Equal(t, int64(0), policy.Get("states.1.transitions.#").Int())
+	require.Equal(t, "blocks-*", policy.Get("ism_template.0.index_patterns.0").String())
+
+	_, err = ConvertToISMPolicy([]byte(`{"policy":{"phases":{"hot":{"actions":{"searchable_snapshot":{}}}}}}`), "")
+	require.Error(t, err)
+}
+
+func TestConvertToOpenSearchTemplate(t *testing.T) {
+	t.Parallel()
+
+	template := `{"index_patterns":["blocks-*"],"template":{"settings":{"index.lifecycle.name":"blocks-policy","index.lifecycle.rollover_alias":"blocks","number_of_shards":5}}}`
+	converted, err := ConvertToOpenSearchTemplate([]byte(template))
+	require.NoError(t, err)
+
+	settings := gjson.GetBytes(converted, "template.settings")
+	require.False(t, settings.Get(escapePath("index.lifecycle.name")).Exists())
+	require.False(t, settings.Get(escapePath("index.lifecycle.rollover_alias")).Exists())
+	require.Equal(t, "blocks", settings.Get(escapePath(ismRolloverAlias)).String())
+	require.Equal(t, int64(5), settings.Get("number_of_shards").Int())
+}
+
+func TestNormalizeISMPolicy(t *testing.T) {
+	t.Parallel()
+
+	policy := object{
+		"policy_id":          "blocks-policy",
+		"last_updated_time":  1,
+		"schema_version":     17,
+		"error_notification": nil,
+		"default_state":      "hot",
+		"states": []interface{}{
+			object{"actions": []interface{}{
+				object{
+					"retry":    object{"count": 3, "backoff": "exponential", "delay": "1m"},
+					"rollover": object{"min_size": "50GB", "copy_alias": false},
+				},
+			}},
+		},
+	}
+	normalizeISMPolicy(policy)
+
+	require.Equal(t, object{
+		"default_state": "hot",
+		"states": []interface{}{
+			object{"actions": []interface{}{
+				object{"rollover": object{"min_size": "50GB"}},
+			}},
+		},
+	}, policy)
+}
+
+func TestEsClient_OpenSearchPolicies(t *testing.T) {
+	t.Parallel()
+
+	mut := sync.Mutex{}
+	requests := make(map[string][]byte)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+		mut.Lock()
+		requests[r.Method+" "+r.URL.RequestURI()] = body
+		mut.Unlock()
+
+		switch {
+		case r.URL.Path == "/":
+			_, _ = w.Write([]byte(`{"version":{"distribution":"opensearch","number":"2.11.0"}}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/_plugins/_ism/policies/blocks-policy":
+			_, _ = w.Write([]byte(`{"_id":"blocks-policy","_seq_no":7,"_primary_term":2,"policy":{"policy_id":"blocks-policy","schema_version":17,"default_state":"hot","states":[]}}`))
+		case r.Method == http.MethodGet:
+			w.WriteHeader(http.StatusNotFound)
+		case r.URL.Path == "/_plugins/_ism/add/blocks-000001":
+			_, _ = w.Write([]byte(`{"updated_indices":0,"failures":true,"failed_indices":[{"index_name":"blocks-000001","reason":"This index is already being managed"}]}`))
+		default:
+			_, _ = w.Write([]byte(`{}`))
+		}
+	}))
+	defer server.Close()
+
+	client := createClient(t, server.URL, config.BulkRetryConfig{})
+	require.Equal(t, BackendOpenSearch, client.Backend())
+
+	policy, err := client.GetPolicy("blocks-policy")
+	require.NoError(t, err)
+	require.JSONEq(t, `{"policy":{"default_state":"hot","states":[]}}`, string(policy))
+
+	policy, err = client.GetPolicy("rounds-policy")
+	require.NoError(t, err)
+	require.Nil(t, policy)
+
+	err = client.PutPolicy("blocks-policy", bytes.NewBufferString(ilmPolicy))
+	require.NoError(t, err)
+	updateBody, found := requests["PUT /_plugins/_ism/policies/blocks-policy?if_seq_no=7&if_primary_term=2"]
+	require.True(t, found)
+	require.True(t, IsISMPolicy(updateBody))
+	require.Equal(t, "blocks-*", gjson.GetBytes(updateBody, "policy.ism_template.0.index_patterns.0").String())
+
+	err = client.PutPolicy("rounds-policy", bytes.NewBufferString(ilmPolicy))
+	require.NoError(t, err)
+	_, found = requests["PUT /_plugins/_ism/policies/rounds-policy"]
+	require.True(t, found)
+
+	err = client.AttachPolicy("blocks-000001", "blocks-policy")
+	require.NoError(t, err)
+}
@@ -0,0 +1,47 @@
+package elastic
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/elastic/go-elasticsearch/v7/esapi"
+	"github.com/tidwall/gjson"
+)
+
+// StartReindexTask submits a _reindex request that runs in the background on the cluster and returns the id of its
+// task. The slices are not set if empty, as a reindex from a remote cluster cannot be sliced
+func (esc *esClient) StartReindexTask(body []byte, slices string) (string, error) {
+	options := []func(*esapi.ReindexRequest){
+		esc.client.Reindex.WithWaitForCompletion(false),
+	}
+	if slices != "" {
+		options = append(options, esc.client.Reindex.WithSlices(slices))
+	}
+
+	res, err := esc.client.Reindex(bytes.NewReader(body), options...)
+	if err != nil {
+		return "", err
+	}
+
+	respBytes, err := getBytesFromResponse(res)
+	if err != nil {
+		return "", err
+	}
+
+	taskID := gjson.GetBytes(respBytes, "task").String()
+	if taskID == "" {
+		return "", fmt.Errorf("the reindex response does not contain a task id: %s", respBytes)
+	}
+
+	return taskID, nil
+}
+
+// GetTask returns the status of the provided task, as returned by the Tasks API
+func (esc *esClient) GetTask(taskID string) ([]byte, error) {
+	res, err := esc.client.Tasks.Get(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	return getBytesFromResponse(res)
+}
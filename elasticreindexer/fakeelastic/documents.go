@@ -0,0 +1,960 @@
+package fakeelastic
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const defaultSearchSize = 10
+
+type document struct {
+	id     string
+	seq    uint64
+	source json.RawMessage
+}
+
+type searchHit struct {
+	index      string
+	doc        *document
+	sortValues []gjson.Result
+}
+
+type sortField struct {
+	field      string
+	descending bool
+}
+
+type scrollCursor struct {
+	hits []object
+	size int
+}
+
+func (s *Server) newID(prefix string) string {
+	s.nextID++
+
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+// putDocument stores the provided source, returning true if a document with the same id was replaced
+func (s *Server) putDocument(idx *index, id string, source json.RawMessage) bool {
+	s.nextID++
+	existing, found := idx.docs[id]
+	seq := s.nextID
+	if found {
+		seq = existing.seq
+	}
+
+	idx.docs[id] = &document{
+		id:     id,
+		seq:    seq,
+		source: append(json.RawMessage{}, source...),
+	}
+
+	return found
+}
+
+func (s *Server) bulk(defaultIndex string, body []byte) (int, interface{}) {
+	lines := make([][]byte, 0)
+	for _, line := range bytes.Split(body, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) > 0 {
+			lines = append(lines, line)
+		}
+	}
+
+	items := make([]object, 0)
+	hasErrors := false
+	for i := 0; i < len(lines); i++ {
+		var action map[string]struct {
+			Index string `json:"_index"`
+			ID    string `json:"_id"`
+		}
+		err := json.Unmarshal(lines[i], &action)
+		if err != nil || len(action) != 1 {
+			return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "illegal_argument_exception", "malformed action/metadata line")
+		}
+
+		for actionType, metadata := range action {
+			var source []byte
+			if actionType != "delete" {
+				if i+1 >= len(lines) {
+					return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "illegal_argument_exception", "the source of the last action is missing")
+				}
+				i++
+				source = lines[i]
+			}
+
+			indexName := metadata.Index
+			if indexName == "" {
+				indexName = defaultIndex
+			}
+
+			item := s.bulkItem(actionType, indexName, metadata.ID, source)
+			if _, failed := item["error"]; failed {
+				hasErrors = true
+			}
+			items = append(items, object{actionType: item})
+		}
+	}
+
+	return http.StatusOK, object{
+		"took":   1,
+		"errors": hasErrors,
+		"items":  items,
+	}
+}
+
+func (s *Server) bulkItem(actionType string, indexName string, id string, source []byte) object {
+	item := object{
+		"_index": indexName,
+		"_id":    id,
+	}
+	if indexName == "" {
+		return withItemError(item, http.StatusBadRequest, "action_request_validation_exception", "index is missing")
+	}
+
+	idx, err := s.writeIndexOf(indexName)
+	if err != nil {
+		return withItemError(item, http.StatusBadRequest, "illegal_argument_exception", err.Error())
+	}
+	item["_index"] = idx.name
+	if source != nil && !json.Valid(source) {
+		return withItemError(item, http.StatusBadRequest, "mapper_parsing_exception", "failed to parse")
+	}
+
+	existing, found := idx.docs[id]
+	switch actionType {
+	case "index", "create":
+		if id == "" {
+			id = s.newID("generated")
+			item["_id"] = id
+		}
+		if actionType == "create" && found {
+			return withItemError(item, http.StatusConflict, "version_conflict_engine_exception",
+				fmt.Sprintf("[%s]: version conflict, document already exists", id))
+		}
+		return withItemResult(item, s.putDocument(idx, id, source))
+	case "update":
+		request := gjson.ParseBytes(source)
+		if !found {
+			if !request.Get("doc_as_upsert").Bool() {
+				return withItemError(item, http.StatusNotFound, "document_missing_exception", fmt.Sprintf("[%s]: document missing", id))
+			}
+			return withItemResult(item, s.putDocument(idx, id, json.RawMessage(request.Get("doc").Raw)))
+		}
+
+		updated := object{}
+		_ = json.Unmarshal(existing.source, &updated)
+		_ = json.Unmarshal([]byte(request.Get("doc").Raw), &updated)
+		updatedSource, _ := json.Marshal(updated)
+		return withItemResult(item, s.putDocument(idx, id, updatedSource))
+	case "delete":
+		if !found {
+			item["result"] = "not_found"
+			item["status"] = http.StatusNotFound
+			return item
+		}
+		delete(idx.docs, id)
+		item["result"] = "deleted"
+		item["status"] = http.StatusOK
+		return item
+	}
+
+	return withItemError(item, http.StatusBadRequest, "illegal_argument_exception", "unsupported bulk action "+actionType)
+}
+
+func withItemResult(item object, replaced bool) object {
+	item["result"] = "created"
+	item["status"] = http.StatusCreated
+	if replaced {
+		item["result"] = "updated"
+		item["status"] = http.StatusOK
+	}
+
+	return item
+}
+
+func withItemError(item object, status int, errorType string, reason string) object {
+	item["status"] = status
+	item["error"] = object{
+		"type":   errorType,
+		"reason": reason,
+	}
+
+	return item
+}
+
+func (s *Server) count(indexExpression string, body []byte) (int, interface{}) {
+	if !s.searchable(indexExpression) {
+		return indexNotFound(indexExpression)
+	}
+
+	hits, err := s.findHits(indexExpression, gjson.ParseBytes(body))
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parsing_exception", err.Error())
+	}
+
+	return http.StatusOK, object{
+		"count":   len(hits),
+		"_shards": object{"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+	}
+}
+
+// searchable returns false for an expression without wildcards that does not match any index or alias, case when
+// Elasticsearch returns an index_not_found_exception
+func (s *Server) searchable(indexExpression string) bool {
+	if indexExpression == "" || strings.Contains(indexExpression, "*") {
+		return true
+	}
+
+	return len(s.resolve(indexExpression)) > 0
+}
+
+func (s *Server) search(indexExpression string, params url.Values, body []byte) (int, interface{}) {
+	request := gjson.ParseBytes(body)
+	pitID := request.Get("pit.id").String()
+	if pitID != "" {
+		pitIndexExpression, found := s.pits[pitID]
+		if !found {
+			return http.StatusNotFound, errorResponse(http.StatusNotFound, "search_context_missing_exception", "No search context found for id ["+pitID+"]")
+		}
+		indexExpression = pitIndexExpression
+	}
+	if !s.searchable(indexExpression) {
+		return indexNotFound(indexExpression)
+	}
+
+	hits, err := s.findHits(indexExpression, request)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parsing_exception", err.Error())
+	}
+	aggregations, err := computeAggregations(request, hits)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parsing_exception", err.Error())
+	}
+
+	totalHits := len(hits)
+	hits = searchAfter(hits, request)
+	from := int(request.Get("from").Int())
+	if from > len(hits) {
+		from = len(hits)
+	}
+	hits = hits[from:]
+
+	size := defaultSearchSize
+	if params.Get("size") != "" {
+		size, _ = strconv.Atoi(params.Get("size"))
+	}
+	if request.Get("size").Exists() {
+		size = int(request.Get("size").Int())
+	}
+
+	encodedHits := make([]object, 0, len(hits))
+	for _, hit := range hits {
+		encodedHits = append(encodedHits, encodeHit(hit, request.Get("_source")))
+	}
+
+	response := object{
+		"took":      1,
+		"timed_out": false,
+		"_shards":   object{"total": 1, "successful": 1, "skipped": 0, "failed": 0},
+	}
+	if aggregations != nil {
+		response["aggregations"] = aggregations
+	}
+	if pitID != "" {
+		response["pit_id"] = pitID
+	}
+	if params.Get("scroll") != "" {
+		scrollID := s.newID("scroll")
+		s.scrolls[scrollID] = &scrollCursor{hits: encodedHits, size: size}
+		response["_scroll_id"] = scrollID
+		response["hits"] = hitsResponse(totalHits, s.scrolls[scrollID].nextPage())
+		return http.StatusOK, response
+	}
+
+	if size < len(encodedHits) {
+		encodedHits = encodedHits[:size]
+	}
+	response["hits"] = hitsResponse(totalHits, encodedHits)
+
+	return http.StatusOK, response
+}
+
+func hitsResponse(totalHits int, hits []object) object {
+	return object{
+		"total": object{
+			"value":    totalHits,
+			"relation": "eq",
+		},
+		"max_score": 1,
+		"hits":      hits,
+	}
+}
+
+func (sc *scrollCursor) nextPage() []object {
+	size := sc.size
+	if size > len(sc.hits) {
+		size = len(sc.hits)
+	}
+
+	page := sc.hits[:size]
+	sc.hits = sc.hits[size:]
+
+	return page
+}
+
+func (s *Server) nextScrollPage(scrollID string) (int, interface{}) {
+	cursor, found := s.scrolls[scrollID]
+	if !found {
+		return http.StatusNotFound, errorResponse(http.StatusNotFound, "search_context_missing_exception", "No search context found for id ["+scrollID+"]")
+	}
+
+	return http.StatusOK, object{
+		"_scroll_id": scrollID,
+		"took":       1,
+		"timed_out":  false,
+		"hits":       hitsResponse(len(cursor.hits), cursor.nextPage()),
+	}
+}
+
+func (s *Server) clearScroll(scrollID string) (int, interface{}) {
+	numFreed := 0
+	for _, id := range strings.Split(scrollID, ",") {
+		if id == "_all" {
+			numFreed += len(s.scrolls)
+			s.scrolls = make(map[string]*scrollCursor)
+			continue
+		}
+
+		_, found := s.scrolls[id]
+		if found {
+			delete(s.scrolls, id)
+			numFreed++
+		}
+	}
+
+	response := object{"succeeded": numFreed > 0, "num_freed": numFreed}
+	if numFreed == 0 {
+		return http.StatusNotFound, response
+	}
+
+	return http.StatusOK, response
+}
+
+func (s *Server) openPointInTime(indexExpression string) (int, interface{}) {
+	if !s.searchable(indexExpression) {
+		return indexNotFound(indexExpression)
+	}
+
+	pitID := s.newID("pit")
+	s.pits[pitID] = indexExpression
+
+	return http.StatusOK, object{"id": pitID}
+}
+
+func (s *Server) closePointInTime(body []byte) (int, interface{}) {
+	pitID := gjson.GetBytes(body, "id").String()
+	_, found := s.pits[pitID]
+	if !found {
+		return http.StatusNotFound, object{"succeeded": false, "num_freed": 0}
+	}
+
+	delete(s.pits, pitID)
+
+	return http.StatusOK, object{"succeeded": true, "num_freed": 1}
+}
+
+// findHits returns the documents matching the query of the provided search request, restricted to the requested
+// slice and sorted as requested. Without a sort, the documents are returned in the order they were indexed, or
+// shuffled for a random_score query
+func (s *Server) findHits(indexExpression string, request gjson.Result) ([]*searchHit, error) {
+	sortFields, err := parseSort(request.Get("sort"))
+	if err != nil {
+		return nil, err
+	}
+
+	slice := request.Get("slice")
+	sliceID, sliceMax := slice.Get("id").Uint(), slice.Get("max").Uint()
+	if slice.Exists() && (sliceMax == 0 || sliceID >= sliceMax) {
+		return nil, fmt.Errorf("invalid slice id %d for max %d", sliceID, sliceMax)
+	}
+
+	hits := make([]*searchHit, 0)
+	for _, idx := range s.resolve(indexExpression) {
+		for _, doc := range idx.docs {
+			if slice.Exists() && hashID(doc.id)%sliceMax != sliceID {
+				continue
+			}
+
+			matched, errMatch := matchQuery(request.Get("query"), doc)
+			if errMatch != nil {
+				return nil, errMatch
+			}
+			if matched {
+				hits = append(hits, newSearchHit(idx.name, doc, sortFields))
+			}
+		}
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].doc.seq < hits[j].doc.seq
+	})
+	if len(sortFields) > 0 {
+		sort.SliceStable(hits, func(i, j int) bool {
+			return compareSortValues(hits[i].sortValues, hits[j].sortValues, sortFields) < 0
+		})
+	}
+	if request.Get("query.function_score.random_score").Exists() {
+		rand.Shuffle(len(hits), func(i, j int) {
+			hits[i], hits[j] = hits[j], hits[i]
+		})
+	}
+
+	return hits, nil
+}
+
+func hashID(id string) uint64 {
+	hasher := fnv.New64a()
+	_, _ = hasher.Write([]byte(id))
+
+	return hasher.Sum64()
+}
+
+func parseSort(sortRequest gjson.Result) ([]*sortField, error) {
+	if !sortRequest.Exists() {
+		return nil, nil
+	}
+
+	elements := []gjson.Result{sortRequest}
+	if sortRequest.IsArray() {
+		elements = sortRequest.Array()
+	}
+
+	sortFields := make([]*sortField, 0, len(elements))
+	for _, element := range elements {
+		if element.Type == gjson.String {
+			sortFields = append(sortFields, &sortField{field: element.String()})
+			continue
+		}
+		if !element.IsObject() {
+			return nil, fmt.Errorf("unsupported sort %s", element.Raw)
+		}
+
+		for field, order := range element.Map() {
+			if order.IsObject() {
+				order = order.Get("order")
+			}
+			sortFields = append(sortFields, &sortField{
+				field:      field,
+				descending: order.String() == "desc",
+			})
+		}
+	}
+
+	return sortFields, nil
+}
+
+func newSearchHit(indexName string, doc *document, sortFields []*sortField) *searchHit {
+	sortValues := make([]gjson.Result, 0, len(sortFields))
+	for _, field := range sortFields {
+		switch field.field {
+		case "_shard_doc", "_doc":
+			sortValues = append(sortValues, gjson.Parse(strconv.FormatUint(doc.seq, 10)))
+		case "_id":
+			sortValues = append(sortValues, gjson.Parse(strconv.Quote(doc.id)))
+		case "_score":
+			sortValues = append(sortValues, gjson.Parse("1"))
+		default:
+			sortValues = append(sortValues, gjson.GetBytes(doc.source, field.field))
+		}
+	}
+
+	return &searchHit{
+		index:      indexName,
+		doc:        doc,
+		sortValues: sortValues,
+	}
+}
+
+// compareSortValues compares two lists of sort values. The missing values are sorted last, in both orders
+func compareSortValues(first []gjson.Result, second []gjson.Result, sortFields []*sortField) int {
+	for i, field := range sortFields {
+		if i >= len(first) || i >= len(second) {
+			return 0
+		}
+
+		firstExists, secondExists := first[i].Exists(), second[i].Exists()
+		switch {
+		case !firstExists && !secondExists:
+			continue
+		case !firstExists:
+			return 1
+		case !secondExists:
+			return -1
+		}
+
+		comparison := compareValues(first[i], second[i])
+		if field.descending {
+			comparison = -comparison
+		}
+		if comparison != 0 {
+			return comparison
+		}
+	}
+
+	return 0
+}
+
+// compareValues compares two values as numbers if both of them are numbers or numeric strings, otherwise as strings
+func compareValues(first gjson.Result, second gjson.Result) int {
+	firstNumber, firstIsNumber := toNumber(first)
+	secondNumber, secondIsNumber := toNumber(second)
+	if firstIsNumber && secondIsNumber {
+		switch {
+		case firstNumber < secondNumber:
+			return -1
+		case firstNumber > secondNumber:
+			return 1
+		}
+		return 0
+	}
+
+	return strings.Compare(first.String(), second.String())
+}
+
+func toNumber(value gjson.Result) (float64, bool) {
+	switch value.Type {
+	case gjson.Number:
+		return value.Num, true
+	case gjson.String:
+		number, err := strconv.ParseFloat(value.Str, 64)
+		return number, err == nil
+	}
+
+	return 0, false
+}
+
+func searchAfter(hits []*searchHit, request gjson.Result) []*searchHit {
+	after := request.Get("search_after")
+	if !after.Exists() {
+		return hits
+	}
+
+	sortFields, _ := parseSort(request.Get("sort"))
+	afterValues := after.Array()
+	for i, hit := range hits {
+		if compareSortValues(hit.sortValues, afterValues, sortFields) > 0 {
+			return hits[i:]
+		}
+	}
+
+	return nil
+}
+
+func encodeHit(hit *searchHit, sourceFilter gjson.Result) object {
+	encoded := object{
+		"_index": hit.index,
+		"_type":  "_doc",
+		"_id":    hit.doc.id,
+		"_score": 1,
+	}
+
+	switch {
+	case !sourceFilter.Exists() || sourceFilter.Type == gjson.True:
+		encoded["_source"] = hit.doc.source
+	case sourceFilter.Type == gjson.String || sourceFilter.IsArray():
+		encoded["_source"] = filterSource(hit.doc.source, sourceFilter)
+	}
+
+	if len(hit.sortValues) > 0 {
+		sortValues := make([]json.RawMessage, 0, len(hit.sortValues))
+		for _, value := range hit.sortValues {
+			raw := value.Raw
+			if !value.Exists() {
+				raw = "null"
+			}
+			sortValues = append(sortValues, json.RawMessage(raw))
+		}
+		encoded["sort"] = sortValues
+		encoded["_score"] = nil
+	}
+
+	return encoded
+}
+
+// filterSource keeps only the provided fields of the source, the dotted fields being kept in their objects
+func filterSource(source json.RawMessage, fields gjson.Result) object {
+	filtered := object{}
+	includedFields := []gjson.Result{fields}
+	if fields.IsArray() {
+		includedFields = fields.Array()
+	}
+
+	for _, field := range includedFields {
+		value := gjson.GetBytes(source, field.String())
+		if !value.Exists() {
+			continue
+		}
+
+		parent := filtered
+		pathParts := strings.Split(field.String(), ".")
+		for _, part := range pathParts[:len(pathParts)-1] {
+			child, ok := parent[part].(object)
+			if !ok {
+				child = object{}
+				parent[part] = child
+			}
+			parent = child
+		}
+		parent[pathParts[len(pathParts)-1]] = json.RawMessage(value.Raw)
+	}
+
+	return filtered
+}
+
+// computeAggregations computes the terms and composite aggregations of the provided request over all the matched
+// documents
+func computeAggregations(request gjson.Result, hits []*searchHit) (object, error) {
+	aggregationsRequest := request.Get("aggs")
+	if !aggregationsRequest.Exists() {
+		aggregationsRequest = request.Get("aggregations")
+	}
+	if !aggregationsRequest.Exists() {
+		return nil, nil
+	}
+
+	aggregations := object{}
+	for name, aggregation := range aggregationsRequest.Map() {
+		terms := aggregation.Get("terms")
+		if terms.Exists() {
+			aggregations[name] = termsAggregation(terms, hits)
+			continue
+		}
+
+		composite := aggregation.Get("composite")
+		if !composite.Exists() {
+			return nil, fmt.Errorf("unsupported aggregation %s", aggregation.Raw)
+		}
+
+		result, err := compositeAggregation(composite, hits)
+		if err != nil {
+			return nil, err
+		}
+		aggregations[name] = result
+	}
+
+	return aggregations, nil
+}
+
+func termsAggregation(terms gjson.Result, hits []*searchHit) object {
+	counts := make(map[string]int)
+	for _, hit := range hits {
+		for _, value := range fieldValues(hit.doc, terms.Get("field").String()) {
+			counts[value.Raw]++
+		}
+	}
+
+	keys := make([]string, 0, len(counts))
+	for key := range counts {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if counts[keys[i]] != counts[keys[j]] {
+			return counts[keys[i]] > counts[keys[j]]
+		}
+		return keys[i] < keys[j]
+	})
+
+	size := defaultSearchSize
+	if terms.Get("size").Exists() {
+		size = int(terms.Get("size").Int())
+	}
+	sumOtherDocCount := 0
+	buckets := make([]object, 0, len(keys))
+	for i, key := range keys {
+		if i >= size {
+			sumOtherDocCount += counts[key]
+			continue
+		}
+
+		buckets = append(buckets, object{
+			"key":       json.RawMessage(key),
+			"doc_count": counts[key],
+		})
+	}
+
+	return object{
+		"doc_count_error_upper_bound": 0,
+		"sum_other_doc_count":         sumOtherDocCount,
+		"buckets":                     buckets,
+	}
+}
+
+// compositeAggregation computes a composite aggregation with a single terms source. The buckets are sorted by key,
+// ascending, and are paginated with the after key
+func compositeAggregation(composite gjson.Result, hits []*searchHit) (object, error) {
+	sources := composite.Get("sources").Array()
+	if len(sources) != 1 {
+		return nil, fmt.Errorf("composite aggregations support exactly one source: %s", composite.Raw)
+	}
+
+	var sourceName string
+	var terms gjson.Result
+	for name, source := range sources[0].Map() {
+		sourceName, terms = name, source.Get("terms")
+	}
+	if !terms.Exists() {
+		return nil, fmt.Errorf("unsupported composite source %s", sources[0].Raw)
+	}
+
+	counts := make(map[string]int)
+	values := make(map[string]gjson.Result)
+	for _, hit := range hits {
+		for _, value := range fieldValues(hit.doc, terms.Get("field").String()) {
+			counts[value.Raw]++
+			values[value.Raw] = value
+		}
+	}
+
+	keys := make([]string, 0, len(counts))
+	after := composite.Get("after." + sourceName)
+	for key := range counts {
+		if after.Exists() && compareValues(values[key], after) <= 0 {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return compareValues(values[keys[i]], values[keys[j]]) < 0
+	})
+
+	size := defaultSearchSize
+	if composite.Get("size").Exists() {
+		size = int(composite.Get("size").Int())
+	}
+	if len(keys) > size {
+		keys = keys[:size]
+	}
+
+	buckets := make([]object, 0, len(keys))
+	for _, key := range keys {
+		buckets = append(buckets, object{
+			"key":       object{sourceName: json.RawMessage(key)},
+			"doc_count": counts[key],
+		})
+	}
+
+	result := object{
+		"buckets": buckets,
+	}
+	if len(keys) > 0 {
+		result["after_key"] = object{sourceName: json.RawMessage(keys[len(keys)-1])}
+	}
+
+	return result, nil
+}
+
+func fieldValues(doc *document, field string) []gjson.Result {
+	if field == "_id" {
+		return []gjson.Result{gjson.Parse(strconv.Quote(doc.id))}
+	}
+
+	value := gjson.GetBytes(doc.source, field)
+	if !value.Exists() || value.Type == gjson.Null {
+		return nil
+	}
+	if value.IsArray() {
+		return value.Array()
+	}
+
+	return []gjson.Result{value}
+}
+
+// matchQuery returns true if the provided document matches the query. The values are compared exactly, as the fields
+// are not analyzed
+func matchQuery(query gjson.Result, doc *document) (bool, error) {
+	if !query.Exists() {
+		return true, nil
+	}
+
+	queryParts := query.Map()
+	if len(queryParts) != 1 {
+		return false, fmt.Errorf("a query should have exactly one clause: %s", query.Raw)
+	}
+
+	for queryType, clause := range queryParts {
+		switch queryType {
+		case "match_all":
+			return true, nil
+		case "match_none":
+			return false, nil
+		case "term", "match":
+			field, value := singleField(clause)
+			if value.IsObject() {
+				value = firstExisting(value, "value", "query")
+			}
+			return containsValue(fieldValues(doc, field), []gjson.Result{value}), nil
+		case "terms":
+			field, values := singleField(clause)
+			return containsValue(fieldValues(doc, field), values.Array()), nil
+		case "ids":
+			return containsValue(fieldValues(doc, "_id"), clause.Get("values").Array()), nil
+		case "exists":
+			return len(fieldValues(doc, clause.Get("field").String())) > 0, nil
+		case "range":
+			field, bounds := singleField(clause)
+			return matchRange(fieldValues(doc, field), bounds), nil
+		case "bool":
+			return matchBool(clause, doc)
+		case "function_score":
+			return matchQuery(clause.Get("query"), doc)
+		case "constant_score":
+			return matchQuery(clause.Get("filter"), doc)
+		}
+
+		return false, fmt.Errorf("unsupported query [%s]", queryType)
+	}
+
+	return false, nil
+}
+
+func singleField(clause gjson.Result) (string, gjson.Result) {
+	for field, value := range clause.Map() {
+		return field, value
+	}
+
+	return "", gjson.Result{}
+}
+
+func firstExisting(value gjson.Result, keys ...string) gjson.Result {
+	for _, key := range keys {
+		if value.Get(key).Exists() {
+			return value.Get(key)
+		}
+	}
+
+	return gjson.Result{}
+}
+
+func containsValue(values []gjson.Result, expected []gjson.Result) bool {
+	for _, value := range values {
+		for _, expectedValue := range expected {
+			if compareValues(value, expectedValue) == 0 {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+func matchRange(values []gjson.Result, bounds gjson.Result) bool {
+	for _, value := range values {
+		if inRange(value, bounds) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func inRange(value gjson.Result, bounds gjson.Result) bool {
+	for operator, bound := range bounds.Map() {
+		comparison := compareValues(value, bound)
+		switch operator {
+		case "gte":
+			if comparison < 0 {
+				return false
+			}
+		case "gt":
+			if comparison <= 0 {
+				return false
+			}
+		case "lte":
+			if comparison > 0 {
+				return false
+			}
+		case "lt":
+			if comparison >= 0 {
+				return false
+			}
+		}
+	}
+
+	return true
+}
+
+func matchBool(clause gjson.Result, doc *document) (bool, error) {
+	for _, occurrence := range []string{"must", "filter"} {
+		matched, err := matchClauses(clause.Get(occurrence), doc, true)
+		if err != nil || !matched {
+			return false, err
+		}
+	}
+
+	matchedAny, err := matchClauses(clause.Get("must_not"), doc, false)
+	if err != nil || matchedAny {
+		return false, err
+	}
+
+	should := clause.Get("should")
+	if !should.Exists() || len(asArray(should)) == 0 {
+		return true, nil
+	}
+	minimumShouldMatch := int64(0)
+	if !clause.Get("must").Exists() && !clause.Get("filter").Exists() {
+		minimumShouldMatch = 1
+	}
+	if clause.Get("minimum_should_match").Exists() {
+		minimumShouldMatch = clause.Get("minimum_should_match").Int()
+	}
+
+	numMatched := int64(0)
+	for _, query := range asArray(should) {
+		matched, errMatch := matchQuery(query, doc)
+		if errMatch != nil {
+			return false, errMatch
+		}
+		if matched {
+			numMatched++
+		}
+	}
+
+	return numMatched >= minimumShouldMatch, nil
+}
+
+// matchClauses returns true if all the provided clauses match, when all is set, or if any of them matches otherwise
+func matchClauses(clauses gjson.Result, doc *document, all bool) (bool, error) {
+	for _, query := range asArray(clauses) {
+		matched, err := matchQuery(query, doc)
+		if err != nil {
+			return false, err
+		}
+		if matched != all {
+			return matched, nil
+		}
+	}
+
+	return all, nil
+}
+
+func asArray(value gjson.Result) []gjson.Result {
+	if !value.Exists() {
+		return nil
+	}
+	if value.IsArray() {
+		return value.Array()
+	}
+
+	return []gjson.Result{value}
+}
@@ -0,0 +1,155 @@
+package fakeelastic
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// CreateIndex creates an index with the provided body, which can hold settings, mappings and aliases, as the create
+// index API does. An empty body creates the index from the matching index template, if any
+func (s *Server) CreateIndex(name string, body string) error {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	request := indexBody{}
+	if body != "" {
+		err := json.Unmarshal([]byte(body), &request)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err := s.addIndex(name, request)
+
+	return err
+}
+
+// PutIndexTemplate stores the provided composable index template
+func (s *Server) PutIndexTemplate(name string, body string) error {
+	if !json.Valid([]byte(body)) {
+		return errors.New("invalid index template body")
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	s.templates[name] = json.RawMessage(body)
+
+	return nil
+}
+
+// IndexDocument stores the provided document in the index, or in the write index of the alias, with the provided name.
+// The index is created if it does not exist
+func (s *Server) IndexDocument(indexName string, id string, source string) error {
+	if !json.Valid([]byte(source)) {
+		return fmt.Errorf("invalid source for document %s", id)
+	}
+
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	idx, err := s.writeIndexOf(indexName)
+	if err != nil {
+		return err
+	}
+	s.putDocument(idx, id, json.RawMessage(source))
+
+	return nil
+}
+
+// Documents returns the sources of the documents from the indices matching the provided expression, by id
+func (s *Server) Documents(indexExpression string) map[string]string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	documents := make(map[string]string)
+	for _, idx := range s.resolve(indexExpression) {
+		for id, doc := range idx.docs {
+			documents[id] = string(doc.source)
+		}
+	}
+
+	return documents
+}
+
+// Count returns the number of documents from the indices matching the provided expression
+func (s *Server) Count(indexExpression string) int {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	count := 0
+	for _, idx := range s.resolve(indexExpression) {
+		count += len(idx.docs)
+	}
+
+	return count
+}
+
+// Indices returns the names of all the indices, sorted
+func (s *Server) Indices() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	names := make([]string, 0, len(s.indices))
+	for name := range s.indices {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
+// Mapping returns the mappings of the provided concrete index, or an empty string if the index does not exist
+func (s *Server) Mapping(indexName string) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	idx, found := s.indices[indexName]
+	if !found {
+		return ""
+	}
+
+	mappings, _ := json.Marshal(idx.mappings)
+
+	return string(mappings)
+}
+
+// WriteIndex returns the index where the documents written through the provided alias are stored, or an empty string
+// if the alias does not exist or has no write index
+func (s *Server) WriteIndex(alias string) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	if len(s.aliasIndices(alias)) == 0 {
+		return ""
+	}
+	idx, err := s.writeIndexOf(alias)
+	if err != nil {
+		return ""
+	}
+
+	return idx.name
+}
+
+// IndexTemplate returns the body of the provided composable index template, or an empty string if it does not exist
+func (s *Server) IndexTemplate(name string) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return string(s.templates[name])
+}
+
+// Policy returns the provided ILM policy, without the "policy" wrapper, or an empty string if it does not exist
+func (s *Server) Policy(name string) string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	existing, found := s.policies[name]
+	if !found {
+		return ""
+	}
+
+	return string(existing.body)
+}
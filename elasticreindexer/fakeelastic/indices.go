@@ -0,0 +1,461 @@
+package fakeelastic
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	rolloverSuffixWidth = 6
+	indexSeparator      = ","
+)
+
+type index struct {
+	name     string
+	settings json.RawMessage
+	mappings object
+	aliases  map[string]*aliasProperties
+	docs     map[string]*document
+}
+
+type aliasProperties struct {
+	IsWriteIndex *bool `json:"is_write_index,omitempty"`
+}
+
+type indexBody struct {
+	Settings json.RawMessage             `json:"settings"`
+	Mappings object                      `json:"mappings"`
+	Aliases  map[string]*aliasProperties `json:"aliases"`
+}
+
+func newIndex(name string) *index {
+	return &index{
+		name:     name,
+		mappings: object{},
+		aliases:  make(map[string]*aliasProperties),
+		docs:     make(map[string]*document),
+	}
+}
+
+func (idx *index) isWriteIndexOf(alias string) bool {
+	properties, found := idx.aliases[alias]
+
+	return found && properties.IsWriteIndex != nil && *properties.IsWriteIndex
+}
+
+func matchesPattern(pattern string, name string) bool {
+	matched, err := path.Match(pattern, name)
+
+	return err == nil && matched
+}
+
+// resolve returns the concrete indices behind the provided expression: comma separated index names, aliases or
+// wildcard patterns, sorted by name
+func (s *Server) resolve(indexExpression string) []*index {
+	resolved := make(map[string]*index)
+	for _, name := range strings.Split(indexExpression, indexSeparator) {
+		if name == "" || name == "_all" {
+			name = "*"
+		}
+
+		for indexName, idx := range s.indices {
+			if matchesPattern(name, indexName) {
+				resolved[indexName] = idx
+				continue
+			}
+			for alias := range idx.aliases {
+				if matchesPattern(name, alias) {
+					resolved[indexName] = idx
+				}
+			}
+		}
+	}
+
+	indices := make([]*index, 0, len(resolved))
+	for _, idx := range resolved {
+		indices = append(indices, idx)
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i].name < indices[j].name
+	})
+
+	return indices
+}
+
+// writeIndexOf returns the index where the documents written with the provided name are stored: the index itself, the
+// write index of the alias, or the only index behind the alias
+func (s *Server) writeIndexOf(name string) (*index, error) {
+	idx, found := s.indices[name]
+	if found {
+		return idx, nil
+	}
+
+	backingIndices := s.aliasIndices(name)
+	for _, backingIndex := range backingIndices {
+		if backingIndex.isWriteIndexOf(name) {
+			return backingIndex, nil
+		}
+	}
+	if len(backingIndices) == 1 {
+		return backingIndices[0], nil
+	}
+	if len(backingIndices) > 1 {
+		return nil, fmt.Errorf("no write index is defined for alias [%s]", name)
+	}
+
+	// as Elasticsearch does, a missing index is created on the first write
+	return s.addIndex(name, indexBody{})
+}
+
+func (s *Server) aliasIndices(alias string) []*index {
+	indices := make([]*index, 0)
+	for _, idx := range s.indices {
+		_, found := idx.aliases[alias]
+		if found {
+			indices = append(indices, idx)
+		}
+	}
+	sort.Slice(indices, func(i, j int) bool {
+		return indices[i].name < indices[j].name
+	})
+
+	return indices
+}
+
+func (s *Server) nameExists(name string) bool {
+	_, found := s.indices[name]
+
+	return found || len(s.aliasIndices(name)) > 0
+}
+
+// addIndex creates an index with the settings, mappings and aliases of the matching index template, overwritten by
+// the ones from the provided body
+func (s *Server) addIndex(name string, body indexBody) (*index, error) {
+	if s.nameExists(name) {
+		return nil, fmt.Errorf("index [%s] already exists", name)
+	}
+
+	idx := newIndex(name)
+	template := s.matchingTemplate(name)
+	if template.Exists() {
+		idx.settings = json.RawMessage(template.Get("template.settings").Raw)
+		_ = json.Unmarshal([]byte(template.Get("template.mappings").Raw), &idx.mappings)
+		_ = json.Unmarshal([]byte(template.Get("template.aliases").Raw), &idx.aliases)
+	}
+
+	if len(body.Settings) > 0 {
+		idx.settings = body.Settings
+	}
+	if body.Mappings != nil {
+		mergeMappings(idx.mappings, body.Mappings)
+	}
+	for alias, properties := range body.Aliases {
+		if properties == nil {
+			properties = &aliasProperties{}
+		}
+		idx.aliases[alias] = properties
+	}
+
+	s.indices[name] = idx
+
+	return idx, nil
+}
+
+// matchingTemplate returns the composable index template with the highest priority whose patterns match the provided
+// index name
+func (s *Server) matchingTemplate(indexName string) gjson.Result {
+	var bestTemplate gjson.Result
+	bestPriority := int64(-1)
+	for _, templateName := range sortedKeys(s.templates) {
+		template := gjson.ParseBytes(s.templates[templateName])
+		priority := template.Get("priority").Int()
+		if priority <= bestPriority {
+			continue
+		}
+
+		for _, pattern := range template.Get("index_patterns").Array() {
+			if matchesPattern(pattern.String(), indexName) {
+				bestTemplate = template
+				bestPriority = priority
+				break
+			}
+		}
+	}
+
+	return bestTemplate
+}
+
+// simulateIndex returns the template section of the composable index template that a new index with the provided
+// name would get
+func (s *Server) simulateIndex(indexName string) (int, interface{}) {
+	template := object{"settings": object{}, "aliases": object{}}
+	matchingTemplate := s.matchingTemplate(indexName)
+	if matchingTemplate.Get("template").IsObject() {
+		template = object{}
+		matchingTemplate.Get("template").ForEach(func(key, value gjson.Result) bool {
+			template[key.String()] = json.RawMessage(value.Raw)
+			return true
+		})
+	}
+
+	return http.StatusOK, object{"template": template, "overlapping": []object{}}
+}
+
+func (s *Server) createIndex(name string, body []byte) (int, interface{}) {
+	request := indexBody{}
+	if len(body) > 0 {
+		err := json.Unmarshal(body, &request)
+		if err != nil {
+			return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parse_exception", err.Error())
+		}
+	}
+
+	_, err := s.addIndex(name, request)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "resource_already_exists_exception", err.Error())
+	}
+
+	return http.StatusOK, object{
+		"acknowledged":        true,
+		"shards_acknowledged": true,
+		"index":               name,
+	}
+}
+
+func (s *Server) indexExists(indexExpression string) (int, interface{}) {
+	if len(s.resolve(indexExpression)) == 0 {
+		return http.StatusNotFound, nil
+	}
+
+	return http.StatusOK, nil
+}
+
+func (s *Server) deleteIndex(indexExpression string) (int, interface{}) {
+	indices := s.resolve(indexExpression)
+	if len(indices) == 0 {
+		return indexNotFound(indexExpression)
+	}
+
+	for _, idx := range indices {
+		delete(s.indices, idx.name)
+	}
+
+	return http.StatusOK, object{"acknowledged": true}
+}
+
+func (s *Server) getMapping(indexExpression string) (int, interface{}) {
+	indices := s.resolve(indexExpression)
+	if len(indices) == 0 {
+		return indexNotFound(indexExpression)
+	}
+
+	response := object{}
+	for _, idx := range indices {
+		response[idx.name] = object{"mappings": idx.mappings}
+	}
+
+	return http.StatusOK, response
+}
+
+func (s *Server) putMapping(indexExpression string, body []byte) (int, interface{}) {
+	indices := s.resolve(indexExpression)
+	if len(indices) == 0 {
+		return indexNotFound(indexExpression)
+	}
+
+	mappings := object{}
+	err := json.Unmarshal(body, &mappings)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parse_exception", err.Error())
+	}
+
+	for _, idx := range indices {
+		mergeMappings(idx.mappings, mappings)
+	}
+
+	return http.StatusOK, object{"acknowledged": true}
+}
+
+// mergeMappings adds the provided mappings to the existing ones, the properties of the objects being merged recursively
+func mergeMappings(existing object, mappings object) {
+	for key, value := range mappings {
+		existingValue, existingIsObject := existing[key].(object)
+		valueObject, valueIsObject := value.(object)
+		if existingIsObject && valueIsObject {
+			mergeMappings(existingValue, valueObject)
+			continue
+		}
+
+		existing[key] = value
+	}
+}
+
+func (s *Server) putAlias(indexExpression string, alias string, body []byte) (int, interface{}) {
+	indices := s.resolve(indexExpression)
+	if len(indices) == 0 {
+		return indexNotFound(indexExpression)
+	}
+
+	properties := &aliasProperties{}
+	if len(body) > 0 {
+		err := json.Unmarshal(body, properties)
+		if err != nil {
+			return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parse_exception", err.Error())
+		}
+	}
+
+	for _, idx := range indices {
+		s.addAlias(idx, alias, properties)
+	}
+
+	return http.StatusOK, object{"acknowledged": true}
+}
+
+// addAlias adds the alias on the provided index. If the index becomes the write index, the other indices behind the
+// alias lose the flag, as only one write index is allowed
+func (s *Server) addAlias(idx *index, alias string, properties *aliasProperties) {
+	if properties.IsWriteIndex != nil && *properties.IsWriteIndex {
+		for _, other := range s.aliasIndices(alias) {
+			if other.isWriteIndexOf(alias) {
+				other.aliases[alias] = &aliasProperties{IsWriteIndex: boolPointer(false)}
+			}
+		}
+	}
+
+	idx.aliases[alias] = &aliasProperties{IsWriteIndex: properties.IsWriteIndex}
+}
+
+func (s *Server) updateAliases(body []byte) (int, interface{}) {
+	var request struct {
+		Actions []map[string]struct {
+			Index        string `json:"index"`
+			Alias        string `json:"alias"`
+			IsWriteIndex *bool  `json:"is_write_index"`
+		} `json:"actions"`
+	}
+	err := json.Unmarshal(body, &request)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parse_exception", err.Error())
+	}
+
+	for _, action := range request.Actions {
+		for actionType, parameters := range action {
+			indices := s.resolve(parameters.Index)
+			if len(indices) == 0 {
+				return indexNotFound(parameters.Index)
+			}
+
+			for _, idx := range indices {
+				switch actionType {
+				case "add":
+					s.addAlias(idx, parameters.Alias, &aliasProperties{IsWriteIndex: parameters.IsWriteIndex})
+				case "remove":
+					delete(idx.aliases, parameters.Alias)
+				default:
+					return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "illegal_argument_exception", "unsupported alias action "+actionType)
+				}
+			}
+		}
+	}
+
+	return http.StatusOK, object{"acknowledged": true}
+}
+
+func (s *Server) aliasExists(alias string) (int, interface{}) {
+	if len(s.aliasIndices(alias)) == 0 {
+		return http.StatusNotFound, nil
+	}
+
+	return http.StatusOK, nil
+}
+
+func (s *Server) getAlias(alias string) (int, interface{}) {
+	indices := s.aliasIndices(alias)
+	if len(indices) == 0 {
+		return http.StatusNotFound, object{
+			"error":  fmt.Sprintf("alias [%s] missing", alias),
+			"status": http.StatusNotFound,
+		}
+	}
+
+	response := object{}
+	for _, idx := range indices {
+		response[idx.name] = object{"aliases": object{alias: idx.aliases[alias]}}
+	}
+
+	return http.StatusOK, response
+}
+
+// rollover creates the next index behind the alias, named by incrementing the numeric suffix of the current write
+// index, and moves the write flag on it
+func (s *Server) rollover(alias string) (int, interface{}) {
+	if len(s.aliasIndices(alias)) == 0 {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "illegal_argument_exception",
+			fmt.Sprintf("rollover target [%s] does not exist", alias))
+	}
+	oldIndex, err := s.writeIndexOf(alias)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "illegal_argument_exception", err.Error())
+	}
+
+	newIndexName, err := nextGeneration(oldIndex.name)
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "illegal_argument_exception", err.Error())
+	}
+
+	newIndex, err := s.addIndex(newIndexName, indexBody{})
+	if err != nil {
+		return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "resource_already_exists_exception", err.Error())
+	}
+	s.addAlias(newIndex, alias, &aliasProperties{IsWriteIndex: boolPointer(true)})
+
+	return http.StatusOK, object{
+		"acknowledged":        true,
+		"shards_acknowledged": true,
+		"old_index":           oldIndex.name,
+		"new_index":           newIndexName,
+		"rolled_over":         true,
+		"dry_run":             false,
+		"conditions":          object{},
+	}
+}
+
+func nextGeneration(indexName string) (string, error) {
+	separatorPosition := strings.LastIndex(indexName, "-")
+	if separatorPosition < 0 {
+		return "", fmt.Errorf("index name [%s] does not match pattern '^.*-\\d+$'", indexName)
+	}
+
+	generation, err := strconv.Atoi(indexName[separatorPosition+1:])
+	if err != nil {
+		return "", fmt.Errorf("index name [%s] does not match pattern '^.*-\\d+$'", indexName)
+	}
+
+	return fmt.Sprintf("%s-%0*d", indexName[:separatorPosition], rolloverSuffixWidth, generation+1), nil
+}
+
+func indexNotFound(indexExpression string) (int, interface{}) {
+	return http.StatusNotFound, errorResponse(http.StatusNotFound, "index_not_found_exception",
+		fmt.Sprintf("no such index [%s]", indexExpression))
+}
+
+func sortedKeys(values map[string]json.RawMessage) []string {
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func boolPointer(value bool) *bool {
+	return &value
+}
@@ -0,0 +1,362 @@
+package fakeelastic
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	logger "github.com/multiversx/mx-chain-logger-go"
+)
+
+var log = logger.GetOrCreate("fakeelastic")
+
+const fakeVersion = "7.17.0"
+
+type object = map[string]interface{}
+
+// Server is an in-memory stand-in of an Elasticsearch cluster, served over HTTP, that implements the endpoints used by
+// the tools: search, scroll and point in time, bulk, count, mappings, index templates, aliases, rollover and ILM
+// policies. The documents are not analyzed: the queries match the exact values of the source fields
+type Server struct {
+	mut          sync.Mutex
+	httpServer   *httptest.Server
+	indices      map[string]*index
+	templates    map[string]json.RawMessage
+	oldTemplates map[string]json.RawMessage
+	policies     map[string]*policy
+	scrolls      map[string]*scrollCursor
+	pits         map[string]string
+	requests     []string
+	nextID       uint64
+}
+
+type policy struct {
+	version int
+	body    json.RawMessage
+}
+
+// NewServer creates and starts a new fake Elasticsearch server. It should be closed when it is no longer used
+func NewServer() *Server {
+	s := &Server{
+		indices:      make(map[string]*index),
+		templates:    make(map[string]json.RawMessage),
+		oldTemplates: make(map[string]json.RawMessage),
+		policies:     make(map[string]*policy),
+		scrolls:      make(map[string]*scrollCursor),
+		pits:         make(map[string]string),
+	}
+	s.httpServer = httptest.NewServer(s)
+
+	return s
+}
+
+// URL returns the address of the server
+func (s *Server) URL() string {
+	return s.httpServer.URL
+}
+
+// Close stops the server
+func (s *Server) Close() {
+	s.httpServer.Close()
+}
+
+// Requests returns the method and the path of all the requests received so far
+func (s *Server) Requests() []string {
+	s.mut.Lock()
+	defer s.mut.Unlock()
+
+	return append([]string{}, s.requests...)
+}
+
+// ServeHTTP handles a request as an Elasticsearch cluster would
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		writeResponse(w, r, http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parse_exception", err.Error()))
+		return
+	}
+
+	s.mut.Lock()
+	s.requests = append(s.requests, r.Method+" "+r.URL.Path)
+	status, response := s.route(r.Method, splitPath(r.URL.Path), r.URL.Query(), body)
+	s.mut.Unlock()
+
+	writeResponse(w, r, status, response)
+}
+
+func (s *Server) route(method string, segments []string, params url.Values, body []byte) (int, interface{}) {
+	if len(segments) == 0 {
+		return s.info()
+	}
+
+	switch segments[0] {
+	case "_bulk":
+		return s.bulk("", body)
+	case "_search":
+		if len(segments) > 1 && segments[1] == "scroll" {
+			return s.routeScroll(method, segments[2:], params, body)
+		}
+		return s.search("", params, body)
+	case "_count":
+		return s.count("", body)
+	case "_pit":
+		return s.closePointInTime(body)
+	case "_aliases":
+		return s.updateAliases(body)
+	case "_alias":
+		return s.routeAlias(method, "", segments[1:], body)
+	case "_index_template":
+		if len(segments) == 3 && segments[1] == "_simulate_index" {
+			return s.simulateIndex(segments[2])
+		}
+		return s.routeTemplate(method, s.templates, segments[1:], body)
+	case "_template":
+		return s.routeTemplate(method, s.oldTemplates, segments[1:], body)
+	case "_ilm":
+		if len(segments) > 1 && segments[1] == "policy" {
+			return s.routePolicy(method, segments[2:], body)
+		}
+	}
+	if strings.HasPrefix(segments[0], "_") {
+		return unsupported(method, segments)
+	}
+
+	return s.routeIndex(method, segments, params, body)
+}
+
+func (s *Server) routeIndex(method string, segments []string, params url.Values, body []byte) (int, interface{}) {
+	indexExpression := segments[0]
+	if len(segments) == 1 {
+		switch method {
+		case http.MethodPut:
+			return s.createIndex(indexExpression, body)
+		case http.MethodHead:
+			return s.indexExists(indexExpression)
+		case http.MethodDelete:
+			return s.deleteIndex(indexExpression)
+		}
+		return unsupported(method, segments)
+	}
+
+	switch segments[1] {
+	case "_bulk":
+		return s.bulk(indexExpression, body)
+	case "_search":
+		return s.search(indexExpression, params, body)
+	case "_count":
+		return s.count(indexExpression, body)
+	case "_pit":
+		return s.openPointInTime(indexExpression)
+	case "_mapping":
+		if method == http.MethodPut {
+			return s.putMapping(indexExpression, body)
+		}
+		return s.getMapping(indexExpression)
+	case "_alias", "_aliases":
+		return s.routeAlias(method, indexExpression, segments[2:], body)
+	case "_rollover":
+		return s.rollover(indexExpression)
+	case "_refresh":
+		return http.StatusOK, object{"_shards": object{"failed": 0}}
+	}
+
+	return unsupported(method, segments)
+}
+
+func (s *Server) routeScroll(method string, segments []string, params url.Values, body []byte) (int, interface{}) {
+	scrollID := params.Get("scroll_id")
+	if len(segments) > 0 {
+		scrollID = segments[0]
+	}
+	if scrollID == "" {
+		var request struct {
+			ScrollID string `json:"scroll_id"`
+		}
+		_ = json.Unmarshal(body, &request)
+		scrollID = request.ScrollID
+	}
+
+	if method == http.MethodDelete {
+		return s.clearScroll(scrollID)
+	}
+
+	return s.nextScrollPage(scrollID)
+}
+
+func (s *Server) routeAlias(method string, indexExpression string, segments []string, body []byte) (int, interface{}) {
+	if len(segments) == 0 {
+		return unsupported(method, segments)
+	}
+
+	alias := segments[0]
+	switch method {
+	case http.MethodPut, http.MethodPost:
+		return s.putAlias(indexExpression, alias, body)
+	case http.MethodHead:
+		return s.aliasExists(alias)
+	case http.MethodGet:
+		return s.getAlias(alias)
+	}
+
+	return unsupported(method, segments)
+}
+
+func (s *Server) routeTemplate(method string, templates map[string]json.RawMessage, segments []string, body []byte) (int, interface{}) {
+	name := ""
+	if len(segments) > 0 {
+		name = segments[0]
+	}
+
+	switch method {
+	case http.MethodPut, http.MethodPost:
+		if !json.Valid(body) {
+			return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parse_exception", "invalid template body")
+		}
+		templates[name] = append(json.RawMessage{}, body...)
+		return http.StatusOK, object{"acknowledged": true}
+	case http.MethodHead:
+		_, found := templates[name]
+		if !found {
+			return http.StatusNotFound, nil
+		}
+		return http.StatusOK, nil
+	case http.MethodDelete:
+		delete(templates, name)
+		return http.StatusOK, object{"acknowledged": true}
+	case http.MethodGet:
+		return s.getTemplates(templates, name)
+	}
+
+	return unsupported(method, segments)
+}
+
+func (s *Server) getTemplates(templates map[string]json.RawMessage, name string) (int, interface{}) {
+	names := make([]string, 0, len(templates))
+	for templateName := range templates {
+		if name == "" || matchesPattern(name, templateName) {
+			names = append(names, templateName)
+		}
+	}
+	if len(names) == 0 {
+		return http.StatusNotFound, errorResponse(http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("index template matching [%s] not found", name))
+	}
+	sort.Strings(names)
+
+	indexTemplates := make([]object, 0, len(names))
+	for _, templateName := range names {
+		indexTemplates = append(indexTemplates, object{
+			"name":           templateName,
+			"index_template": templates[templateName],
+		})
+	}
+
+	return http.StatusOK, object{"index_templates": indexTemplates}
+}
+
+func (s *Server) routePolicy(method string, segments []string, body []byte) (int, interface{}) {
+	if len(segments) == 0 {
+		return unsupported(method, segments)
+	}
+
+	name := segments[0]
+	switch method {
+	case http.MethodPut:
+		var request struct {
+			Policy json.RawMessage `json:"policy"`
+		}
+		err := json.Unmarshal(body, &request)
+		if err != nil || len(request.Policy) == 0 {
+			return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "parse_exception", "the policy is missing")
+		}
+
+		existing, found := s.policies[name]
+		version := 1
+		if found {
+			version = existing.version + 1
+		}
+		s.policies[name] = &policy{version: version, body: request.Policy}
+		return http.StatusOK, object{"acknowledged": true}
+	case http.MethodGet:
+		existing, found := s.policies[name]
+		if !found {
+			return http.StatusNotFound, errorResponse(http.StatusNotFound, "resource_not_found_exception", fmt.Sprintf("Lifecycle policy not found: %s", name))
+		}
+		return http.StatusOK, object{name: object{
+			"version":       existing.version,
+			"modified_date": "2021-01-01T00:00:00.000Z",
+			"policy":        existing.body,
+		}}
+	case http.MethodDelete:
+		delete(s.policies, name)
+		return http.StatusOK, object{"acknowledged": true}
+	}
+
+	return unsupported(method, segments)
+}
+
+func (s *Server) info() (int, interface{}) {
+	return http.StatusOK, object{
+		"name":         "fakeelastic",
+		"cluster_name": "fakeelastic",
+		"version": object{
+			"number":       fakeVersion,
+			"build_flavor": "default",
+		},
+		"tagline": "You Know, for Search",
+	}
+}
+
+func unsupported(method string, segments []string) (int, interface{}) {
+	uri := "/" + strings.Join(segments, "/")
+	log.Warn("unsupported request", "method", method, "uri", uri)
+
+	return http.StatusBadRequest, errorResponse(http.StatusBadRequest, "illegal_argument_exception",
+		fmt.Sprintf("no handler found for uri [%s] and method [%s]", uri, method))
+}
+
+func errorResponse(status int, errorType string, reason string) object {
+	return object{
+		"error": object{
+			"root_cause": []object{{"type": errorType, "reason": reason}},
+			"type":       errorType,
+			"reason":     reason,
+		},
+		"status": status,
+	}
+}
+
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, response interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if response == nil || r.Method == http.MethodHead {
+		return
+	}
+
+	err := json.NewEncoder(w).Encode(response)
+	if err != nil {
+		log.Warn("cannot write response", "error", err)
+	}
+}
+
+func splitPath(path string) []string {
+	segments := make([]string, 0)
+	for _, segment := range strings.Split(path, "/") {
+		if segment == "" {
+			continue
+		}
+
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		segments = append(segments, unescaped)
+	}
+
+	return segments
+}
@@ -0,0 +1,191 @@
+package fakeelastic
+
+import (
+	"bytes"
+	"fmt"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/elastic"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func newClient(t *testing.T, server *Server) elasticClient {
+	client, err := elastic.NewElasticClient(config.ElasticInstanceConfig{URL: server.URL()})
+	require.NoError(t, err)
+
+	return client
+}
+
+// elasticClient holds the methods of the client used by the tests
+type elasticClient interface {
+	DoBulkRequest(buff *bytes.Buffer, index string) error
+	DoSearchRequest(index string, body []byte) ([]byte, error)
+	DoScrollRequestAllDocuments(index string, body []byte, handlerFunc func(responseBytes []byte) error) error
+	DoPitRequestAllDocuments(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error
+	GetCountWithBody(index string, body []byte) (uint64, error)
+	GetMapping(index string) (*bytes.Buffer, error)
+	CreateIndexWithMapping(targetIndex string, body *bytes.Buffer) error
+	PutIndexTemplate(templateName string, body *bytes.Buffer) error
+	GetIndexTemplate(templateName string) ([]byte, error)
+	GetMatchingIndexTemplate(index string) ([]byte, error)
+	PutAlias(index string, alias string) error
+	DoesAliasExist(alias string) bool
+	DoesIndexExist(index string) bool
+	GetWriteIndex(alias string) (string, error)
+	Rollover(alias string) (string, string, error)
+	PutPolicy(policyName string, policy *bytes.Buffer) error
+	GetPolicy(policyName string) ([]byte, error)
+}
+
+func addDocuments(t *testing.T, server *Server, index string, numDocuments int) {
+	for i := 0; i < numDocuments; i++ {
+		err := server.IndexDocument(index, fmt.Sprintf("doc-%d", i), fmt.Sprintf(`{"timestamp":%d,"shard":%d}`, 1000+i, i%3))
+		require.NoError(t, err)
+	}
+}
+
+func TestServer_BulkAndSearch(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	bulk := bytes.NewBufferString(`{"index":{"_id":"a"}}
+{"timestamp":10,"shard":1}
+{"index":{"_id":"b"}}
+{"timestamp":20,"shard":2}
+{"create":{"_id":"c"}}
+{"timestamp":30,"shard":2}
+`)
+	require.NoError(t, client.DoBulkRequest(bulk, "blocks"))
+	require.Equal(t, 3, server.Count("blocks"))
+
+	err := client.DoBulkRequest(bytes.NewBufferString(`{"create":{"_id":"c"}}`+"\n"+`{"timestamp":40}`+"\n"), "blocks")
+	require.Error(t, err)
+	require.Equal(t, `{"timestamp":30,"shard":2}`, server.Documents("blocks")["c"])
+
+	count, err := client.GetCountWithBody("blocks", []byte(`{"query":{"range":{"timestamp":{"gte":"15","lte":"30"}}}}`))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), count)
+
+	response, err := client.DoSearchRequest("blocks", []byte(`{"query":{"bool":{"must_not":[{"term":{"shard":1}}]}},"sort":[{"timestamp":{"order":"desc"}}]}`))
+	require.NoError(t, err)
+	require.Equal(t, `["c","b"]`, gjson.GetBytes(response, "hits.hits.#._id").Raw)
+
+	response, err = client.DoSearchRequest("blocks", []byte(`{"size":0,"aggs":{"buckets":{"terms":{"field":"shard","size":10}}}}`))
+	require.NoError(t, err)
+	require.Equal(t, `[{"doc_count":2,"key":2},{"doc_count":1,"key":1}]`, gjson.GetBytes(response, "aggregations.buckets.buckets").Raw)
+	require.Equal(t, int64(0), gjson.GetBytes(response, "hits.hits.#").Int())
+
+	response, err = client.DoSearchRequest("blocks", []byte(`{"size":0,"aggs":{"buckets":{"composite":{"size":1,"sources":[{"key":{"terms":{"field":"shard"}}}],"after":{"key":1}}}}}`))
+	require.NoError(t, err)
+	require.Equal(t, `[{"doc_count":2,"key":{"key":2}}]`, gjson.GetBytes(response, "aggregations.buckets.buckets").Raw)
+	require.Equal(t, `{"key":2}`, gjson.GetBytes(response, "aggregations.buckets.after_key").Raw)
+
+	response, err = client.DoSearchRequest("blocks", []byte(`{"query":{"unknown":{}}}`))
+	require.Error(t, err)
+	require.Nil(t, response)
+}
+
+func TestServer_ScrollAndPointInTimeReadAllDocuments(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+	addDocuments(t, server, "logs", 25)
+
+	ids := make(map[string]struct{})
+	for sliceID := 0; sliceID < 2; sliceID++ {
+		query := fmt.Sprintf(`{"query":{"match_all":{}},"slice":{"id":%d,"max":2},"size":10}`, sliceID)
+		err := client.DoScrollRequestAllDocuments("logs", []byte(query), func(responseBytes []byte) error {
+			for _, id := range gjson.GetBytes(responseBytes, "hits.hits.#._id").Array() {
+				ids[id.String()] = struct{}{}
+			}
+			return nil
+		})
+		require.NoError(t, err)
+	}
+	require.Len(t, ids, 25)
+
+	timestamps := make([]int64, 0)
+	query := []byte(`{"query":{"range":{"timestamp":{"gte":"1005"}}},"sort":[{"timestamp":{"order":"asc"}},{"_shard_doc":{"order":"asc"}}]}`)
+	err := client.DoPitRequestAllDocuments("logs", query, 7, func(responseBytes []byte) error {
+		for _, timestamp := range gjson.GetBytes(responseBytes, "hits.hits.#._source.timestamp").Array() {
+			timestamps = append(timestamps, timestamp.Int())
+		}
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, timestamps, 20)
+	for i, timestamp := range timestamps {
+		require.Equal(t, int64(1005+i), timestamp)
+	}
+	require.Empty(t, server.pits)
+}
+
+func TestServer_TemplatesAliasesAndRollover(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	template := `{"index_patterns":["blocks-*"],"priority":1,"template":{"mappings":{"properties":{"nonce":{"type":"long"}}}}}`
+	require.NoError(t, client.PutIndexTemplate("blocks", bytes.NewBufferString(template)))
+	templateBytes, err := client.GetIndexTemplate("blocks")
+	require.NoError(t, err)
+	require.Equal(t, `["blocks-*"]`, gjson.GetBytes(templateBytes, "index_patterns").Raw)
+
+	matchingTemplate, err := client.GetMatchingIndexTemplate("blocks-000001")
+	require.NoError(t, err)
+	require.Equal(t, "long", gjson.GetBytes(matchingTemplate, "template.mappings.properties.nonce.type").String())
+	matchingTemplate, err = client.GetMatchingIndexTemplate("transactions")
+	require.NoError(t, err)
+	require.False(t, gjson.GetBytes(matchingTemplate, "template.mappings").Exists())
+
+	require.NoError(t, client.CreateIndexWithMapping("blocks-000001", nil))
+	require.NoError(t, client.PutAlias("blocks-000001", "blocks"))
+	require.True(t, client.DoesAliasExist("blocks"))
+	require.True(t, client.DoesIndexExist("blocks"))
+	require.False(t, client.DoesIndexExist("missing"))
+
+	mapping, err := client.GetMapping("blocks")
+	require.NoError(t, err)
+	require.Equal(t, "long", gjson.Get(mapping.String(), "mappings.properties.nonce.type").String())
+
+	oldIndex, newIndex, err := client.Rollover("blocks")
+	require.NoError(t, err)
+	require.Equal(t, "blocks-000001", oldIndex)
+	require.Equal(t, "blocks-000002", newIndex)
+
+	writeIndex, err := client.GetWriteIndex("blocks")
+	require.NoError(t, err)
+	require.Equal(t, "blocks-000002", writeIndex)
+	require.Equal(t, `{"properties":{"nonce":{"type":"long"}}}`, server.Mapping("blocks-000002"))
+
+	require.NoError(t, client.DoBulkRequest(bytes.NewBufferString(`{"index":{"_id":"x"}}`+"\n"+`{"nonce":1}`+"\n"), "blocks"))
+	require.Len(t, server.Documents("blocks-000002"), 1)
+	require.Len(t, server.Documents("blocks"), 1)
+}
+
+func TestServer_Policies(t *testing.T) {
+	t.Parallel()
+
+	server := NewServer()
+	defer server.Close()
+	client := newClient(t, server)
+
+	policy, err := client.GetPolicy("blocks")
+	require.NoError(t, err)
+	require.Nil(t, policy)
+
+	require.NoError(t, client.PutPolicy("blocks", bytes.NewBufferString(`{"policy":{"phases":{"hot":{"actions":{}}}}}`)))
+	policy, err = client.GetPolicy("blocks")
+	require.NoError(t, err)
+	require.Equal(t, `{"policy":{"phases":{"hot":{"actions":{}}}}}`, string(policy))
+	require.Equal(t, `{"phases":{"hot":{"actions":{}}}}`, server.Policy("blocks"))
+}
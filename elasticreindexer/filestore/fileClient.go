@@ -0,0 +1,392 @@
+package filestore
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+
+	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/tidwall/gjson"
+)
+
+var log = logger.GetOrCreate("filestore")
+
+const (
+	defaultChunkSizeInMB = 512
+	defaultPageSize      = 9000
+	maxLineSize          = 100 * 1024 * 1024
+	bytesInMB            = 1024 * 1024
+)
+
+var errSearchNotSupported = errors.New("search requests are not supported by the file client")
+
+// fileClient is an ElasticClientHandler that stores the indices in a local directory instead of an Elasticsearch
+// cluster. Every index has its own directory with the mapping, the documents in the bulk format (NDJSON) split into
+// chunks, and a manifest with the counts and the checksums of the chunks
+type fileClient struct {
+	rootDir          string
+	compress         bool
+	chunkSizeInBytes int64
+	pageSize         int
+
+	mut             sync.Mutex
+	writers         map[string]*indexWriter
+	pendingMappings map[string][]byte
+}
+
+// NewFileClient will create a new instance of a file client
+func NewFileClient(cfg config.FileConfig) (*fileClient, error) {
+	if cfg.Path == "" {
+		return nil, errors.New("empty path for the file client")
+	}
+
+	err := os.MkdirAll(cfg.Path, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	chunkSizeInMB := cfg.ChunkSizeInMB
+	if chunkSizeInMB <= 0 {
+		chunkSizeInMB = defaultChunkSizeInMB
+	}
+	pageSize := cfg.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	return &fileClient{
+		rootDir:          cfg.Path,
+		compress:         cfg.Compress,
+		chunkSizeInBytes: int64(chunkSizeInMB) * bytesInMB,
+		pageSize:         pageSize,
+		writers:          make(map[string]*indexWriter),
+		pendingMappings:  make(map[string][]byte),
+	}, nil
+}
+
+// GetMapping will return the saved mapping of the provided index
+func (fc *fileClient) GetMapping(index string) (*bytes.Buffer, error) {
+	mappingBytes, err := os.ReadFile(filepath.Join(fc.indexDir(index), mappingFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		log.Warn("no mapping saved for index", "index", index)
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return bytes.NewBuffer(mappingBytes), nil
+}
+
+// CreateIndexWithMapping will keep the provided mapping until an alias is set for the index
+func (fc *fileClient) CreateIndexWithMapping(targetIndex string, body *bytes.Buffer) error {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	mapping := make([]byte, 0)
+	if body != nil {
+		mapping = append(mapping, body.Bytes()...)
+	}
+	fc.pendingMappings[targetIndex] = mapping
+
+	return nil
+}
+
+// PutAlias will save the mapping of the provided index in the directory of the alias
+func (fc *fileClient) PutAlias(index string, alias string) error {
+	_, err := fc.getWriter(alias)
+	if err != nil {
+		return err
+	}
+
+	fc.mut.Lock()
+	mapping, found := fc.pendingMappings[index]
+	fc.mut.Unlock()
+	if !found || len(mapping) == 0 {
+		return nil
+	}
+
+	return writeFileAtomically(filepath.Join(fc.indexDir(alias), mappingFileName), mapping)
+}
+
+// DoesAliasExist returns true if the directory of the provided alias already has a manifest
+func (fc *fileClient) DoesAliasExist(alias string) bool {
+	_, err := os.Stat(filepath.Join(fc.indexDir(alias), manifestFileName))
+	return err == nil
+}
+
+// DoesIndexExist returns true if the provided index was created or already has a directory
+func (fc *fileClient) DoesIndexExist(index string) bool {
+	fc.mut.Lock()
+	_, found := fc.pendingMappings[index]
+	fc.mut.Unlock()
+	if found {
+		return true
+	}
+
+	_, err := os.Stat(fc.indexDir(index))
+	return err == nil
+}
+
+// DoBulkRequest will append the provided bulk in the files of the provided index
+func (fc *fileClient) DoBulkRequest(buff *bytes.Buffer, index string) error {
+	writer, err := fc.getWriter(index)
+	if err != nil {
+		return err
+	}
+
+	bulk := buff.Bytes()
+	numLines := bytes.Count(bulk, []byte("\n"))
+
+	return writer.write(bulk, uint64(numLines/2))
+}
+
+// ClearIndex will remove the documents saved for the provided index, so it can be copied again from its beginning
+// without duplicating them. The mapping of the index is kept
+func (fc *fileClient) ClearIndex(index string) error {
+	if !fc.DoesAliasExist(index) {
+		return nil
+	}
+
+	writer, err := fc.getWriter(index)
+	if err != nil {
+		return err
+	}
+
+	return writer.clear()
+}
+
+func (fc *fileClient) getWriter(index string) (*indexWriter, error) {
+	fc.mut.Lock()
+	defer fc.mut.Unlock()
+
+	writer, found := fc.writers[index]
+	if found {
+		return writer, nil
+	}
+
+	writer, err := newIndexWriter(fc.indexDir(index), index, fc.compress, fc.chunkSizeInBytes)
+	if err != nil {
+		return nil, fmt.Errorf("%w while creating the writer for index %s", err, index)
+	}
+	fc.writers[index] = writer
+
+	return writer, nil
+}
+
+// GetCount returns the number of documents of the provided index, as saved in the manifest
+func (fc *fileClient) GetCount(index string) (uint64, error) {
+	manifest, err := loadManifest(fc.indexDir(index))
+	if err != nil {
+		return 0, err
+	}
+
+	return manifest.NumDocs, nil
+}
+
+// GetCountWithBody returns the number of documents of the provided index that match the query. All the files of
+// the index are read
+func (fc *fileClient) GetCountWithBody(index string, body []byte) (uint64, error) {
+	filter, err := newDocumentsFilter(body)
+	if err != nil {
+		return 0, err
+	}
+
+	count := uint64(0)
+	err = fc.iterateDocuments(index, func(id string, source []byte) error {
+		if filter.matches(id, source) {
+			count++
+		}
+		return nil
+	})
+
+	return count, err
+}
+
+// DoScrollRequestAllDocuments will read the documents of the provided index that match the query, in pages of the size
+// from the query, if any, or of the configured size
+func (fc *fileClient) DoScrollRequestAllDocuments(index string, body []byte, handlerFunc func(responseBytes []byte) error) error {
+	pageSize := fc.pageSize
+	bodySize := gjson.GetBytes(body, "size")
+	if bodySize.Exists() && bodySize.Int() > 0 {
+		pageSize = int(bodySize.Int())
+	}
+
+	return fc.readAllDocuments(index, body, pageSize, handlerFunc)
+}
+
+// DoPitRequestAllDocuments will read the documents of the provided index that match the query, in pages of the provided size
+func (fc *fileClient) DoPitRequestAllDocuments(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error {
+	return fc.readAllDocuments(index, body, pageSize, handlerFunc)
+}
+
+// DoSearchRequest is not supported by the file client
+func (fc *fileClient) DoSearchRequest(_ string, _ []byte) ([]byte, error) {
+	return nil, errSearchNotSupported
+}
+
+// readAllDocuments returns the documents in the order they were written, without the sort values, so a partially
+// read interval will be read again from its beginning
+func (fc *fileClient) readAllDocuments(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error {
+	filter, err := newDocumentsFilter(body)
+	if err != nil {
+		return err
+	}
+
+	page := newResponsePage()
+	err = fc.iterateDocuments(index, func(id string, source []byte) error {
+		if !filter.matches(id, source) {
+			return nil
+		}
+
+		err := page.add(id, source)
+		if err != nil {
+			return err
+		}
+		if page.numHits < pageSize {
+			return nil
+		}
+
+		err = handlerFunc(page.bytes())
+		page = newResponsePage()
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	if page.numHits == 0 {
+		return nil
+	}
+
+	return handlerFunc(page.bytes())
+}
+
+func (fc *fileClient) iterateDocuments(index string, handler func(id string, source []byte) error) error {
+	indexDir := fc.indexDir(index)
+	manifest, err := loadManifest(indexDir)
+	if err != nil {
+		return fmt.Errorf("%w while loading the manifest of index %s", err, index)
+	}
+
+	for _, chunk := range manifest.Chunks {
+		err = iterateChunk(filepath.Join(indexDir, chunk.File), chunk.SHA256, manifest.Compressed, handler)
+		if err != nil {
+			return fmt.Errorf("%w while reading chunk %s of index %s", err, chunk.File, index)
+		}
+	}
+
+	return nil
+}
+
+func iterateChunk(filePath string, expectedChecksum string, compressed bool, handler func(id string, source []byte) error) error {
+	file, err := os.Open(filePath)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	hasher := sha256.New()
+	var reader io.Reader = io.TeeReader(file, hasher)
+	if compressed {
+		gzipReader, errGzip := gzip.NewReader(reader)
+		if errGzip != nil {
+			return errGzip
+		}
+		defer func() {
+			_ = gzipReader.Close()
+		}()
+		reader = gzipReader
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxLineSize)
+	for scanner.Scan() {
+		meta := gjson.GetBytes(scanner.Bytes(), "index._id").String()
+		if !scanner.Scan() {
+			return errors.New("missing document source after the bulk metadata")
+		}
+
+		err = handler(meta, scanner.Bytes())
+		if err != nil {
+			return err
+		}
+	}
+	err = scanner.Err()
+	if err != nil {
+		return err
+	}
+
+	// consume the bytes after the last line, so the checksum is computed over the whole file
+	_, err = io.Copy(io.Discard, reader)
+	if err != nil {
+		return err
+	}
+
+	checksum := hex.EncodeToString(hasher.Sum(nil))
+	if checksum != expectedChecksum {
+		return fmt.Errorf("checksum mismatch: expected %s, computed %s", expectedChecksum, checksum)
+	}
+
+	return nil
+}
+
+func (fc *fileClient) indexDir(index string) string {
+	return filepath.Join(fc.rootDir, index)
+}
+
+// responsePage builds a response in the same format as the Elasticsearch search response
+type responsePage struct {
+	buff    *bytes.Buffer
+	numHits int
+}
+
+func newResponsePage() *responsePage {
+	buff := &bytes.Buffer{}
+	buff.WriteString(`{"hits":{"hits":[`)
+
+	return &responsePage{
+		buff: buff,
+	}
+}
+
+func (rp *responsePage) add(id string, source []byte) error {
+	idBytes, err := json.Marshal(id)
+	if err != nil {
+		return err
+	}
+
+	if rp.numHits > 0 {
+		rp.buff.WriteByte(',')
+	}
+	rp.buff.WriteString(`{"_id":`)
+	rp.buff.Write(idBytes)
+	rp.buff.WriteString(`,"_source":`)
+	rp.buff.Write(source)
+	rp.buff.WriteByte('}')
+	rp.numHits++
+
+	return nil
+}
+
+func (rp *responsePage) bytes() []byte {
+	rp.buff.WriteString(`]}}`)
+	return rp.buff.Bytes()
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (fc *fileClient) IsInterfaceNil() bool {
+	return fc == nil
+}
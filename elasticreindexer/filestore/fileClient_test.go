@@ -0,0 +1,179 @@
+package filestore
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+func createBulk(startIdx int, numDocs int) *bytes.Buffer {
+	buff := &bytes.Buffer{}
+	for i := startIdx; i < startIdx+numDocs; i++ {
+		buff.WriteString(fmt.Sprintf(`{ "index" : { "_id" : "id-%d" } }`+"\n", i))
+		buff.WriteString(fmt.Sprintf(`{"timestamp":%d,"value":"v%d"}`+"\n", i, i))
+	}
+
+	return buff
+}
+
+func readAllIDs(t *testing.T, fc *fileClient, index string, body []byte) []string {
+	ids := make([]string, 0)
+	err := fc.DoScrollRequestAllDocuments(index, body, func(responseBytes []byte) error {
+		for _, hit := range gjson.GetBytes(responseBytes, "hits.hits").Array() {
+			ids = append(ids, hit.Get("_id").String())
+		}
+		return nil
+	})
+	require.NoError(t, err)
+
+	return ids
+}
+
+func TestNewFileClient(t *testing.T) {
+	t.Parallel()
+
+	fc, err := NewFileClient(config.FileConfig{})
+	require.Nil(t, fc)
+	require.Error(t, err)
+}
+
+func TestFileClient_WriteAndRead(t *testing.T) {
+	t.Parallel()
+
+	for _, compress := range []bool{false, true} {
+		compress := compress
+		t.Run(fmt.Sprintf("compress %v", compress), func(t *testing.T) {
+			t.Parallel()
+
+			dir := t.TempDir()
+			fc, _ := NewFileClient(config.FileConfig{Path: dir, Compress: compress, PageSize: 3})
+			// every bulk will be written in its own chunk
+			fc.chunkSizeInBytes = 1
+
+			require.False(t, fc.DoesAliasExist("blocks"))
+			require.NoError(t, fc.CreateIndexWithMapping("blocks-000001", bytes.NewBufferString(`{"mappings":{}}`)))
+			require.True(t, fc.DoesIndexExist("blocks-000001"))
+			require.NoError(t, fc.PutAlias("blocks-000001", "blocks"))
+			require.True(t, fc.DoesAliasExist("blocks"))
+
+			require.NoError(t, fc.DoBulkRequest(createBulk(0, 5), "blocks"))
+			require.NoError(t, fc.DoBulkRequest(createBulk(5, 5), "blocks"))
+
+			// a new client (e.g. a replay on another machine) reads only from the files
+			reader, _ := NewFileClient(config.FileConfig{Path: dir, PageSize: 3})
+
+			mapping, err := reader.GetMapping("blocks")
+			require.NoError(t, err)
+			require.Equal(t, `{"mappings":{}}`, mapping.String())
+
+			count, err := reader.GetCount("blocks")
+			require.NoError(t, err)
+			require.Equal(t, uint64(10), count)
+
+			manifest, _ := loadManifest(filepath.Join(dir, "blocks"))
+			require.Len(t, manifest.Chunks, 2)
+			require.Equal(t, compress, manifest.Compressed)
+
+			require.Len(t, readAllIDs(t, reader, "blocks", nil), 10)
+
+			rangeQuery := []byte(`{"query":{"range":{"timestamp":{"gte":"3","lte":"6"}}}}`)
+			require.Equal(t, []string{"id-3", "id-4", "id-5", "id-6"}, readAllIDs(t, reader, "blocks", rangeQuery))
+			count, err = reader.GetCountWithBody("blocks", rangeQuery)
+			require.NoError(t, err)
+			require.Equal(t, uint64(4), count)
+
+			boolQuery := []byte(`{"query":{"bool":{"filter":[{"range":{"timestamp":{"gte":"3","lt":"7"}}}],"must_not":{"term":{"value":"v4"}}}}}`)
+			require.Equal(t, []string{"id-3", "id-5", "id-6"}, readAllIDs(t, reader, "blocks", boolQuery))
+
+			termQuery := []byte(`{"query":{"bool":{"filter":[{"match_all":{}},{"term":{"timestamp":{"value":8}}}]}}}`)
+			require.Equal(t, []string{"id-8"}, readAllIDs(t, reader, "blocks", termQuery))
+
+			unsupportedQuery := []byte(`{"query":{"bool":{"filter":[{"range":{"timestamp":{"gte":"3"}}},{"match":{"value":"v4"}}]}}}`)
+			_, err = reader.GetCountWithBody("blocks", unsupportedQuery)
+			require.True(t, errors.Is(err, errUnsupportedQuery))
+			err = reader.DoScrollRequestAllDocuments("blocks", unsupportedQuery, func(_ []byte) error { return nil })
+			require.True(t, errors.Is(err, errUnsupportedQuery))
+
+			numRead := 0
+			for sliceID := 0; sliceID < 3; sliceID++ {
+				sliceQuery := []byte(fmt.Sprintf(`{"query":{"match_all":{}},"slice":{"id":%d,"max":3}}`, sliceID))
+				numRead += len(readAllIDs(t, reader, "blocks", sliceQuery))
+			}
+			require.Equal(t, 10, numRead)
+		})
+	}
+}
+
+func TestFileClient_ChecksumMismatchShouldErr(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fc, _ := NewFileClient(config.FileConfig{Path: dir})
+	require.NoError(t, fc.DoBulkRequest(createBulk(0, 2), "tokens"))
+
+	chunkPath := filepath.Join(dir, "tokens", "data-000001.ndjson")
+	chunkBytes, _ := os.ReadFile(chunkPath)
+	require.NoError(t, os.WriteFile(chunkPath, bytes.Replace(chunkBytes, []byte("v1"), []byte("v2"), 1), 0644))
+
+	err := fc.DoScrollRequestAllDocuments("tokens", nil, func(_ []byte) error {
+		return nil
+	})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "checksum mismatch")
+}
+
+func TestFileClient_ResumeShouldTruncateTheChunksToTheManifest(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fc, _ := NewFileClient(config.FileConfig{Path: dir})
+	require.NoError(t, fc.DoBulkRequest(createBulk(0, 2), "tokens"))
+
+	// a bulk appended by a run that stopped before saving the manifest, and a chunk that was never recorded
+	chunkPath := filepath.Join(dir, "tokens", "data-000001.ndjson")
+	chunkFile, _ := os.OpenFile(chunkPath, os.O_APPEND|os.O_WRONLY, 0644)
+	_, _ = chunkFile.Write(createBulk(2, 2).Bytes())
+	_ = chunkFile.Close()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "tokens", "data-000002.ndjson"), createBulk(4, 1).Bytes(), 0644))
+
+	resumed, _ := NewFileClient(config.FileConfig{Path: dir})
+	require.NoError(t, resumed.DoBulkRequest(createBulk(2, 2), "tokens"))
+
+	count, err := resumed.GetCount("tokens")
+	require.NoError(t, err)
+	require.Equal(t, uint64(4), count)
+	require.Equal(t, []string{"id-0", "id-1", "id-2", "id-3"}, readAllIDs(t, resumed, "tokens", nil))
+}
+
+func TestFileClient_ClearIndexShouldKeepTheMapping(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+	fc, _ := NewFileClient(config.FileConfig{Path: dir})
+	require.NoError(t, fc.ClearIndex("blocks"))
+	require.False(t, fc.DoesAliasExist("blocks"))
+
+	require.NoError(t, fc.CreateIndexWithMapping("blocks-000001", bytes.NewBufferString(`{"mappings":{}}`)))
+	require.NoError(t, fc.PutAlias("blocks-000001", "blocks"))
+	require.NoError(t, fc.DoBulkRequest(createBulk(0, 3), "blocks"))
+
+	overwriting, _ := NewFileClient(config.FileConfig{Path: dir})
+	require.NoError(t, overwriting.ClearIndex("blocks"))
+	require.NoError(t, overwriting.DoBulkRequest(createBulk(1, 1), "blocks"))
+
+	count, err := overwriting.GetCount("blocks")
+	require.NoError(t, err)
+	require.Equal(t, uint64(1), count)
+	require.Equal(t, []string{"id-1"}, readAllIDs(t, overwriting, "blocks", nil))
+
+	mapping, err := overwriting.GetMapping("blocks")
+	require.NoError(t, err)
+	require.Equal(t, `{"mappings":{}}`, mapping.String())
+}
@@ -0,0 +1,211 @@
+package filestore
+
+import (
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+const (
+	chunkFileNameFormat = "data-%06d.ndjson"
+	chunkFilesPattern   = "data-*.ndjson*"
+)
+
+type countingWriter struct {
+	writer       io.Writer
+	bytesWritten int64
+}
+
+// Write will write the provided bytes and count them
+func (cw *countingWriter) Write(p []byte) (int, error) {
+	n, err := cw.writer.Write(p)
+	cw.bytesWritten += int64(n)
+
+	return n, err
+}
+
+// indexWriter appends the bulks of an index into chunk files and keeps the manifest of the index up to date
+type indexWriter struct {
+	mut              sync.Mutex
+	indexDir         string
+	compress         bool
+	chunkSizeInBytes int64
+	manifest         *Manifest
+	currentChunk     *ChunkInfo
+	currentHasher    hash.Hash
+}
+
+func newIndexWriter(indexDir string, index string, compress bool, chunkSizeInBytes int64) (*indexWriter, error) {
+	err := os.MkdirAll(indexDir, os.ModePerm)
+	if err != nil {
+		return nil, err
+	}
+
+	manifest, err := loadManifest(indexDir)
+	if errors.Is(err, os.ErrNotExist) {
+		manifest = &Manifest{
+			Index:      index,
+			Compressed: compress,
+		}
+		err = saveManifest(indexDir, manifest)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Compressed != compress {
+		return nil, fmt.Errorf("the existing files of index %s have compressed = %v", index, manifest.Compressed)
+	}
+
+	// a previous run can stop after appending a bulk and before saving the manifest
+	err = truncateChunks(indexDir, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return &indexWriter{
+		indexDir:         indexDir,
+		compress:         compress,
+		chunkSizeInBytes: chunkSizeInBytes,
+		manifest:         manifest,
+	}, nil
+}
+
+// write appends the provided bulk. When compression is enabled, every bulk is written as a separate gzip member, so
+// the chunk file is a valid gzip file after every write
+func (iw *indexWriter) write(bulk []byte, numDocs uint64) error {
+	iw.mut.Lock()
+	defer iw.mut.Unlock()
+
+	if iw.currentChunk == nil || iw.currentChunk.SizeInBytes >= iw.chunkSizeInBytes {
+		iw.startNewChunk()
+	}
+
+	file, err := os.OpenFile(filepath.Join(iw.indexDir, iw.currentChunk.File), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = file.Close()
+	}()
+
+	cw := &countingWriter{
+		writer: io.MultiWriter(file, iw.currentHasher),
+	}
+	err = writeBulk(cw, bulk, iw.compress)
+	if err != nil {
+		return err
+	}
+
+	iw.currentChunk.NumDocs += numDocs
+	iw.currentChunk.SizeInBytes += cw.bytesWritten
+	iw.currentChunk.SHA256 = hex.EncodeToString(iw.currentHasher.Sum(nil))
+	iw.manifest.NumDocs += numDocs
+
+	return saveManifest(iw.indexDir, iw.manifest)
+}
+
+// startNewChunk never appends to the chunks written by a previous run, so the checksums of the chunks can be computed
+// only from the bytes written by this run
+func (iw *indexWriter) startNewChunk() {
+	fileName := fmt.Sprintf(chunkFileNameFormat, len(iw.manifest.Chunks)+1)
+	if iw.compress {
+		fileName += ".gz"
+	}
+
+	iw.currentChunk = &ChunkInfo{
+		File: fileName,
+	}
+	iw.currentHasher = sha256.New()
+	iw.manifest.Chunks = append(iw.manifest.Chunks, iw.currentChunk)
+}
+
+// clear removes all the chunks of the index, so it can be written again from its beginning. The mapping is kept
+func (iw *indexWriter) clear() error {
+	iw.mut.Lock()
+	defer iw.mut.Unlock()
+
+	err := removeChunkFiles(iw.indexDir, nil)
+	if err != nil {
+		return err
+	}
+
+	iw.manifest = &Manifest{
+		Index:      iw.manifest.Index,
+		Compressed: iw.compress,
+	}
+	iw.currentChunk = nil
+	iw.currentHasher = nil
+
+	return saveManifest(iw.indexDir, iw.manifest)
+}
+
+// truncateChunks drops the bytes and the chunk files that are not recorded in the manifest, so the chunks match their
+// sizes and checksums before new bulks are appended
+func truncateChunks(indexDir string, manifest *Manifest) error {
+	recordedFiles := make(map[string]struct{}, len(manifest.Chunks))
+	for _, chunk := range manifest.Chunks {
+		recordedFiles[chunk.File] = struct{}{}
+
+		filePath := filepath.Join(indexDir, chunk.File)
+		fileInfo, err := os.Stat(filePath)
+		if err != nil {
+			return err
+		}
+		if fileInfo.Size() <= chunk.SizeInBytes {
+			continue
+		}
+
+		log.Warn("truncating chunk to the size from the manifest", "file", filePath,
+			"size", fileInfo.Size(), "manifest size", chunk.SizeInBytes)
+		err = os.Truncate(filePath, chunk.SizeInBytes)
+		if err != nil {
+			return err
+		}
+	}
+
+	return removeChunkFiles(indexDir, recordedFiles)
+}
+
+// removeChunkFiles removes the chunk files of the index directory, except the provided ones
+func removeChunkFiles(indexDir string, filesToKeep map[string]struct{}) error {
+	chunkFiles, err := filepath.Glob(filepath.Join(indexDir, chunkFilesPattern))
+	if err != nil {
+		return err
+	}
+
+	for _, chunkFile := range chunkFiles {
+		_, keep := filesToKeep[filepath.Base(chunkFile)]
+		if keep {
+			continue
+		}
+
+		err = os.Remove(chunkFile)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func writeBulk(writer io.Writer, bulk []byte, compress bool) error {
+	if !compress {
+		_, err := writer.Write(bulk)
+		return err
+	}
+
+	gzipWriter := gzip.NewWriter(writer)
+	_, err := gzipWriter.Write(bulk)
+	if err != nil {
+		return err
+	}
+
+	return gzipWriter.Close()
+}
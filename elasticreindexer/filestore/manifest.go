@@ -0,0 +1,62 @@
+package filestore
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+)
+
+const (
+	manifestFileName = "manifest.json"
+	mappingFileName  = "mapping.json"
+)
+
+// Manifest holds the description of the files of an index
+type Manifest struct {
+	Index      string       `json:"index"`
+	NumDocs    uint64       `json:"numDocs"`
+	Compressed bool         `json:"compressed"`
+	Chunks     []*ChunkInfo `json:"chunks"`
+}
+
+// ChunkInfo holds the description of a file with documents in the bulk format
+type ChunkInfo struct {
+	File        string `json:"file"`
+	NumDocs     uint64 `json:"numDocs"`
+	SizeInBytes int64  `json:"sizeInBytes"`
+	SHA256      string `json:"sha256"`
+}
+
+func loadManifest(indexDir string) (*Manifest, error) {
+	fileBytes, err := os.ReadFile(filepath.Join(indexDir, manifestFileName))
+	if err != nil {
+		return nil, err
+	}
+
+	manifest := &Manifest{}
+	err = json.Unmarshal(fileBytes, manifest)
+	if err != nil {
+		return nil, err
+	}
+
+	return manifest, nil
+}
+
+func saveManifest(indexDir string, manifest *Manifest) error {
+	fileBytes, err := json.MarshalIndent(manifest, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return writeFileAtomically(filepath.Join(indexDir, manifestFileName), fileBytes)
+}
+
+func writeFileAtomically(filePath string, data []byte) error {
+	tmpFilePath := filePath + ".tmp"
+	err := os.WriteFile(tmpFilePath, data, 0644)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFilePath, filePath)
+}
@@ -0,0 +1,243 @@
+package filestore
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+
+	"github.com/tidwall/gjson"
+)
+
+var errUnsupportedQuery = errors.New("query clause not supported by the file client")
+
+// documentMatcher returns true if the source of a document matches a query clause
+type documentMatcher func(source []byte) bool
+
+// documentsFilter holds the parts of an Elasticsearch query that can be applied on the documents read from files:
+// match_all, term and range clauses, also combined in the filter, must and must_not clauses of a bool query, and a
+// slice. Any other clause is rejected, so a query is never widened to all the documents
+type documentsFilter struct {
+	matchers  []documentMatcher
+	hasSlice  bool
+	sliceID   uint32
+	numSlices uint32
+}
+
+func newDocumentsFilter(body []byte) (*documentsFilter, error) {
+	filter := &documentsFilter{}
+	if len(body) == 0 {
+		return filter, nil
+	}
+
+	query := gjson.GetBytes(body, "query")
+	if query.Exists() {
+		matchers, err := parseClause(query)
+		if err != nil {
+			return nil, err
+		}
+		filter.matchers = matchers
+	}
+
+	slice := gjson.GetBytes(body, "slice")
+	if slice.Exists() && slice.Get("max").Uint() > 1 {
+		filter.hasSlice = true
+		filter.sliceID = uint32(slice.Get("id").Uint())
+		filter.numSlices = uint32(slice.Get("max").Uint())
+	}
+
+	return filter, nil
+}
+
+// parseClause returns the matchers of a query clause, all of them having to match a document
+func parseClause(clause gjson.Result) ([]documentMatcher, error) {
+	if !clause.IsObject() {
+		return nil, fmt.Errorf("%w: %s", errUnsupportedQuery, clause.Raw)
+	}
+
+	matchers := make([]documentMatcher, 0)
+	var err error
+	clause.ForEach(func(key, value gjson.Result) bool {
+		var clauseMatchers []documentMatcher
+		switch key.String() {
+		case "match_all":
+		case "range":
+			clauseMatchers, err = parseRange(value)
+		case "term":
+			clauseMatchers, err = parseTerm(value)
+		case "bool":
+			clauseMatchers, err = parseBool(value)
+		default:
+			err = fmt.Errorf("%w: %s", errUnsupportedQuery, key.String())
+		}
+		matchers = append(matchers, clauseMatchers...)
+
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matchers, nil
+}
+
+func parseRange(value gjson.Result) ([]documentMatcher, error) {
+	matchers := make([]documentMatcher, 0)
+	var err error
+	value.ForEach(func(field, bounds gjson.Result) bool {
+		bounds.ForEach(func(operator, bound gjson.Result) bool {
+			limit := bound.Float()
+			var isInRange func(value float64) bool
+			switch operator.String() {
+			case "gte":
+				isInRange = func(value float64) bool { return value >= limit }
+			case "gt":
+				isInRange = func(value float64) bool { return value > limit }
+			case "lte":
+				isInRange = func(value float64) bool { return value <= limit }
+			case "lt":
+				isInRange = func(value float64) bool { return value < limit }
+			default:
+				err = fmt.Errorf("%w: range %s", errUnsupportedQuery, operator.String())
+				return false
+			}
+			matchers = append(matchers, newRangeMatcher(field.String(), isInRange))
+
+			return err == nil
+		})
+
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matchers, nil
+}
+
+// newRangeMatcher returns a matcher for the documents whose field exists and is in range
+func newRangeMatcher(path string, isInRange func(value float64) bool) documentMatcher {
+	return func(source []byte) bool {
+		fieldValue := gjson.GetBytes(source, path)
+		return fieldValue.Exists() && isInRange(fieldValue.Float())
+	}
+}
+
+func parseTerm(value gjson.Result) ([]documentMatcher, error) {
+	matchers := make([]documentMatcher, 0)
+	value.ForEach(func(field, term gjson.Result) bool {
+		if term.IsObject() {
+			term = term.Get("value")
+		}
+
+		path := field.String()
+		matchers = append(matchers, func(source []byte) bool {
+			return termMatches(gjson.GetBytes(source, path), term)
+		})
+
+		return true
+	})
+
+	return matchers, nil
+}
+
+func termMatches(fieldValue gjson.Result, term gjson.Result) bool {
+	if !fieldValue.Exists() {
+		return false
+	}
+	if fieldValue.Type == gjson.Number {
+		return fieldValue.Float() == term.Float()
+	}
+
+	return fieldValue.String() == term.String()
+}
+
+func parseBool(value gjson.Result) ([]documentMatcher, error) {
+	matchers := make([]documentMatcher, 0)
+	var err error
+	value.ForEach(func(occurrence, clauses gjson.Result) bool {
+		var clauseMatchers []documentMatcher
+		switch occurrence.String() {
+		case "filter", "must":
+			clauseMatchers, err = parseClauses(clauses)
+		case "must_not":
+			clauseMatchers, err = parseNegatedClauses(clauses)
+		default:
+			err = fmt.Errorf("%w: bool %s", errUnsupportedQuery, occurrence.String())
+		}
+		matchers = append(matchers, clauseMatchers...)
+
+		return err == nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return matchers, nil
+}
+
+// parseClauses returns the matchers of a clause or of an array of clauses
+func parseClauses(clauses gjson.Result) ([]documentMatcher, error) {
+	if !clauses.IsArray() {
+		return parseClause(clauses)
+	}
+
+	matchers := make([]documentMatcher, 0)
+	for _, clause := range clauses.Array() {
+		clauseMatchers, err := parseClause(clause)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, clauseMatchers...)
+	}
+
+	return matchers, nil
+}
+
+// parseNegatedClauses returns a matcher for each clause of a must_not occurrence, matching the documents that do not
+// match the clause
+func parseNegatedClauses(clauses gjson.Result) ([]documentMatcher, error) {
+	negatedClauses := []gjson.Result{clauses}
+	if clauses.IsArray() {
+		negatedClauses = clauses.Array()
+	}
+
+	matchers := make([]documentMatcher, 0, len(negatedClauses))
+	for _, clause := range negatedClauses {
+		clauseMatchers, err := parseClause(clause)
+		if err != nil {
+			return nil, err
+		}
+		matchers = append(matchers, negate(clauseMatchers))
+	}
+
+	return matchers, nil
+}
+
+// negate returns a matcher for the documents that do not match all the provided matchers
+func negate(matchers []documentMatcher) documentMatcher {
+	return func(source []byte) bool {
+		return !matchesAll(matchers, source)
+	}
+}
+
+func matchesAll(matchers []documentMatcher, source []byte) bool {
+	for _, matcher := range matchers {
+		if !matcher(source) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (df *documentsFilter) matches(id string, source []byte) bool {
+	if df.hasSlice {
+		hasher := fnv.New32a()
+		_, _ = hasher.Write([]byte(id))
+		if hasher.Sum32()%df.numSlices != df.sliceID {
+			return false
+		}
+	}
+
+	return matchesAll(df.matchers, source)
+}
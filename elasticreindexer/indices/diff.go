@@ -0,0 +1,124 @@
+package indices
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+)
+
+const (
+	// DiffAdded defines a field that exists only in the local file
+	DiffAdded = "added"
+	// DiffRemoved defines a field that exists only in the cluster
+	DiffRemoved = "removed"
+	// DiffChanged defines a field that has different values in the local file and in the cluster
+	DiffChanged = "changed"
+)
+
+// FieldDiff holds a difference between a local file and the cluster, for a leaf field
+type FieldDiff struct {
+	Path  string `json:"path"`
+	Kind  string `json:"kind"`
+	Local string `json:"local,omitempty"`
+	Live  string `json:"live,omitempty"`
+}
+
+// flattenJSON returns the leaf values of the provided JSON, keyed by their dotted path. The values are compared as
+// strings, as Elasticsearch returns the settings with string values (e.g. "5" for number_of_shards)
+func flattenJSON(data []byte) (map[string]string, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return map[string]string{}, nil
+	}
+
+	var value interface{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	err := decoder.Decode(&value)
+	if err != nil {
+		return nil, err
+	}
+
+	leaves := make(map[string]string)
+	flattenValue("", value, leaves)
+
+	return leaves, nil
+}
+
+func flattenValue(path string, value interface{}, leaves map[string]string) {
+	switch typedValue := value.(type) {
+	case map[string]interface{}:
+		for key, child := range typedValue {
+			flattenValue(joinPath(path, key), child, leaves)
+		}
+	case []interface{}:
+		for idx, child := range typedValue {
+			flattenValue(joinPath(path, fmt.Sprintf("%d", idx)), child, leaves)
+		}
+	case nil:
+		leaves[path] = "null"
+	default:
+		leaves[path] = fmt.Sprintf("%v", typedValue)
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
+
+// normalizeSettingsPaths removes the "index." prefix from the settings paths, as Elasticsearch returns the settings
+// nested under "index" no matter how they were provided
+func normalizeSettingsPaths(leaves map[string]string, settingsPath string) map[string]string {
+	prefix := settingsPath + ".index."
+	normalized := make(map[string]string, len(leaves))
+	for path, value := range leaves {
+		if strings.HasPrefix(path, prefix) {
+			path = settingsPath + "." + strings.TrimPrefix(path, prefix)
+		}
+		normalized[path] = value
+	}
+
+	return normalized
+}
+
+// diffLeaves compares the local leaves with the live ones, returning the differences sorted by path
+func diffLeaves(local map[string]string, live map[string]string) []*FieldDiff {
+	diffs := make([]*FieldDiff, 0)
+	for path, localValue := range local {
+		liveValue, found := live[path]
+		if !found {
+			diffs = append(diffs, &FieldDiff{Path: path, Kind: DiffAdded, Local: localValue})
+			continue
+		}
+		if liveValue != localValue {
+			diffs = append(diffs, &FieldDiff{Path: path, Kind: DiffChanged, Local: localValue, Live: liveValue})
+		}
+	}
+	for path, liveValue := range live {
+		_, found := local[path]
+		if !found {
+			diffs = append(diffs, &FieldDiff{Path: path, Kind: DiffRemoved, Live: liveValue})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Path < diffs[j].Path
+	})
+
+	return diffs
+}
+
+// isDestructiveMappingDiff returns true if the provided difference changes or removes an existing mapping field, which
+// cannot be done on an existing index without reindexing it
+func isDestructiveMappingDiff(diff *FieldDiff, mappingsPath string) bool {
+	if diff.Kind == DiffAdded {
+		return false
+	}
+
+	return mappingsPath == "" || strings.HasPrefix(diff.Path, mappingsPath+".")
+}
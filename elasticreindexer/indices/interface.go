@@ -0,0 +1,15 @@
+package indices
+
+import "bytes"
+
+// ClusterHandler defines the behaviour of a client that reads and updates the templates, policies and mappings of a cluster
+type ClusterHandler interface {
+	GetIndexTemplate(templateName string) ([]byte, error)
+	GetPolicy(policyName string) ([]byte, error)
+	GetWriteIndex(alias string) (string, error)
+	GetIndexMapping(index string) ([]byte, error)
+	PutIndexTemplate(templateName string, body *bytes.Buffer) error
+	PutPolicy(policyName string, policy *bytes.Buffer) error
+	PutMapping(index string, body *bytes.Buffer) error
+	IsInterfaceNil() bool
+}
@@ -0,0 +1,82 @@
+package mock
+
+import "bytes"
+
+// ClusterHandlerStub -
+type ClusterHandlerStub struct {
+	GetIndexTemplateCalled func(templateName string) ([]byte, error)
+	GetPolicyCalled        func(policyName string) ([]byte, error)
+	GetWriteIndexCalled    func(alias string) (string, error)
+	GetIndexMappingCalled  func(index string) ([]byte, error)
+	PutIndexTemplateCalled func(templateName string, body *bytes.Buffer) error
+	PutPolicyCalled        func(policyName string, policy *bytes.Buffer) error
+	PutMappingCalled       func(index string, body *bytes.Buffer) error
+}
+
+// GetIndexTemplate -
+func (c *ClusterHandlerStub) GetIndexTemplate(templateName string) ([]byte, error) {
+	if c.GetIndexTemplateCalled != nil {
+		return c.GetIndexTemplateCalled(templateName)
+	}
+
+	return nil, nil
+}
+
+// GetPolicy -
+func (c *ClusterHandlerStub) GetPolicy(policyName string) ([]byte, error) {
+	if c.GetPolicyCalled != nil {
+		return c.GetPolicyCalled(policyName)
+	}
+
+	return nil, nil
+}
+
+// GetWriteIndex -
+func (c *ClusterHandlerStub) GetWriteIndex(alias string) (string, error) {
+	if c.GetWriteIndexCalled != nil {
+		return c.GetWriteIndexCalled(alias)
+	}
+
+	return "", nil
+}
+
+// GetIndexMapping -
+func (c *ClusterHandlerStub) GetIndexMapping(index string) ([]byte, error) {
+	if c.GetIndexMappingCalled != nil {
+		return c.GetIndexMappingCalled(index)
+	}
+
+	return nil, nil
+}
+
+// PutIndexTemplate -
+func (c *ClusterHandlerStub) PutIndexTemplate(templateName string, body *bytes.Buffer) error {
+	if c.PutIndexTemplateCalled != nil {
+		return c.PutIndexTemplateCalled(templateName, body)
+	}
+
+	return nil
+}
+
+// PutPolicy -
+func (c *ClusterHandlerStub) PutPolicy(policyName string, policy *bytes.Buffer) error {
+	if c.PutPolicyCalled != nil {
+		return c.PutPolicyCalled(policyName, policy)
+	}
+
+	return nil
+}
+
+// PutMapping -
+func (c *ClusterHandlerStub) PutMapping(index string, body *bytes.Buffer) error {
+	if c.PutMappingCalled != nil {
+		return c.PutMappingCalled(index, body)
+	}
+
+	return nil
+}
+
+// IsInterfaceNil -
+func (c *ClusterHandlerStub) IsInterfaceNil() bool {
+	return c == nil
+}
@@ -0,0 +1,85 @@
+package indices
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+const (
+	// ResourceTemplate defines an index template
+	ResourceTemplate = "template"
+	// ResourcePolicy defines a lifecycle policy
+	ResourcePolicy = "policy"
+	// ResourceMapping defines the mapping of the write index behind an alias
+	ResourceMapping = "mapping"
+
+	// ActionNone defines a resource that is the same in the local files and in the cluster
+	ActionNone = "none"
+	// ActionCreate defines a resource that exists only in the local files
+	ActionCreate = "create"
+	// ActionUpdate defines a resource that is different in the local files and in the cluster
+	ActionUpdate = "update"
+)
+
+// Change holds the differences of a resource between the local files and the cluster
+type Change struct {
+	Resource    string       `json:"resource"`
+	Name        string       `json:"name"`
+	Index       string       `json:"index"`
+	Action      string       `json:"action"`
+	Destructive bool         `json:"destructive"`
+	Diffs       []*FieldDiff `json:"diffs,omitempty"`
+
+	localBody []byte
+}
+
+// Plan holds the changes needed to bring the cluster to the state from the local files
+type Plan struct {
+	Changes []*Change `json:"changes"`
+}
+
+// HasChanges returns true if at least one resource is different in the local files and in the cluster
+func (p *Plan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.Action != ActionNone {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Print writes the plan in a human readable format: "+" for the fields that exist only in the local files, "-" for the
+// fields that exist only in the cluster and "~" for the changed fields
+func (p *Plan) Print(writer io.Writer) {
+	for _, change := range p.Changes {
+		destructive := ""
+		if change.Destructive {
+			destructive = " (destructive)"
+		}
+		_, _ = fmt.Fprintf(writer, "%s %s: %s%s\n", change.Resource, change.Name, change.Action, destructive)
+
+		for _, diff := range change.Diffs {
+			switch diff.Kind {
+			case DiffAdded:
+				_, _ = fmt.Fprintf(writer, "    + %s: %s\n", diff.Path, diff.Local)
+			case DiffRemoved:
+				_, _ = fmt.Fprintf(writer, "    - %s: %s\n", diff.Path, diff.Live)
+			default:
+				_, _ = fmt.Fprintf(writer, "    ~ %s: %s => %s\n", diff.Path, diff.Live, diff.Local)
+			}
+		}
+	}
+}
+
+// SaveToFile will write the plan as JSON in the provided file
+func (p *Plan) SaveToFile(filePath string) error {
+	planBytes, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, planBytes, 0644)
+}
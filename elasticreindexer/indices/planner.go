@@ -0,0 +1,286 @@
+package indices
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/tidwall/gjson"
+)
+
+var (
+	log = logger.GetOrCreate("indices")
+
+	errNilClusterHandler = errors.New("nil cluster handler")
+)
+
+const (
+	templateSettingsPath = "template.settings"
+	templateMappingsPath = "template.mappings"
+)
+
+// ArgsPlanner holds the arguments needed for creating a new planner
+type ArgsPlanner struct {
+	Client ClusterHandler
+	// Templates holds the content of the local index templates, by index
+	Templates map[string]*bytes.Buffer
+	// Policies holds the content of the local lifecycle policies, by index
+	Policies map[string]*bytes.Buffer
+}
+
+type planner struct {
+	client    ClusterHandler
+	templates map[string]*bytes.Buffer
+	policies  map[string]*bytes.Buffer
+}
+
+// NewPlanner will create a new instance of a component that compares the local templates and policies with the ones
+// from the cluster
+func NewPlanner(args ArgsPlanner) (*planner, error) {
+	if check.IfNil(args.Client) {
+		return nil, errNilClusterHandler
+	}
+
+	return &planner{
+		client:    args.Client,
+		templates: args.Templates,
+		policies:  args.Policies,
+	}, nil
+}
+
+// PolicyName returns the name of the lifecycle policy of the provided index
+func PolicyName(index string) string {
+	return fmt.Sprintf("%s-%s", index, "policy")
+}
+
+// Plan fetches the templates, policies, aliases and mappings from the cluster and compares them with the local files
+func (p *planner) Plan() (*Plan, error) {
+	plan := &Plan{
+		Changes: make([]*Change, 0),
+	}
+
+	for _, index := range sortedKeys(p.templates) {
+		templateChange, err := p.planTemplate(index)
+		if err != nil {
+			return nil, fmt.Errorf("%w while planning the template of index %s", err, index)
+		}
+		plan.Changes = append(plan.Changes, templateChange)
+
+		policy, found := p.policies[index]
+		if found {
+			policyChange, errPolicy := p.planPolicy(index, policy.Bytes())
+			if errPolicy != nil {
+				return nil, fmt.Errorf("%w while planning the policy of index %s", errPolicy, index)
+			}
+			plan.Changes = append(plan.Changes, policyChange)
+		}
+
+		mappingChange, err := p.planMapping(index)
+		if err != nil {
+			return nil, fmt.Errorf("%w while planning the mapping of index %s", err, index)
+		}
+		plan.Changes = append(plan.Changes, mappingChange)
+	}
+
+	return plan, nil
+}
+
+func (p *planner) planTemplate(index string) (*Change, error) {
+	localBody := p.templates[index].Bytes()
+	change := &Change{
+		Resource:  ResourceTemplate,
+		Name:      index,
+		Index:     index,
+		Action:    ActionCreate,
+		localBody: localBody,
+	}
+
+	liveBody, err := p.client.GetIndexTemplate(index)
+	if err != nil {
+		return nil, err
+	}
+	if liveBody == nil {
+		return change, nil
+	}
+
+	local, err := flattenJSON(localBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing the local template", err)
+	}
+	live, err := flattenJSON(liveBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing the live template", err)
+	}
+
+	change.Diffs = diffLeaves(
+		normalizeSettingsPaths(local, templateSettingsPath),
+		normalizeSettingsPaths(live, templateSettingsPath),
+	)
+	setActionAndDestructive(change, templateMappingsPath)
+
+	return change, nil
+}
+
+func (p *planner) planPolicy(index string, localBody []byte) (*Change, error) {
+	name := PolicyName(index)
+	change := &Change{
+		Resource:  ResourcePolicy,
+		Name:      name,
+		Index:     index,
+		Action:    ActionCreate,
+		localBody: localBody,
+	}
+
+	liveBody, err := p.client.GetPolicy(name)
+	if err != nil {
+		return nil, err
+	}
+	if liveBody == nil {
+		return change, nil
+	}
+
+	local, err := flattenJSON(localBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing the local policy", err)
+	}
+	live, err := flattenJSON(liveBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing the live policy", err)
+	}
+
+	change.Diffs = diffLeaves(local, live)
+	change.Action = ActionNone
+	if len(change.Diffs) > 0 {
+		change.Action = ActionUpdate
+	}
+
+	return change, nil
+}
+
+// planMapping compares the mappings from the local template with the mappings of the write index behind the alias
+func (p *planner) planMapping(index string) (*Change, error) {
+	localBody := []byte(gjson.GetBytes(p.templates[index].Bytes(), templateMappingsPath).Raw)
+	change := &Change{
+		Resource:  ResourceMapping,
+		Name:      index,
+		Action:    ActionCreate,
+		localBody: localBody,
+	}
+
+	writeIndex, err := p.client.GetWriteIndex(index)
+	if err != nil {
+		return nil, err
+	}
+	if writeIndex == "" {
+		return change, nil
+	}
+	change.Index = writeIndex
+
+	liveBody, err := p.client.GetIndexMapping(writeIndex)
+	if err != nil {
+		return nil, err
+	}
+
+	local, err := flattenJSON(localBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing the local mappings", err)
+	}
+	live, err := flattenJSON(liveBody)
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing the live mappings", err)
+	}
+
+	change.Diffs = diffLeaves(local, live)
+	setActionAndDestructive(change, "")
+
+	return change, nil
+}
+
+func setActionAndDestructive(change *Change, mappingsPath string) {
+	change.Action = ActionNone
+	if len(change.Diffs) > 0 {
+		change.Action = ActionUpdate
+	}
+
+	for _, diff := range change.Diffs {
+		if isDestructiveMappingDiff(diff, mappingsPath) {
+			change.Destructive = true
+			return
+		}
+	}
+}
+
+// Apply will update the templates and policies that are different in the cluster, and will add the new fields to the
+// mappings of the write indices. The destructive changes of the templates are applied only if allowDestructive is set,
+// while the destructive changes of the existing indices are never applied, as they need a reindexing
+func (p *planner) Apply(plan *Plan, allowDestructive bool) error {
+	numSkipped := 0
+	for _, change := range plan.Changes {
+		if change.Action == ActionNone {
+			continue
+		}
+
+		applied, err := p.applyChange(change, allowDestructive)
+		if err != nil {
+			return fmt.Errorf("%w while applying the change of %s %s", err, change.Resource, change.Name)
+		}
+		if !applied {
+			numSkipped++
+		}
+	}
+
+	if numSkipped > 0 {
+		return fmt.Errorf("%d changes were not applied", numSkipped)
+	}
+
+	return nil
+}
+
+func (p *planner) applyChange(change *Change, allowDestructive bool) (bool, error) {
+	switch change.Resource {
+	case ResourceTemplate:
+		if change.Destructive && !allowDestructive {
+			log.Warn("skipping destructive template change, start with the allow destructive flag to apply it", "template", change.Name)
+			return false, nil
+		}
+
+		log.Info("updating index template", "template", change.Name, "action", change.Action)
+		return true, p.client.PutIndexTemplate(change.Name, bytes.NewBuffer(change.localBody))
+	case ResourcePolicy:
+		log.Info("updating lifecycle policy", "policy", change.Name, "action", change.Action)
+		return true, p.client.PutPolicy(change.Name, bytes.NewBuffer(change.localBody))
+	case ResourceMapping:
+		if change.Action == ActionCreate {
+			log.Warn("the alias does not exist, run the tool without the plan flags to create it", "alias", change.Name)
+			return false, nil
+		}
+		if change.Destructive {
+			log.Warn("skipping destructive mapping change, the index has to be reindexed or rolled over with the new template",
+				"alias", change.Name, "index", change.Index)
+			return false, nil
+		}
+
+		log.Info("adding the new fields to the mapping", "alias", change.Name, "index", change.Index)
+		return true, p.client.PutMapping(change.Index, bytes.NewBuffer(change.localBody))
+	default:
+		return false, fmt.Errorf("unknown resource %s", change.Resource)
+	}
+}
+
+func sortedKeys(buffers map[string]*bytes.Buffer) []string {
+	keys := make([]string, 0, len(buffers))
+	for key := range buffers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (p *planner) IsInterfaceNil() bool {
+	return p == nil
+}
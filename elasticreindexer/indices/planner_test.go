@@ -0,0 +1,129 @@
+package indices
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/indices/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const localTemplate = `{
+  "index_patterns": ["blocks-*"],
+  "template": {
+    "settings": {
+      "index.lifecycle.name": "blocks-policy",
+      "number_of_shards": 5
+    },
+    "mappings": {
+      "properties": {
+        "nonce": {"type": "long"},
+        "hash": {"type": "keyword"},
+        "epoch": {"type": "long"}
+      }
+    }
+  }
+}`
+
+const localPolicy = `{"policy":{"phases":{"hot":{"min_age":"0ms","actions":{"rollover":{"max_size":"50GB"}}}}}}`
+
+func createPlanner(t *testing.T, client ClusterHandler) *planner {
+	p, err := NewPlanner(ArgsPlanner{
+		Client:    client,
+		Templates: map[string]*bytes.Buffer{"blocks": bytes.NewBufferString(localTemplate)},
+		Policies:  map[string]*bytes.Buffer{"blocks": bytes.NewBufferString(localPolicy)},
+	})
+	require.NoError(t, err)
+
+	return p
+}
+
+func TestNewPlanner_NilClientShouldErr(t *testing.T) {
+	t.Parallel()
+
+	p, err := NewPlanner(ArgsPlanner{})
+	require.Nil(t, p)
+	require.Equal(t, errNilClusterHandler, err)
+}
+
+func TestPlanner_PlanMissingResources(t *testing.T) {
+	t.Parallel()
+
+	p := createPlanner(t, &mock.ClusterHandlerStub{})
+	plan, err := p.Plan()
+	require.NoError(t, err)
+	require.Len(t, plan.Changes, 3)
+	for _, change := range plan.Changes {
+		require.Equal(t, ActionCreate, change.Action)
+	}
+}
+
+func TestPlanner_PlanAndApply(t *testing.T) {
+	t.Parallel()
+
+	client := &mock.ClusterHandlerStub{
+		GetIndexTemplateCalled: func(_ string) ([]byte, error) {
+			// the settings are returned nested and as strings, and the epoch field has another type
+			return []byte(`{"index_patterns":["blocks-*"],"composed_of":[],"template":{"settings":{"index":{"lifecycle":{"name":"blocks-policy"},"number_of_shards":"5"}},
+				"mappings":{"properties":{"nonce":{"type":"long"},"hash":{"type":"keyword"},"epoch":{"type":"integer"}}}}}`), nil
+		},
+		GetPolicyCalled: func(name string) ([]byte, error) {
+			require.Equal(t, "blocks-policy", name)
+			return []byte(localPolicy), nil
+		},
+		GetWriteIndexCalled: func(alias string) (string, error) {
+			require.Equal(t, "blocks", alias)
+			return "blocks-000002", nil
+		},
+		GetIndexMappingCalled: func(index string) ([]byte, error) {
+			require.Equal(t, "blocks-000002", index)
+			return []byte(`{"properties":{"nonce":{"type":"long"}}}`), nil
+		},
+	}
+	p := createPlanner(t, client)
+
+	plan, err := p.Plan()
+	require.NoError(t, err)
+	require.True(t, plan.HasChanges())
+	require.Len(t, plan.Changes, 3)
+
+	templateChange := plan.Changes[0]
+	require.Equal(t, ActionUpdate, templateChange.Action)
+	require.True(t, templateChange.Destructive)
+	require.Equal(t, []*FieldDiff{{
+		Path:  "template.mappings.properties.epoch.type",
+		Kind:  DiffChanged,
+		Local: "long",
+		Live:  "integer",
+	}}, templateChange.Diffs)
+
+	require.Equal(t, ActionNone, plan.Changes[1].Action)
+
+	mappingChange := plan.Changes[2]
+	require.Equal(t, "blocks-000002", mappingChange.Index)
+	require.Equal(t, ActionUpdate, mappingChange.Action)
+	require.False(t, mappingChange.Destructive)
+	require.Len(t, mappingChange.Diffs, 2)
+
+	putTemplateCalled := false
+	putMappingCalled := false
+	client.PutIndexTemplateCalled = func(_ string, _ *bytes.Buffer) error {
+		putTemplateCalled = true
+		return nil
+	}
+	client.PutMappingCalled = func(index string, body *bytes.Buffer) error {
+		putMappingCalled = true
+		require.Equal(t, "blocks-000002", index)
+		require.Contains(t, body.String(), "epoch")
+		return nil
+	}
+
+	err = p.Apply(plan, false)
+	require.Error(t, err)
+	require.False(t, putTemplateCalled)
+	require.True(t, putMappingCalled)
+
+	err = p.Apply(plan, true)
+	require.NoError(t, err)
+	require.True(t, putTemplateCalled)
+}
@@ -0,0 +1,211 @@
+package metrics
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	logger "github.com/multiversx/mx-chain-logger-go"
+)
+
+var log = logger.GetOrCreate("metrics")
+
+type seriesKey struct {
+	index    string
+	interval string
+}
+
+// series holds the counters of an index, or of an interval of an index
+type series struct {
+	docsRead           uint64
+	docsWritten        uint64
+	bytesWritten       uint64
+	numBulks           uint64
+	bulkLatencySeconds float64
+	numRetriedItems    uint64
+	expectedDocs       uint64
+}
+
+func (s *series) add(other *series) {
+	s.docsRead += other.docsRead
+	s.docsWritten += other.docsWritten
+	s.bytesWritten += other.bytesWritten
+	s.numBulks += other.numBulks
+	s.bulkLatencySeconds += other.bulkLatencySeconds
+	s.numRetriedItems += other.numRetriedItems
+	s.expectedDocs += other.expectedDocs
+}
+
+// collector keeps the progress and throughput counters of the reindexing, per index and per interval
+type collector struct {
+	mut       sync.RWMutex
+	series    map[seriesKey]*series
+	startTime time.Time
+	now       func() time.Time
+}
+
+// NewCollector will create a new instance of a metrics collector
+func NewCollector() *collector {
+	return &collector{
+		series:    make(map[seriesKey]*series),
+		startTime: time.Now(),
+		now:       time.Now,
+	}
+}
+
+func (c *collector) getSeries(index string, interval string) *series {
+	key := seriesKey{
+		index:    index,
+		interval: interval,
+	}
+
+	s, found := c.series[key]
+	if !found {
+		s = &series{}
+		c.series[key] = s
+	}
+
+	return s
+}
+
+// AddExpectedDocuments adds the number of source documents that are going to be read for the provided interval
+func (c *collector) AddExpectedDocuments(index string, interval string, numDocs uint64) {
+	c.mut.Lock()
+	c.getSeries(index, interval).expectedDocs += numDocs
+	c.mut.Unlock()
+}
+
+// AddDocumentsRead adds the number of documents read from the source for the provided interval
+func (c *collector) AddDocumentsRead(index string, interval string, numDocs uint64) {
+	c.mut.Lock()
+	c.getSeries(index, interval).docsRead += numDocs
+	c.mut.Unlock()
+}
+
+// ObserveBulk records a bulk request written in the destination for the provided interval
+func (c *collector) ObserveBulk(index string, interval string, numDocs int, numBytes int, duration time.Duration) {
+	c.mut.Lock()
+	s := c.getSeries(index, interval)
+	s.numBulks++
+	s.docsWritten += uint64(numDocs)
+	s.bytesWritten += uint64(numBytes)
+	s.bulkLatencySeconds += duration.Seconds()
+	c.mut.Unlock()
+}
+
+// AddRetriedItems adds the number of bulk items that were sent again to the destination. The destination client knows
+// only the name of the written index, so the retries are not split by interval
+func (c *collector) AddRetriedItems(index string, numItems int) {
+	c.mut.Lock()
+	c.getSeries(index, "").numRetriedItems += uint64(numItems)
+	c.mut.Unlock()
+}
+
+// snapshot returns a copy of all the series and the totals per index
+func (c *collector) snapshot() (map[seriesKey]series, map[string]*series) {
+	c.mut.RLock()
+	defer c.mut.RUnlock()
+
+	allSeries := make(map[seriesKey]series, len(c.series))
+	perIndex := make(map[string]*series)
+	for key, s := range c.series {
+		allSeries[key] = *s
+
+		indexSeries, found := perIndex[key.index]
+		if !found {
+			indexSeries = &series{}
+			perIndex[key.index] = indexSeries
+		}
+		indexSeries.add(s)
+	}
+
+	return allSeries, perIndex
+}
+
+// estimateRemaining returns the estimated time until all the expected documents are read, based on the average read
+// throughput since the collector was created. It returns false if there is not enough data for an estimation
+func (c *collector) estimateRemaining(s *series, elapsed time.Duration) (time.Duration, bool) {
+	if s.docsRead == 0 || s.expectedDocs == 0 || elapsed <= 0 {
+		return 0, false
+	}
+	if s.docsRead >= s.expectedDocs {
+		return 0, true
+	}
+
+	docsPerSecond := float64(s.docsRead) / elapsed.Seconds()
+	remainingSeconds := float64(s.expectedDocs-s.docsRead) / docsPerSecond
+
+	return time.Duration(remainingSeconds * float64(time.Second)), true
+}
+
+// StartSummaryLoop prints a summary line with the progress of every index at the provided interval, until the context
+// is done
+func (c *collector) StartSummaryLoop(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				c.printSummary()
+			}
+		}
+	}()
+}
+
+func (c *collector) printSummary() {
+	_, perIndex := c.snapshot()
+	elapsed := c.now().Sub(c.startTime)
+
+	indices := make([]string, 0, len(perIndex))
+	total := &series{}
+	for index, s := range perIndex {
+		indices = append(indices, index)
+		total.add(s)
+	}
+	sort.Strings(indices)
+
+	for _, index := range indices {
+		s := perIndex[index]
+		eta, ok := c.estimateRemaining(s, elapsed)
+		log.Debug("index progress", "index", index, "docs read", s.docsRead, "expected docs", s.expectedDocs,
+			"docs written", s.docsWritten, "retried items", s.numRetriedItems, "eta", formatEta(eta, ok))
+	}
+
+	docsPerSecond := 0.0
+	if elapsed > 0 {
+		docsPerSecond = float64(total.docsWritten) / elapsed.Seconds()
+	}
+	eta, ok := c.estimateRemaining(total, elapsed)
+	log.Info("reindexing progress",
+		"indices", len(indices),
+		"docs read", total.docsRead,
+		"expected docs", total.expectedDocs,
+		"docs written", total.docsWritten,
+		"MB written", total.bytesWritten/(1024*1024),
+		"docs/s", int64(docsPerSecond),
+		"retried items", total.numRetriedItems,
+		"elapsed", elapsed.Truncate(time.Second),
+		"eta", formatEta(eta, ok))
+}
+
+func formatEta(eta time.Duration, ok bool) string {
+	if !ok {
+		return "unknown"
+	}
+
+	return eta.Truncate(time.Second).String()
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (c *collector) IsInterfaceNil() bool {
+	return c == nil
+}
@@ -0,0 +1,48 @@
+package metrics
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestCollector_WritePrometheus(t *testing.T) {
+	t.Parallel()
+
+	c := NewCollector()
+	c.now = func() time.Time {
+		return c.startTime.Add(10 * time.Second)
+	}
+
+	c.AddExpectedDocuments("blocks", "100-200", 300)
+	c.AddDocumentsRead("blocks", "100-200", 100)
+	c.ObserveBulk("blocks", "100-200", 100, 2048, 500*time.Millisecond)
+	c.ObserveBulk("blocks", "100-200", 0, 10, 500*time.Millisecond)
+	c.AddRetriedItems("blocks", 3)
+	c.AddDocumentsRead("accounts", "", 5)
+
+	buff := &bytes.Buffer{}
+	c.WritePrometheus(buff)
+	output := buff.String()
+
+	require.Contains(t, output, "# TYPE elasticreindexer_documents_read_total counter\n")
+	require.Contains(t, output, `elasticreindexer_documents_read_total{index="blocks",interval="100-200"} 100`+"\n")
+	require.Contains(t, output, `elasticreindexer_documents_read_total{index="accounts"} 5`+"\n")
+	require.Contains(t, output, `elasticreindexer_documents_written_total{index="blocks",interval="100-200"} 100`+"\n")
+	require.Contains(t, output, `elasticreindexer_bytes_written_total{index="blocks",interval="100-200"} 2058`+"\n")
+	require.Contains(t, output, `elasticreindexer_bulk_retried_items_total{index="blocks"} 3`+"\n")
+	require.Contains(t, output, `elasticreindexer_bulk_latency_seconds_sum{index="blocks",interval="100-200"} 1`+"\n")
+	require.Contains(t, output, `elasticreindexer_bulk_latency_seconds_count{index="blocks",interval="100-200"} 2`+"\n")
+	// 100 documents read in 10 seconds, 200 remaining
+	require.Contains(t, output, `elasticreindexer_eta_seconds{index="blocks"} 20`+"\n")
+	require.NotContains(t, output, `elasticreindexer_eta_seconds{index="accounts"}`)
+	require.Contains(t, output, "elasticreindexer_uptime_seconds 10\n")
+}
+
+func TestEscapeLabelValue(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `a\"b\\c\n`, escapeLabelValue("a\"b\\c\n"))
+}
@@ -0,0 +1,176 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	metricsPath     = "/metrics"
+	metricsPrefix   = "elasticreindexer_"
+	shutdownTimeout = 5 * time.Second
+)
+
+type metricDescription struct {
+	name       string
+	help       string
+	metricType string
+	value      func(s *series) string
+}
+
+var seriesMetrics = []metricDescription{
+	{
+		name:       "documents_read_total",
+		help:       "Number of documents read from the source",
+		metricType: "counter",
+		value:      func(s *series) string { return formatUint(s.docsRead) },
+	},
+	{
+		name:       "documents_written_total",
+		help:       "Number of documents written in the destination",
+		metricType: "counter",
+		value:      func(s *series) string { return formatUint(s.docsWritten) },
+	},
+	{
+		name:       "bytes_written_total",
+		help:       "Number of bytes of the bulk requests written in the destination",
+		metricType: "counter",
+		value:      func(s *series) string { return formatUint(s.bytesWritten) },
+	},
+	{
+		name:       "bulk_retried_items_total",
+		help:       "Number of bulk items sent again to the destination after being rejected",
+		metricType: "counter",
+		value:      func(s *series) string { return formatUint(s.numRetriedItems) },
+	},
+	{
+		name:       "source_documents",
+		help:       "Number of source documents expected to be read",
+		metricType: "gauge",
+		value:      func(s *series) string { return formatUint(s.expectedDocs) },
+	},
+}
+
+// WritePrometheus writes all the metrics in the Prometheus text exposition format
+func (c *collector) WritePrometheus(buff *bytes.Buffer) {
+	allSeries, perIndex := c.snapshot()
+	elapsed := c.now().Sub(c.startTime)
+
+	keys := make([]seriesKey, 0, len(allSeries))
+	for key := range allSeries {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].index != keys[j].index {
+			return keys[i].index < keys[j].index
+		}
+		return keys[i].interval < keys[j].interval
+	})
+
+	for _, metric := range seriesMetrics {
+		writeHeader(buff, metric.name, metric.help, metric.metricType)
+		for _, key := range keys {
+			s := allSeries[key]
+			writeSample(buff, metric.name, key, metric.value(&s))
+		}
+	}
+
+	writeHeader(buff, "bulk_latency_seconds", "Duration of the bulk requests written in the destination, retries included", "summary")
+	for _, key := range keys {
+		s := allSeries[key]
+		writeSample(buff, "bulk_latency_seconds_sum", key, formatFloat(s.bulkLatencySeconds))
+		writeSample(buff, "bulk_latency_seconds_count", key, formatUint(s.numBulks))
+	}
+
+	indices := make([]string, 0, len(perIndex))
+	for index := range perIndex {
+		indices = append(indices, index)
+	}
+	sort.Strings(indices)
+
+	writeHeader(buff, "eta_seconds", "Estimated time until all the source documents of the index are read", "gauge")
+	for _, index := range indices {
+		eta, ok := c.estimateRemaining(perIndex[index], elapsed)
+		if !ok {
+			continue
+		}
+		writeSample(buff, "eta_seconds", seriesKey{index: index}, formatFloat(eta.Seconds()))
+	}
+
+	writeHeader(buff, "uptime_seconds", "Time since the reindexing started", "gauge")
+	buff.WriteString(fmt.Sprintf("%suptime_seconds %s\n", metricsPrefix, formatFloat(elapsed.Seconds())))
+}
+
+// ServeHTTP serves the metrics in the Prometheus text exposition format
+func (c *collector) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	buff := &bytes.Buffer{}
+	c.WritePrometheus(buff)
+
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_, _ = w.Write(buff.Bytes())
+}
+
+// StartServer will serve the metrics of the provided handler on the provided address, until the context is done
+func StartServer(ctx context.Context, address string, handler http.Handler) error {
+	mux := http.NewServeMux()
+	mux.Handle(metricsPath, handler)
+	server := &http.Server{
+		Addr:    address,
+		Handler: mux,
+	}
+
+	errChan := make(chan error, 1)
+	go func() {
+		errChan <- server.ListenAndServe()
+	}()
+
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(shutdownCtx)
+	}()
+
+	// the server has a short time to fail, e.g. if the address is already in use
+	select {
+	case err := <-errChan:
+		return err
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	log.Info("serving metrics", "address", address, "path", metricsPath)
+
+	return nil
+}
+
+func writeHeader(buff *bytes.Buffer, name string, help string, metricType string) {
+	buff.WriteString(fmt.Sprintf("# HELP %s%s %s\n", metricsPrefix, name, help))
+	buff.WriteString(fmt.Sprintf("# TYPE %s%s %s\n", metricsPrefix, name, metricType))
+}
+
+func writeSample(buff *bytes.Buffer, name string, key seriesKey, value string) {
+	labels := fmt.Sprintf(`index="%s"`, escapeLabelValue(key.index))
+	if key.interval != "" {
+		labels += fmt.Sprintf(`,interval="%s"`, escapeLabelValue(key.interval))
+	}
+
+	buff.WriteString(fmt.Sprintf("%s%s{%s} %s\n", metricsPrefix, name, labels, value))
+}
+
+func escapeLabelValue(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+	return replacer.Replace(value)
+}
+
+func formatUint(value uint64) string {
+	return fmt.Sprintf("%d", value)
+}
+
+func formatFloat(value float64) string {
+	return fmt.Sprintf("%g", value)
+}
@@ -0,0 +1,106 @@
+package process
+
+import (
+	"errors"
+)
+
+// minIntervalDuration is the duration, in seconds, of the intervals that are not split anymore, no matter how many
+// documents they hold
+const minIntervalDuration = 1
+
+// intervalCounter returns the number of documents having the timestamp in [start, stop)
+type intervalCounter func(start, stop int64) (uint64, error)
+
+type countedInterval struct {
+	interval
+	count uint64
+}
+
+// computeBalancedIntervals splits the provided time range into intervals holding about the same number of documents.
+// The intervals having more documents than the target are split in halves until they fit, then the neighbouring small
+// intervals are merged back while they fit. If the target is 0, the range is split in about numIntervals intervals.
+// Like the counter, the intervals are half-open, so the last one stops right after endTime
+func computeBalancedIntervals(startTime, endTime int64, numIntervals int64, targetDocs uint64, counter intervalCounter) ([]*interval, error) {
+	if startTime > endTime {
+		return nil, errors.New("blockchain start time is greater than current timestamp")
+	}
+
+	stopTime := endTime + 1
+	total, err := counter(startTime, stopTime)
+	if err != nil {
+		return nil, err
+	}
+	if total == 0 {
+		return computeIntervals(startTime, endTime, 1)
+	}
+
+	if targetDocs == 0 {
+		if numIntervals < 1 {
+			numIntervals = 1
+		}
+		targetDocs = (total + uint64(numIntervals) - 1) / uint64(numIntervals)
+	}
+
+	splitIntervals := make([]*countedInterval, 0)
+	splitIntervals, err = splitInterval(splitIntervals, startTime, stopTime, total, targetDocs, counter)
+	if err != nil {
+		return nil, err
+	}
+
+	return mergeIntervals(splitIntervals, targetDocs), nil
+}
+
+func splitInterval(
+	intervals []*countedInterval,
+	start int64,
+	stop int64,
+	count uint64,
+	targetDocs uint64,
+	counter intervalCounter,
+) ([]*countedInterval, error) {
+	if count <= targetDocs || stop-start <= minIntervalDuration {
+		return append(intervals, &countedInterval{
+			interval: interval{start: start, stop: stop},
+			count:    count,
+		}), nil
+	}
+
+	middle := start + (stop-start)/2
+	countLeft, err := counter(start, middle)
+	if err != nil {
+		return nil, err
+	}
+	countRight, err := counter(middle, stop)
+	if err != nil {
+		return nil, err
+	}
+
+	intervals, err = splitInterval(intervals, start, middle, countLeft, targetDocs, counter)
+	if err != nil {
+		return nil, err
+	}
+
+	return splitInterval(intervals, middle, stop, countRight, targetDocs, counter)
+}
+
+func mergeIntervals(intervals []*countedInterval, targetDocs uint64) []*interval {
+	merged := make([]*interval, 0, len(intervals))
+	var current *countedInterval
+	for _, counted := range intervals {
+		if current != nil && current.count+counted.count <= targetDocs {
+			current.stop = counted.stop
+			current.count += counted.count
+			continue
+		}
+
+		if current != nil {
+			merged = append(merged, &interval{start: current.start, stop: current.stop})
+		}
+		current = &countedInterval{
+			interval: counted.interval,
+			count:    counted.count,
+		}
+	}
+
+	return append(merged, &interval{start: current.start, stop: current.stop})
+}
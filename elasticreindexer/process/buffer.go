@@ -2,7 +2,7 @@ package process
 
 import "bytes"
 
-// bulkSizeThreshold is the constant for the maximum size of one bulk request that is sent to the elasticsearch database
+// bulkSizeThreshold is the default maximum size of one bulk request that is sent to the elasticsearch database
 const bulkSizeThreshold = 838860 // 0.8MB
 
 // BufferSlice extend structure bytes.Buffer with new methods
@@ -12,11 +12,15 @@ type bufferSlice struct {
 	idx               int
 }
 
-// newBufferSlice will create a new buffer
-func newBufferSlice() *bufferSlice {
+// newBufferSlice will create a new buffer with bulks of at most the provided size
+func newBufferSlice(bulkSize int) *bufferSlice {
+	if bulkSize <= 0 {
+		bulkSize = bulkSizeThreshold
+	}
+
 	return &bufferSlice{
 		buffSlice:         make([]*bytes.Buffer, 0),
-		bulkSizeThreshold: bulkSizeThreshold,
+		bulkSizeThreshold: bulkSize,
 		idx:               0,
 	}
 }
@@ -0,0 +1,123 @@
+package process
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// defaultDestinationName is the name of the output cluster when a single one is configured
+const defaultDestinationName = "output"
+
+// Destination holds an output where the documents are written and the checkpoint of the documents written in it
+type Destination struct {
+	Name       string
+	Elastic    ElasticClientHandler
+	Checkpoint CheckpointHandler
+}
+
+type destination struct {
+	name       string
+	elastic    ElasticClientHandler
+	checkpoint CheckpointHandler
+}
+
+// fanOut writes the same pages in all the destinations that still have to copy an index or an interval. A destination
+// that fails is dropped from the copy, which continues for the other ones
+type fanOut struct {
+	mut          sync.RWMutex
+	destinations []*destination
+	failed       map[string]error
+}
+
+func newFanOut(destinations []*destination) *fanOut {
+	return &fanOut{
+		destinations: destinations,
+		failed:       make(map[string]error),
+	}
+}
+
+// active returns the destinations that did not fail so far
+func (fo *fanOut) active() []*destination {
+	fo.mut.RLock()
+	defer fo.mut.RUnlock()
+
+	active := make([]*destination, 0, len(fo.destinations))
+	for _, dest := range fo.destinations {
+		_, hasFailed := fo.failed[dest.name]
+		if !hasFailed {
+			active = append(active, dest)
+		}
+	}
+
+	return active
+}
+
+// write calls the provided handler for every active destination, concurrently. An error is returned only if all the
+// destinations failed, case when the copy cannot continue
+func (fo *fanOut) write(handler func(dest *destination) error) error {
+	active := fo.active()
+	if len(active) == 0 {
+		return fo.err()
+	}
+	if len(active) == 1 {
+		// a single destination keeps the error as it is, the copy stopping at the first failure
+		err := handler(active[0])
+		if err != nil {
+			fo.fail(active[0], err)
+			return err
+		}
+
+		return nil
+	}
+
+	wg := &sync.WaitGroup{}
+	wg.Add(len(active))
+	for _, dest := range active {
+		go func(d *destination) {
+			defer wg.Done()
+
+			err := handler(d)
+			if err != nil {
+				log.Error("destination failed, the copy continues for the other destinations", "destination", d.name, "error", err)
+				fo.fail(d, err)
+			}
+		}(dest)
+	}
+	wg.Wait()
+
+	if len(fo.active()) == 0 {
+		return fo.err()
+	}
+
+	return nil
+}
+
+func (fo *fanOut) fail(dest *destination, err error) {
+	fo.mut.Lock()
+	fo.failed[dest.name] = err
+	fo.mut.Unlock()
+}
+
+// err returns an error with the failures of all the destinations that failed, or nil if all of them succeeded
+func (fo *fanOut) err() error {
+	fo.mut.RLock()
+	defer fo.mut.RUnlock()
+
+	if len(fo.failed) == 0 {
+		return nil
+	}
+	if len(fo.destinations) == 1 {
+		return fo.failed[fo.destinations[0].name]
+	}
+
+	messages := make([]string, 0, len(fo.failed))
+	for _, dest := range fo.destinations {
+		err, hasFailed := fo.failed[dest.name]
+		if hasFailed {
+			messages = append(messages, fmt.Sprintf("%s: %s", dest.name, err.Error()))
+		}
+	}
+
+	return fmt.Errorf("%d of %d destinations failed: %s", len(fo.failed), len(fo.destinations), strings.Join(messages, "; "))
+}
@@ -0,0 +1,159 @@
+package process
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+)
+
+const (
+	defaultPollIntervalInSeconds   = 30
+	defaultOverlapInSeconds        = 120
+	defaultResyncIntervalInMinutes = 60
+)
+
+type follower struct {
+	reindexerClient      ReindexerHandler
+	checkpoint           CheckpointHandler
+	indicesNoTimestamp   []string
+	indicesWithTimestamp []string
+	pollInterval         time.Duration
+	overlap              int64
+	resyncInterval       time.Duration
+	lastTimestamps       map[string]int64
+	getCurrentTimestamp  func() int64
+}
+
+// NewFollower will create a new instance of a component that keeps the destination cluster in sync with the source
+// cluster after the initial copy
+func NewFollower(
+	reindexer ReindexerHandler,
+	checkpointHandler CheckpointHandler,
+	indicesCfg config.IndicesConfig,
+	followCfg config.FollowConfig,
+) (*follower, error) {
+	if reindexer == nil {
+		return nil, errors.New("nil ReindexerHandler")
+	}
+	if check.IfNil(checkpointHandler) {
+		return nil, errNilCheckpointHandler
+	}
+
+	pollIntervalInSeconds := followCfg.PollIntervalInSeconds
+	if pollIntervalInSeconds <= 0 {
+		pollIntervalInSeconds = defaultPollIntervalInSeconds
+	}
+	overlapInSeconds := followCfg.OverlapInSeconds
+	if overlapInSeconds <= 0 {
+		overlapInSeconds = defaultOverlapInSeconds
+	}
+	resyncIntervalInMinutes := followCfg.ResyncIntervalInMinutes
+	if resyncIntervalInMinutes <= 0 {
+		resyncIntervalInMinutes = defaultResyncIntervalInMinutes
+	}
+
+	indicesWithTimestamp := indicesCfg.WithTimestamp.IndicesWithTimestamp
+	if !indicesCfg.WithTimestamp.Enabled {
+		indicesWithTimestamp = nil
+	}
+
+	return &follower{
+		reindexerClient:      reindexer,
+		checkpoint:           checkpointHandler,
+		indicesNoTimestamp:   indicesCfg.Indices,
+		indicesWithTimestamp: indicesWithTimestamp,
+		pollInterval:         time.Duration(pollIntervalInSeconds) * time.Second,
+		overlap:              int64(overlapInSeconds),
+		resyncInterval:       time.Duration(resyncIntervalInMinutes) * time.Minute,
+		lastTimestamps:       make(map[string]int64),
+		getCurrentTimestamp: func() int64 {
+			return time.Now().Unix()
+		},
+	}, nil
+}
+
+// Follow will keep copying the new documents until the provided context is done
+func (f *follower) Follow(ctx context.Context) {
+	f.initLastTimestamps()
+
+	log.Info("following the source cluster",
+		"poll interval", f.pollInterval,
+		"overlap in seconds", f.overlap,
+		"resync interval", f.resyncInterval)
+
+	pollTicker := time.NewTicker(f.pollInterval)
+	defer pollTicker.Stop()
+	resyncTicker := time.NewTicker(f.resyncInterval)
+	defer resyncTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			log.Info("stopped following the source cluster")
+			return
+		case <-pollTicker.C:
+			f.pollIndicesWithTimestamp()
+		case <-resyncTicker.C:
+			f.resyncIndicesNoTimestamp()
+		}
+	}
+}
+
+// initLastTimestamps sets the timestamp up to which every index was copied. The initial copy saves in the checkpoint
+// the intervals it used, so the last copied timestamp is the end of the last interval
+func (f *follower) initLastTimestamps() {
+	currentTimestamp := f.getCurrentTimestamp()
+	for _, index := range f.indicesWithTimestamp {
+		if index == "" {
+			continue
+		}
+
+		lastTimestamp := int64(0)
+		for _, interv := range f.checkpoint.GetIntervals(index) {
+			if interv.Stop > lastTimestamp {
+				lastTimestamp = interv.Stop
+			}
+		}
+		if lastTimestamp == 0 {
+			lastTimestamp = currentTimestamp
+		}
+
+		f.lastTimestamps[index] = lastTimestamp
+	}
+}
+
+func (f *follower) pollIndicesWithTimestamp() {
+	currentTimestamp := f.getCurrentTimestamp()
+	for _, index := range f.indicesWithTimestamp {
+		if index == "" {
+			continue
+		}
+
+		start := f.lastTimestamps[index] - f.overlap
+		err := f.reindexerClient.SyncIndexWithTimestamp(index, start, currentTimestamp)
+		if err != nil {
+			log.Warn("cannot copy the new documents, will retry on the next poll", "index", index, "error", err)
+			continue
+		}
+
+		log.Debug("copied the new documents", "index", index, "start", start, "stop", currentTimestamp)
+		f.lastTimestamps[index] = currentTimestamp
+	}
+}
+
+func (f *follower) resyncIndicesNoTimestamp() {
+	for _, index := range f.indicesNoTimestamp {
+		if index == "" {
+			continue
+		}
+
+		log.Info("resyncing index", "index", index)
+		err := f.reindexerClient.SyncIndex(index)
+		if err != nil {
+			log.Warn("cannot resync index, will retry on the next resync", "index", index, "error", err)
+		}
+	}
+}
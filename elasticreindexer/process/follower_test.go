@@ -0,0 +1,86 @@
+package process
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/process/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFollower_PollIndicesWithTimestamp(t *testing.T) {
+	type syncCall struct {
+		index string
+		start int64
+		stop  int64
+	}
+	calls := make([]syncCall, 0)
+	failBlocks := true
+	reindexer := &mock.ReindexerHandlerStub{
+		SyncIndexWithTimestampCalled: func(index string, start, stop int64) error {
+			calls = append(calls, syncCall{index: index, start: start, stop: stop})
+			if index == "blocks" && failBlocks {
+				return errors.New("local error")
+			}
+			return nil
+		},
+	}
+	checkpointHandler := &mock.CheckpointHandlerStub{
+		GetIntervalsCalled: func(index string) []checkpoint.IntervalProgress {
+			if index != "transactions" {
+				return nil
+			}
+			return []checkpoint.IntervalProgress{{Start: 100, Stop: 500}, {Start: 500, Stop: 900}}
+		},
+	}
+
+	indicesCfg := config.IndicesConfig{}
+	indicesCfg.WithTimestamp.Enabled = true
+	indicesCfg.WithTimestamp.IndicesWithTimestamp = []string{"transactions", "blocks"}
+
+	f, err := NewFollower(reindexer, checkpointHandler, indicesCfg, config.FollowConfig{OverlapInSeconds: 10})
+	require.NoError(t, err)
+
+	currentTimestamp := int64(1000)
+	f.getCurrentTimestamp = func() int64 {
+		return currentTimestamp
+	}
+	f.initLastTimestamps()
+
+	currentTimestamp = 1030
+	f.pollIndicesWithTimestamp()
+	require.Equal(t, []syncCall{
+		{index: "transactions", start: 890, stop: 1030},
+		{index: "blocks", start: 990, stop: 1030},
+	}, calls)
+
+	// the failed index should be retried from the same timestamp
+	calls = calls[:0]
+	failBlocks = false
+	currentTimestamp = 1060
+	f.pollIndicesWithTimestamp()
+	require.Equal(t, []syncCall{
+		{index: "transactions", start: 1020, stop: 1060},
+		{index: "blocks", start: 990, stop: 1060},
+	}, calls)
+}
+
+func TestFollower_ResyncIndicesNoTimestamp(t *testing.T) {
+	synced := make([]string, 0)
+	reindexer := &mock.ReindexerHandlerStub{
+		SyncIndexCalled: func(index string) error {
+			synced = append(synced, index)
+			return errors.New("local error")
+		},
+	}
+
+	indicesCfg := config.IndicesConfig{
+		Indices: []string{"accounts", "", "tokens"},
+	}
+	f, _ := NewFollower(reindexer, &mock.CheckpointHandlerStub{}, indicesCfg, config.FollowConfig{})
+
+	f.resyncIndicesNoTimestamp()
+	require.Equal(t, []string{"accounts", "tokens"}, synced)
+}
@@ -0,0 +1,90 @@
+package process
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+)
+
+// indexOverrides resolves the settings of an index, falling back to the general ones if the index has no override
+type indexOverrides map[string]config.IndexOverrideConfig
+
+func newIndexOverrides(overrides map[string]config.IndexOverrideConfig) (indexOverrides, error) {
+	for index, override := range overrides {
+		if override.Query == "" {
+			continue
+		}
+
+		query := object{}
+		err := json.Unmarshal([]byte(override.Query), &query)
+		if err != nil {
+			return nil, fmt.Errorf("%w while parsing the query override of index %s", err, index)
+		}
+	}
+
+	return overrides, nil
+}
+
+// targetIndex returns the name of the alias from the destination where the documents of the provided index are written
+func (ovr indexOverrides) targetIndex(index string) string {
+	override := ovr[index]
+	if override.TargetIndex != "" {
+		return override.TargetIndex
+	}
+
+	return override.TargetPrefix + index
+}
+
+// queryFilter returns the query clause applied on the source documents of the provided index, if any
+func (ovr indexOverrides) queryFilter(index string) json.RawMessage {
+	query := ovr[index].Query
+	if query == "" {
+		return nil
+	}
+
+	return json.RawMessage(query)
+}
+
+func (ovr indexOverrides) bulkSize(index string) int {
+	bulkSize := ovr[index].BulkSizeInBytes
+	if bulkSize <= 0 {
+		return bulkSizeThreshold
+	}
+
+	return bulkSize
+}
+
+// pageSize returns the page size override of the provided index, or 0 if there is none
+func (ovr indexOverrides) pageSize(index string) int {
+	pageSize := ovr[index].PageSize
+	if pageSize <= 0 {
+		return 0
+	}
+
+	return pageSize
+}
+
+func (ovr indexOverrides) numParallelWrites(index string, defaultValue int) int {
+	numParallelWrites := ovr[index].NumParallelWrites
+	if numParallelWrites <= 0 {
+		return defaultValue
+	}
+
+	return numParallelWrites
+}
+
+// timeRange returns the interval of the documents with timestamp that are copied for the provided index
+func (ovr indexOverrides) timeRange(index string, defaultStart int64, defaultEnd int64) (int64, int64) {
+	start, end := defaultStart, defaultEnd
+
+	override := ovr[index]
+	if override.StartTime > 0 {
+		start = override.StartTime
+	}
+	if override.EndTime > 0 {
+		end = override.EndTime
+	}
+
+	return start, end
+}
@@ -1,6 +1,11 @@
 package process
 
-import "bytes"
+import (
+	"bytes"
+	"time"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
+)
 
 // ElasticClientHandler defines the behaviour of an elastic search client handler
 type ElasticClientHandler interface {
@@ -11,6 +16,13 @@ type ElasticClientHandler interface {
 		body []byte,
 		handlerFunc func(responseBytes []byte) error,
 	) error
+	DoPitRequestAllDocuments(
+		index string,
+		body []byte,
+		pageSize int,
+		handlerFunc func(responseBytes []byte) error,
+	) error
+	DoSearchRequest(index string, body []byte) ([]byte, error)
 	GetCount(index string) (uint64, error)
 	GetCountWithBody(index string, body []byte) (uint64, error)
 	DoesAliasExist(alias string) bool
@@ -20,9 +32,49 @@ type ElasticClientHandler interface {
 	IsInterfaceNil() bool
 }
 
+// ServerSideReindexHandler defines the behaviour of a client of a cluster that can copy the documents itself
+type ServerSideReindexHandler interface {
+	StartReindexTask(body []byte, slices string) (string, error)
+	GetTask(taskID string) ([]byte, error)
+}
+
 // ReindexerHandler defines the behaviour of an reindexer handler
 type ReindexerHandler interface {
 	Process(overwrite bool, skipMappings bool, indices ...string) error
 	ProcessIndexWithTimestamp(index string, overwrite bool, skipMappings bool, start, stop int64, count *uint64) error
-	GetCountsForInterval(index string, start, stop int64) (uint64, uint64, error)
+	GetCountsForInterval(index string, start, stop int64) (uint64, map[string]uint64, error)
+	GetSourceCountForInterval(index string, start, stop int64) (uint64, error)
+	SyncIndex(index string) error
+	SyncIndexWithTimestamp(index string, start, stop int64) error
+	CheckMappings(skipMappings bool, force bool, indices ...string) error
+	ClearIndex(index string) error
+}
+
+// CheckpointHandler defines the behaviour of a component that persists the reindexing progress
+type CheckpointHandler interface {
+	GetIndexProgress(index string) checkpoint.Progress
+	UpdateIndexProgress(index string, bulk checkpoint.BulkInfo) error
+	RestartIndex(index string) error
+	MarkIndexCompleted(index string) error
+	GetIntervals(index string) []checkpoint.IntervalProgress
+	SetIntervals(index string, intervals []checkpoint.IntervalProgress) error
+	GetIntervalProgress(index string, start, stop int64) checkpoint.Progress
+	UpdateIntervalProgress(index string, start, stop int64, bulk checkpoint.BulkInfo) error
+	MarkIntervalCompleted(index string, start, stop int64) error
+	IsInterfaceNil() bool
+}
+
+// MetricsHandler defines the behaviour of a component that collects the progress and throughput of the reindexing
+type MetricsHandler interface {
+	AddExpectedDocuments(index string, interval string, numDocs uint64)
+	AddDocumentsRead(index string, interval string, numDocs uint64)
+	ObserveBulk(index string, interval string, numDocs int, numBytes int, duration time.Duration)
+	AddRetriedItems(index string, numItems int)
+	IsInterfaceNil() bool
+}
+
+// DocumentTransformer defines the behaviour of a component that transforms the documents before they are indexed
+type DocumentTransformer interface {
+	TransformDocument(id string, source []byte) (string, []byte, bool, error)
+	IsInterfaceNil() bool
 }
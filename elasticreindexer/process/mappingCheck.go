@@ -0,0 +1,328 @@
+package process
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/tidwall/gjson"
+)
+
+const (
+	// MappingTypeConflict defines a field having incompatible types in the source and in the destination
+	MappingTypeConflict = "type-conflict"
+	// MappingMissingField defines a source field that is not mapped in the destination
+	MappingMissingField = "missing-field"
+	// MappingDynamicDifference defines an object having different dynamic settings in the source and in the destination
+	MappingDynamicDifference = "dynamic-difference"
+
+	objectType      = "object"
+	defaultDynamic  = "true"
+	strictDynamic   = "strict"
+	mappingsPath    = "mappings"
+	templateMapping = "template.mappings"
+)
+
+// compatibleTypes holds the source types that can be written in a destination field with a wider type
+var compatibleTypes = map[string][]string{
+	"byte":       {"short", "integer", "long"},
+	"short":      {"integer", "long"},
+	"integer":    {"long"},
+	"half_float": {"float", "double"},
+	"float":      {"double"},
+}
+
+// matchingTemplateGetter defines the behaviour of a destination client that can resolve the index templates matching
+// an index name
+type matchingTemplateGetter interface {
+	GetMatchingIndexTemplate(index string) ([]byte, error)
+}
+
+// fieldPathTransformer defines the behaviour of a transformation chain that can tell the path a source field gets in
+// the destination documents, or false if the field is dropped
+type fieldPathTransformer interface {
+	TransformFieldPath(path string) (string, bool)
+}
+
+// MappingConflict holds a difference between the source mapping and the destination one
+type MappingConflict struct {
+	Path        string `json:"path"`
+	Kind        string `json:"kind"`
+	Source      string `json:"source,omitempty"`
+	Destination string `json:"destination,omitempty"`
+	// Blocking is set for the differences that make the destination reject the documents
+	Blocking bool `json:"blocking"`
+}
+
+// mappingFields holds the type of every field and the dynamic setting of every object of a mapping, by path. The root
+// object has the empty path
+type mappingFields struct {
+	types   map[string]string
+	dynamic map[string]string
+}
+
+// parseMappingFields walks the properties of the provided mapping, e.g. {"properties":{"a":{"type":"keyword"}}}
+func parseMappingFields(mapping gjson.Result) *mappingFields {
+	fields := &mappingFields{
+		types:   make(map[string]string),
+		dynamic: make(map[string]string),
+	}
+	fields.addObject("", mapping)
+
+	return fields
+}
+
+func (mf *mappingFields) addObject(path string, object gjson.Result) {
+	dynamic := object.Get("dynamic")
+	if dynamic.Exists() {
+		mf.dynamic[path] = dynamic.String()
+	}
+
+	for name, field := range object.Get("properties").Map() {
+		fieldPath := joinFieldPath(path, name)
+		fieldType := field.Get("type").String()
+		if fieldType == "" {
+			fieldType = objectType
+		}
+		mf.types[fieldPath] = fieldType
+
+		if field.Get("properties").Exists() {
+			mf.addObject(fieldPath, field)
+		}
+	}
+}
+
+// transformPaths returns the fields with the paths they get after the steps of the transformer that rename or drop
+// fields
+func (mf *mappingFields) transformPaths(transformer fieldPathTransformer) *mappingFields {
+	return &mappingFields{
+		types:   transformPaths(mf.types, transformer),
+		dynamic: transformPaths(mf.dynamic, transformer),
+	}
+}
+
+func transformPaths(values map[string]string, transformer fieldPathTransformer) map[string]string {
+	transformed := make(map[string]string, len(values))
+	for path, value := range values {
+		if path == "" {
+			transformed[path] = value
+			continue
+		}
+
+		newPath, keep := transformer.TransformFieldPath(path)
+		if keep {
+			transformed[newPath] = value
+		}
+	}
+
+	return transformed
+}
+
+// dynamicOf returns the dynamic setting that applies to the fields of the provided object, inherited from the parents
+func (mf *mappingFields) dynamicOf(objectPath string) string {
+	for {
+		dynamic, found := mf.dynamic[objectPath]
+		if found {
+			return dynamic
+		}
+		if objectPath == "" {
+			return defaultDynamic
+		}
+
+		lastDot := strings.LastIndex(objectPath, ".")
+		if lastDot < 0 {
+			objectPath = ""
+			continue
+		}
+		objectPath = objectPath[:lastDot]
+	}
+}
+
+func joinFieldPath(path string, name string) string {
+	if path == "" {
+		return name
+	}
+
+	return path + "." + name
+}
+
+func parentPath(path string) string {
+	lastDot := strings.LastIndex(path, ".")
+	if lastDot < 0 {
+		return ""
+	}
+
+	return path[:lastDot]
+}
+
+func isCompatibleType(sourceType string, destinationType string) bool {
+	if sourceType == destinationType {
+		return true
+	}
+	for _, widerType := range compatibleTypes[sourceType] {
+		if widerType == destinationType {
+			return true
+		}
+	}
+
+	return false
+}
+
+// compareMappings compares the fields of the source mapping with the destination ones. A type conflict blocks the
+// copy, as the documents would be rejected, like a field that is missing in a strict destination object
+func compareMappings(source *mappingFields, destination *mappingFields) []*MappingConflict {
+	conflicts := make([]*MappingConflict, 0)
+	for path, sourceType := range source.types {
+		destinationType, found := destination.types[path]
+		if !found {
+			destinationDynamic := destination.dynamicOf(parentPath(path))
+			conflicts = append(conflicts, &MappingConflict{
+				Path:     path,
+				Kind:     MappingMissingField,
+				Source:   sourceType,
+				Blocking: destinationDynamic == strictDynamic,
+			})
+			continue
+		}
+		if !isCompatibleType(sourceType, destinationType) {
+			conflicts = append(conflicts, &MappingConflict{
+				Path:        path,
+				Kind:        MappingTypeConflict,
+				Source:      sourceType,
+				Destination: destinationType,
+				Blocking:    true,
+			})
+		}
+	}
+
+	objectPaths := map[string]struct{}{"": {}}
+	for path, sourceType := range source.types {
+		if sourceType == objectType || sourceType == "nested" {
+			objectPaths[path] = struct{}{}
+		}
+	}
+	for path := range objectPaths {
+		_, found := destination.types[path]
+		if path != "" && !found {
+			continue
+		}
+
+		sourceDynamic := source.dynamicOf(path)
+		destinationDynamic := destination.dynamicOf(path)
+		if sourceDynamic != destinationDynamic {
+			conflicts = append(conflicts, &MappingConflict{
+				Path:        path,
+				Kind:        MappingDynamicDifference,
+				Source:      sourceDynamic,
+				Destination: destinationDynamic,
+			})
+		}
+	}
+
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Path != conflicts[j].Path {
+			return conflicts[i].Path < conflicts[j].Path
+		}
+		return conflicts[i].Kind < conflicts[j].Kind
+	})
+
+	return conflicts
+}
+
+// CheckMappings compares the mapping of every provided source index with the mapping that its documents will get in
+// the destination: the mapping of the existing target alias, or the index template of the target index when the
+// source mapping is not copied. The conflicts are logged and, if any of them is blocking, an error is returned unless
+// the force flag is set
+func (r *reindexer) CheckMappings(skipMappings bool, force bool, indices ...string) error {
+	numBlocking := 0
+	for _, index := range indices {
+		if index == "" {
+			continue
+		}
+
+		for _, dest := range r.destinations {
+			conflicts, err := r.checkIndexMapping(dest, index, skipMappings)
+			if err != nil {
+				return fmt.Errorf("%w while checking the mapping of index %s in destination %s", err, index, dest.name)
+			}
+
+			for _, conflict := range conflicts {
+				logMappingConflict(index, dest.name, conflict)
+				if conflict.Blocking {
+					numBlocking++
+				}
+			}
+		}
+	}
+
+	if numBlocking > 0 && !force {
+		return fmt.Errorf("found %d blocking mapping conflicts, fix the destination mappings or start the tool with the --force-mappings flag", numBlocking)
+	}
+
+	return nil
+}
+
+func logMappingConflict(index string, destinationName string, conflict *MappingConflict) {
+	if conflict.Blocking {
+		log.Error("mapping conflict", "index", index, "destination name", destinationName, "field", conflict.Path,
+			"kind", conflict.Kind, "source", conflict.Source, "destination", conflict.Destination)
+		return
+	}
+
+	log.Warn("mapping difference", "index", index, "destination name", destinationName, "field", conflict.Path,
+		"kind", conflict.Kind, "source", conflict.Source, "destination", conflict.Destination)
+}
+
+func (r *reindexer) checkIndexMapping(dest *destination, index string, skipMappings bool) ([]*MappingConflict, error) {
+	destinationMapping, err := getDestinationMapping(dest.elastic, r.overrides.targetIndex(index), skipMappings)
+	if err != nil {
+		return nil, err
+	}
+	if !destinationMapping.Exists() {
+		return nil, nil
+	}
+
+	sourceMappingBuff, err := r.sourceElastic.GetMapping(index)
+	if err != nil {
+		return nil, err
+	}
+	if sourceMappingBuff == nil {
+		return nil, nil
+	}
+	sourceFields := parseMappingFields(gjson.GetBytes(sourceMappingBuff.Bytes(), mappingsPath))
+
+	transformer, ok := r.transformers[index].(fieldPathTransformer)
+	if ok {
+		sourceFields = sourceFields.transformPaths(transformer)
+	}
+
+	return compareMappings(sourceFields, parseMappingFields(destinationMapping)), nil
+}
+
+// getDestinationMapping returns the mapping of the existing target alias or, if the source mapping is not copied,
+// the mappings of the index templates whose patterns match the target index. If the source mapping is copied, there is
+// nothing to compare
+func getDestinationMapping(destinationElastic ElasticClientHandler, targetIndex string, skipMappings bool) (gjson.Result, error) {
+	if destinationElastic.DoesAliasExist(targetIndex) {
+		mappingBuff, err := destinationElastic.GetMapping(targetIndex)
+		if err != nil || mappingBuff == nil {
+			return gjson.Result{}, err
+		}
+
+		return gjson.GetBytes(mappingBuff.Bytes(), mappingsPath), nil
+	}
+	if !skipMappings {
+		return gjson.Result{}, nil
+	}
+
+	templateGetter, ok := destinationElastic.(matchingTemplateGetter)
+	if !ok {
+		return gjson.Result{}, nil
+	}
+	template, err := templateGetter.GetMatchingIndexTemplate(targetIndex)
+	if err != nil {
+		return gjson.Result{}, err
+	}
+
+	return gjson.GetBytes(template, templateMapping), nil
+}
@@ -0,0 +1,167 @@
+package process
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/process/mock"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/transform"
+	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
+)
+
+const sourceMapping = `{"mappings":{"properties":{
+	"nonce":{"type":"integer"},
+	"hash":{"type":"keyword"},
+	"fee":{"type":"keyword"},
+	"data":{"properties":{"name":{"type":"keyword"},"value":{"type":"long"}}}
+}}}`
+
+type templateClientStub struct {
+	*mock.ElasticClientStub
+	template      []byte
+	resolvedIndex string
+}
+
+func (tcs *templateClientStub) GetMatchingIndexTemplate(index string) ([]byte, error) {
+	tcs.resolvedIndex = index
+	return tcs.template, nil
+}
+
+func parseTestMapping(mapping string) *mappingFields {
+	return parseMappingFields(gjson.Get(mapping, mappingsPath))
+}
+
+func TestCompareMappings(t *testing.T) {
+	t.Parallel()
+
+	destinationMapping := `{"mappings":{"dynamic":"false","properties":{
+		"nonce":{"type":"long"},
+		"fee":{"type":"double"},
+		"data":{"dynamic":"strict","properties":{"name":{"type":"keyword"}}}
+	}}}`
+
+	conflicts := compareMappings(parseTestMapping(sourceMapping), parseTestMapping(destinationMapping))
+	require.Equal(t, []*MappingConflict{
+		{Path: "", Kind: MappingDynamicDifference, Source: "true", Destination: "false"},
+		{Path: "data", Kind: MappingDynamicDifference, Source: "true", Destination: "strict"},
+		{Path: "data.value", Kind: MappingMissingField, Source: "long", Blocking: true},
+		{Path: "fee", Kind: MappingTypeConflict, Source: "keyword", Destination: "double", Blocking: true},
+		{Path: "hash", Kind: MappingMissingField, Source: "keyword"},
+	}, conflicts)
+}
+
+func TestCompareMappings_SameMappingShouldNotReportConflicts(t *testing.T) {
+	t.Parallel()
+
+	conflicts := compareMappings(parseTestMapping(sourceMapping), parseTestMapping(sourceMapping))
+	require.Empty(t, conflicts)
+}
+
+func TestCheckMappings(t *testing.T) {
+	t.Parallel()
+
+	destinationMapping := `{"mappings":{"properties":{"nonce":{"type":"keyword"}}}}`
+
+	createArgs := func(aliasExists bool) ArgsReindexer {
+		args := createMockArgsReindexer()
+		args.SourceElastic = &mock.ElasticClientStub{
+			GetMappingCalled: func(_ string) (*bytes.Buffer, error) {
+				return bytes.NewBufferString(sourceMapping), nil
+			},
+		}
+		args.DestinationElastic = &mock.ElasticClientStub{
+			DoesAliasExistCalled: func(_ string) bool {
+				return aliasExists
+			},
+			GetMappingCalled: func(_ string) (*bytes.Buffer, error) {
+				return bytes.NewBufferString(destinationMapping), nil
+			},
+		}
+
+		return args
+	}
+
+	t.Run("blocking conflict with the destination alias should err", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newReindexer(createArgs(true))
+		require.NoError(t, err)
+
+		err = r.CheckMappings(false, false, testIndex)
+		require.Error(t, err)
+	})
+	t.Run("blocking conflict with force should not err", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newReindexer(createArgs(true))
+		require.NoError(t, err)
+
+		err = r.CheckMappings(false, true, testIndex)
+		require.NoError(t, err)
+	})
+	t.Run("missing alias and copied mapping should not check", func(t *testing.T) {
+		t.Parallel()
+
+		r, err := newReindexer(createArgs(false))
+		require.NoError(t, err)
+
+		err = r.CheckMappings(false, false, testIndex)
+		require.NoError(t, err)
+	})
+	t.Run("missing alias and skipped mapping should check the template", func(t *testing.T) {
+		t.Parallel()
+
+		args := createArgs(false)
+		args.Overrides = map[string]config.IndexOverrideConfig{testIndex: {TargetIndex: "target-index"}}
+		templateClient := &templateClientStub{
+			ElasticClientStub: args.DestinationElastic.(*mock.ElasticClientStub),
+			template:          []byte(`{"template":` + destinationMapping + `}`),
+		}
+		args.DestinationElastic = templateClient
+		r, err := newReindexer(args)
+		require.NoError(t, err)
+
+		err = r.CheckMappings(true, false, testIndex)
+		require.Error(t, err)
+		require.Equal(t, "target-index", templateClient.resolvedIndex)
+	})
+	t.Run("renamed or dropped source field should not conflict", func(t *testing.T) {
+		t.Parallel()
+
+		for _, step := range []config.TransformConfig{
+			{Type: transform.RenameFieldType, Field: "nonce", NewField: "block_nonce"},
+			{Type: transform.DropFieldType, Field: "nonce"},
+		} {
+			args := createArgs(true)
+			transformers, err := createTransformers(map[string][]config.TransformConfig{testIndex: {step}}, nil)
+			require.NoError(t, err)
+			args.Transformers = transformers
+			r, err := newReindexer(args)
+			require.NoError(t, err)
+
+			err = r.CheckMappings(false, false, testIndex)
+			require.NoError(t, err, step.Type)
+		}
+	})
+}
+
+func TestMappingFields_TransformPaths(t *testing.T) {
+	t.Parallel()
+
+	chain, err := transform.CreateChains(map[string][]config.TransformConfig{testIndex: {
+		{Type: transform.RenameFieldType, Field: "data", NewField: "payload"},
+		{Type: transform.DropFieldType, Field: "hash"},
+	}}, nil)
+	require.NoError(t, err)
+
+	fields := parseTestMapping(sourceMapping).transformPaths(chain[testIndex])
+	require.Equal(t, map[string]string{
+		"nonce":         "integer",
+		"fee":           "keyword",
+		"payload":       objectType,
+		"payload.name":  "keyword",
+		"payload.value": "long",
+	}, fields.types)
+}
@@ -0,0 +1,102 @@
+package mock
+
+import "github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
+
+// CheckpointHandlerStub -
+type CheckpointHandlerStub struct {
+	GetIndexProgressCalled       func(index string) checkpoint.Progress
+	UpdateIndexProgressCalled    func(index string, bulk checkpoint.BulkInfo) error
+	RestartIndexCalled           func(index string) error
+	MarkIndexCompletedCalled     func(index string) error
+	GetIntervalsCalled           func(index string) []checkpoint.IntervalProgress
+	SetIntervalsCalled           func(index string, intervals []checkpoint.IntervalProgress) error
+	GetIntervalProgressCalled    func(index string, start, stop int64) checkpoint.Progress
+	UpdateIntervalProgressCalled func(index string, start, stop int64, bulk checkpoint.BulkInfo) error
+	MarkIntervalCompletedCalled  func(index string, start, stop int64) error
+}
+
+// GetIndexProgress -
+func (c *CheckpointHandlerStub) GetIndexProgress(index string) checkpoint.Progress {
+	if c.GetIndexProgressCalled != nil {
+		return c.GetIndexProgressCalled(index)
+	}
+
+	return checkpoint.Progress{}
+}
+
+// UpdateIndexProgress -
+func (c *CheckpointHandlerStub) UpdateIndexProgress(index string, bulk checkpoint.BulkInfo) error {
+	if c.UpdateIndexProgressCalled != nil {
+		return c.UpdateIndexProgressCalled(index, bulk)
+	}
+
+	return nil
+}
+
+// RestartIndex -
+func (c *CheckpointHandlerStub) RestartIndex(index string) error {
+	if c.RestartIndexCalled != nil {
+		return c.RestartIndexCalled(index)
+	}
+
+	return nil
+}
+
+// MarkIndexCompleted -
+func (c *CheckpointHandlerStub) MarkIndexCompleted(index string) error {
+	if c.MarkIndexCompletedCalled != nil {
+		return c.MarkIndexCompletedCalled(index)
+	}
+
+	return nil
+}
+
+// GetIntervals -
+func (c *CheckpointHandlerStub) GetIntervals(index string) []checkpoint.IntervalProgress {
+	if c.GetIntervalsCalled != nil {
+		return c.GetIntervalsCalled(index)
+	}
+
+	return nil
+}
+
+// SetIntervals -
+func (c *CheckpointHandlerStub) SetIntervals(index string, intervals []checkpoint.IntervalProgress) error {
+	if c.SetIntervalsCalled != nil {
+		return c.SetIntervalsCalled(index, intervals)
+	}
+
+	return nil
+}
+
+// GetIntervalProgress -
+func (c *CheckpointHandlerStub) GetIntervalProgress(index string, start, stop int64) checkpoint.Progress {
+	if c.GetIntervalProgressCalled != nil {
+		return c.GetIntervalProgressCalled(index, start, stop)
+	}
+
+	return checkpoint.Progress{}
+}
+
+// UpdateIntervalProgress -
+func (c *CheckpointHandlerStub) UpdateIntervalProgress(index string, start, stop int64, bulk checkpoint.BulkInfo) error {
+	if c.UpdateIntervalProgressCalled != nil {
+		return c.UpdateIntervalProgressCalled(index, start, stop, bulk)
+	}
+
+	return nil
+}
+
+// MarkIntervalCompleted -
+func (c *CheckpointHandlerStub) MarkIntervalCompleted(index string, start, stop int64) error {
+	if c.MarkIntervalCompletedCalled != nil {
+		return c.MarkIntervalCompletedCalled(index, start, stop)
+	}
+
+	return nil
+}
+
+// IsInterfaceNil -
+func (c *CheckpointHandlerStub) IsInterfaceNil() bool {
+	return c == nil
+}
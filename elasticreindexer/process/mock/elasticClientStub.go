@@ -9,11 +9,16 @@ type ElasticClientStub struct {
 	GetMappingCalled                  func(index string) (*bytes.Buffer, error)
 	CreateIndexWithMappingCalled      func(targetIndex string, body *bytes.Buffer) error
 	DoScrollRequestAllDocumentsCalled func(index string, body []byte, handlerFunc func(responseBytes []byte) error) error
+	DoPitRequestAllDocumentsCalled    func(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error
+	DoSearchRequestCalled             func(index string, body []byte) ([]byte, error)
 	GetCountCalled                    func(index string) (uint64, error)
+	GetCountWithBodyCalled            func(index string, body []byte) (uint64, error)
 	DoesAliasExistCalled              func(alias string) bool
 	DoBulkRequestCalled               func(buff *bytes.Buffer, index string) error
 	DoesIndexExistCalled              func(index string) bool
 	PutAliasCalled                    func(index string, alias string) error
+	StartReindexTaskCalled            func(body []byte, slices string) (string, error)
+	GetTaskCalled                     func(taskID string) ([]byte, error)
 }
 
 // GetMapping -
@@ -26,7 +31,11 @@ func (e *ElasticClientStub) GetMapping(index string) (*bytes.Buffer, error) {
 }
 
 // GetCountWithBody -
-func (e *ElasticClientStub) GetCountWithBody(_ string, _ []byte) (uint64, error) {
+func (e *ElasticClientStub) GetCountWithBody(index string, body []byte) (uint64, error) {
+	if e.GetCountWithBodyCalled != nil {
+		return e.GetCountWithBodyCalled(index, body)
+	}
+
 	return 0, nil
 }
 
@@ -48,6 +57,24 @@ func (e *ElasticClientStub) DoScrollRequestAllDocuments(index string, body []byt
 	return nil
 }
 
+// DoPitRequestAllDocuments -
+func (e *ElasticClientStub) DoPitRequestAllDocuments(index string, body []byte, pageSize int, handlerFunc func(responseBytes []byte) error) error {
+	if e.DoPitRequestAllDocumentsCalled != nil {
+		return e.DoPitRequestAllDocumentsCalled(index, body, pageSize, handlerFunc)
+	}
+
+	return nil
+}
+
+// DoSearchRequest -
+func (e *ElasticClientStub) DoSearchRequest(index string, body []byte) ([]byte, error) {
+	if e.DoSearchRequestCalled != nil {
+		return e.DoSearchRequestCalled(index, body)
+	}
+
+	return nil, nil
+}
+
 // GetCount -
 func (e *ElasticClientStub) GetCount(index string) (uint64, error) {
 	if e.GetCountCalled != nil {
@@ -93,6 +120,24 @@ func (e *ElasticClientStub) PutAlias(index string, alias string) error {
 	return nil
 }
 
+// StartReindexTask -
+func (e *ElasticClientStub) StartReindexTask(body []byte, slices string) (string, error) {
+	if e.StartReindexTaskCalled != nil {
+		return e.StartReindexTaskCalled(body, slices)
+	}
+
+	return "", nil
+}
+
+// GetTask -
+func (e *ElasticClientStub) GetTask(taskID string) ([]byte, error) {
+	if e.GetTaskCalled != nil {
+		return e.GetTaskCalled(taskID)
+	}
+
+	return nil, nil
+}
+
 // IsInterfaceNil -
 func (e *ElasticClientStub) IsInterfaceNil() bool {
 	return e == nil
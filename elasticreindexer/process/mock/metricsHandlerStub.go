@@ -0,0 +1,44 @@
+package mock
+
+import "time"
+
+// MetricsHandlerStub -
+type MetricsHandlerStub struct {
+	AddExpectedDocumentsCalled func(index string, interval string, numDocs uint64)
+	AddDocumentsReadCalled     func(index string, interval string, numDocs uint64)
+	ObserveBulkCalled          func(index string, interval string, numDocs int, numBytes int, duration time.Duration)
+	AddRetriedItemsCalled      func(index string, numItems int)
+}
+
+// AddExpectedDocuments -
+func (m *MetricsHandlerStub) AddExpectedDocuments(index string, interval string, numDocs uint64) {
+	if m.AddExpectedDocumentsCalled != nil {
+		m.AddExpectedDocumentsCalled(index, interval, numDocs)
+	}
+}
+
+// AddDocumentsRead -
+func (m *MetricsHandlerStub) AddDocumentsRead(index string, interval string, numDocs uint64) {
+	if m.AddDocumentsReadCalled != nil {
+		m.AddDocumentsReadCalled(index, interval, numDocs)
+	}
+}
+
+// ObserveBulk -
+func (m *MetricsHandlerStub) ObserveBulk(index string, interval string, numDocs int, numBytes int, duration time.Duration) {
+	if m.ObserveBulkCalled != nil {
+		m.ObserveBulkCalled(index, interval, numDocs, numBytes, duration)
+	}
+}
+
+// AddRetriedItems -
+func (m *MetricsHandlerStub) AddRetriedItems(index string, numItems int) {
+	if m.AddRetriedItemsCalled != nil {
+		m.AddRetriedItemsCalled(index, numItems)
+	}
+}
+
+// IsInterfaceNil -
+func (m *MetricsHandlerStub) IsInterfaceNil() bool {
+	return m == nil
+}
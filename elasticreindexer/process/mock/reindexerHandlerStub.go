@@ -0,0 +1,85 @@
+package mock
+
+// ReindexerHandlerStub -
+type ReindexerHandlerStub struct {
+	ProcessCalled                   func(overwrite bool, skipMappings bool, indices ...string) error
+	ProcessIndexWithTimestampCalled func(index string, overwrite bool, skipMappings bool, start, stop int64, count *uint64) error
+	GetCountsForIntervalCalled      func(index string, start, stop int64) (uint64, map[string]uint64, error)
+	GetSourceCountForIntervalCalled func(index string, start, stop int64) (uint64, error)
+	SyncIndexCalled                 func(index string) error
+	SyncIndexWithTimestampCalled    func(index string, start, stop int64) error
+	CheckMappingsCalled             func(skipMappings bool, force bool, indices ...string) error
+	ClearIndexCalled                func(index string) error
+}
+
+// Process -
+func (r *ReindexerHandlerStub) Process(overwrite bool, skipMappings bool, indices ...string) error {
+	if r.ProcessCalled != nil {
+		return r.ProcessCalled(overwrite, skipMappings, indices...)
+	}
+
+	return nil
+}
+
+// ProcessIndexWithTimestamp -
+func (r *ReindexerHandlerStub) ProcessIndexWithTimestamp(index string, overwrite bool, skipMappings bool, start, stop int64, count *uint64) error {
+	if r.ProcessIndexWithTimestampCalled != nil {
+		return r.ProcessIndexWithTimestampCalled(index, overwrite, skipMappings, start, stop, count)
+	}
+
+	return nil
+}
+
+// GetCountsForInterval -
+func (r *ReindexerHandlerStub) GetCountsForInterval(index string, start, stop int64) (uint64, map[string]uint64, error) {
+	if r.GetCountsForIntervalCalled != nil {
+		return r.GetCountsForIntervalCalled(index, start, stop)
+	}
+
+	return 0, nil, nil
+}
+
+// GetSourceCountForInterval -
+func (r *ReindexerHandlerStub) GetSourceCountForInterval(index string, start, stop int64) (uint64, error) {
+	if r.GetSourceCountForIntervalCalled != nil {
+		return r.GetSourceCountForIntervalCalled(index, start, stop)
+	}
+
+	return 0, nil
+}
+
+// SyncIndex -
+func (r *ReindexerHandlerStub) SyncIndex(index string) error {
+	if r.SyncIndexCalled != nil {
+		return r.SyncIndexCalled(index)
+	}
+
+	return nil
+}
+
+// SyncIndexWithTimestamp -
+func (r *ReindexerHandlerStub) SyncIndexWithTimestamp(index string, start, stop int64) error {
+	if r.SyncIndexWithTimestampCalled != nil {
+		return r.SyncIndexWithTimestampCalled(index, start, stop)
+	}
+
+	return nil
+}
+
+// CheckMappings -
+func (r *ReindexerHandlerStub) CheckMappings(skipMappings bool, force bool, indices ...string) error {
+	if r.CheckMappingsCalled != nil {
+		return r.CheckMappingsCalled(skipMappings, force, indices...)
+	}
+
+	return nil
+}
+
+// ClearIndex -
+func (r *ReindexerHandlerStub) ClearIndex(index string) error {
+	if r.ClearIndexCalled != nil {
+		return r.ClearIndexCalled(index)
+	}
+
+	return nil
+}
@@ -17,6 +17,16 @@ func encodeQuery(query object) (bytes.Buffer, error) {
 	return buff, nil
 }
 
+// decodeQuery keeps the numbers of the provided query as they are, so they can be encoded again without losing precision
+func decodeQuery(query *bytes.Buffer) object {
+	obj := object{}
+	decoder := json.NewDecoder(bytes.NewReader(query.Bytes()))
+	decoder.UseNumber()
+	_ = decoder.Decode(&obj)
+
+	return obj
+}
+
 func getAll() *bytes.Buffer {
 	obj := object{
 		"query": object{
@@ -29,13 +39,15 @@ func getAll() *bytes.Buffer {
 	return &encoded
 }
 
+// getWithTimestamp returns the query for the documents of the half-open interval [start, stop), so the neighbouring
+// intervals, sharing a boundary, never hold the same document
 func getWithTimestamp(start, stop int64, withSource bool, withSortAsc bool) *bytes.Buffer {
 	obj := object{
 		"query": object{
 			"range": object{
 				"timestamp": object{
 					"gte": fmt.Sprintf("%d", start),
-					"lte": fmt.Sprintf("%d", stop),
+					"lt":  fmt.Sprintf("%d", stop),
 				},
 			},
 		},
@@ -59,11 +71,158 @@ func getWithTimestamp(start, stop int64, withSource bool, withSortAsc bool) *byt
 	return &encoded
 }
 
+// withSlice restricts the provided query to one slice of the documents, so the slices can be read in parallel
+func withSlice(query *bytes.Buffer, sliceID int, numSlices int) *bytes.Buffer {
+	obj := decodeQuery(query)
+
+	obj["slice"] = object{
+		"id":  sliceID,
+		"max": numSlices,
+	}
+
+	encoded, _ := encodeQuery(obj)
+
+	return &encoded
+}
+
+// withPitSort adds to the provided query the deterministic sort needed by the point in time reader. The _shard_doc
+// tiebreaker is only valid in the same point in time, so only the timestamp can be used to resume the reading later
+func withPitSort(query *bytes.Buffer, withTimestamp bool) *bytes.Buffer {
+	obj := decodeQuery(query)
+
+	sort := make([]interface{}, 0, 2)
+	if withTimestamp {
+		sort = append(sort, object{
+			"timestamp": object{
+				"order": "asc",
+			},
+		})
+	}
+	sort = append(sort, object{
+		"_shard_doc": object{
+			"order": "asc",
+		},
+	})
+	obj["sort"] = sort
+
+	encoded, _ := encodeQuery(obj)
+
+	return &encoded
+}
+
+// withQueryFilter restricts the provided query to the documents that also match the provided filter clause
+func withQueryFilter(query *bytes.Buffer, filter json.RawMessage) *bytes.Buffer {
+	if len(filter) == 0 {
+		return query
+	}
+
+	obj := decodeQuery(query)
+	originalQuery, found := obj["query"]
+	obj["query"] = filter
+	if found {
+		obj["query"] = object{
+			"bool": object{
+				"filter": []interface{}{originalQuery, filter},
+			},
+		}
+	}
+
+	encoded, _ := encodeQuery(obj)
+
+	return &encoded
+}
+
+// withPageSize sets the number of documents returned in one page by the provided query
+func withPageSize(query *bytes.Buffer, pageSize int) *bytes.Buffer {
+	if pageSize <= 0 {
+		return query
+	}
+
+	obj := decodeQuery(query)
+	obj["size"] = pageSize
+
+	encoded, _ := encodeQuery(obj)
+
+	return &encoded
+}
+
+func getRandomSample(size int, filter json.RawMessage) *bytes.Buffer {
+	var query interface{} = object{
+		"match_all": object{},
+	}
+	if len(filter) > 0 {
+		query = filter
+	}
+
+	obj := object{
+		"size": size,
+		"query": object{
+			"function_score": object{
+				"query":        query,
+				"random_score": object{},
+			},
+		},
+	}
+
+	encoded, _ := encodeQuery(obj)
+
+	return &encoded
+}
+
+func getByIDs(ids []string) *bytes.Buffer {
+	obj := object{
+		"size": len(ids),
+		"query": object{
+			"ids": object{
+				"values": ids,
+			},
+		},
+	}
+
+	encoded, _ := encodeQuery(obj)
+
+	return &encoded
+}
+
+// getCompositeAggregation returns a page of the buckets of the provided field. The next page starts after the provided
+// key, the first page being requested with an empty one
+func getCompositeAggregation(field string, size int, afterKey json.RawMessage) *bytes.Buffer {
+	composite := object{
+		"size": size,
+		"sources": []object{
+			{
+				"key": object{
+					"terms": object{
+						"field": field,
+					},
+				},
+			},
+		},
+	}
+	if len(afterKey) > 0 {
+		composite["after"] = afterKey
+	}
+
+	obj := object{
+		"size": 0,
+		"aggs": object{
+			"buckets": object{
+				"composite": composite,
+			},
+		},
+	}
+
+	encoded, _ := encodeQuery(obj)
+
+	return &encoded
+}
+
 type generalElasticResponse struct {
 	Hits struct {
 		Hits []struct {
 			ID     string          `json:"_id"`
 			Source json.RawMessage `json:"_source"`
+			Sort   json.RawMessage `json:"sort"`
 		} `json:"hits"`
 	} `json:"hits"`
 }
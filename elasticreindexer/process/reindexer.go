@@ -5,44 +5,190 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/multiversx/mx-chain-core-go/core/check"
 	logger "github.com/multiversx/mx-chain-logger-go"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/tidwall/gjson"
 )
 
 var (
-	errNilElasticHandler = errors.New("nil elastic handler")
-	log                  = logger.GetOrCreate("process")
+	errNilElasticHandler    = errors.New("nil elastic handler")
+	errNilCheckpointHandler = errors.New("nil checkpoint handler")
+	errNilMetricsHandler    = errors.New("nil metrics handler")
+	log                     = logger.GetOrCreate("process")
 )
 
 const (
-	defaultStr  = "default"
-	indexSuffix = "-000001"
+	defaultStr = "default"
+	// firstGenerationSuffix is the suffix of the first index behind an alias, the next ones being created by rollovers
+	firstGenerationSuffix = "-000001"
+	defaultPageSize       = 9000
+
+	// ScrollReadMode defines the reading mode that uses the scroll API
+	ScrollReadMode = "scroll"
+	// PitReadMode defines the reading mode that uses a point in time and search_after
+	PitReadMode = "pit"
+	// ReindexReadMode defines the mode where the output cluster copies the documents itself with _reindex tasks
+	ReindexReadMode = "reindex"
+
+	// wholeIndexInterval is the interval label of the metrics of the indices copied without timestamp intervals
+	wholeIndexInterval = ""
 )
 
+// indexCleaner defines the behaviour of a destination client that appends the documents instead of indexing them by _id
+type indexCleaner interface {
+	ClearIndex(index string) error
+}
+
 type reindexer struct {
-	sourceElastic      ElasticClientHandler
-	destinationElastic ElasticClientHandler
-	indices            []string
+	sourceElastic ElasticClientHandler
+	destinations  []*destination
+	indices       []string
+	numSlices     map[string]int
+	readMode      string
+	pageSize      int
+	transformers  map[string]DocumentTransformer
+	overrides     indexOverrides
+	metrics       MetricsHandler
+	serverSide    *serverSideReindexer
+}
+
+// ArgsReindexer holds the arguments needed for creating a new reindexer
+type ArgsReindexer struct {
+	SourceElastic      ElasticClientHandler
+	DestinationElastic ElasticClientHandler
+	Indices            []string
+	NumSlices          map[string]int
+	Checkpoint         CheckpointHandler
+	ReaderConfig       config.ReaderConfig
+	Transformers       map[string]DocumentTransformer
+	Overrides          map[string]config.IndexOverrideConfig
+	Metrics            MetricsHandler
+	// DestinationName is the name of DestinationElastic used in the logs, "output" by default
+	DestinationName string
+	// AdditionalDestinations are written with the same pages as DestinationElastic, each one having its own checkpoint
+	AdditionalDestinations []Destination
+	// SourceConfig holds the connection details of the input cluster, used by the remote server-side reindex
+	SourceConfig config.ElasticInstanceConfig
 }
 
 // newReindexer returns a new instance of reindexer if the provided params aren't nil, or error otherwise
-func newReindexer(sourceElastic ElasticClientHandler, destinationElastic ElasticClientHandler, indices []string) (*reindexer, error) {
-	if check.IfNil(sourceElastic) {
+func newReindexer(args ArgsReindexer) (*reindexer, error) {
+	if check.IfNil(args.SourceElastic) {
 		return nil, fmt.Errorf("%w for source", errNilElasticHandler)
 	}
-	if check.IfNil(destinationElastic) {
+	if check.IfNil(args.DestinationElastic) {
 		return nil, fmt.Errorf("%w for destination", errNilElasticHandler)
 	}
+	if check.IfNil(args.Checkpoint) {
+		return nil, errNilCheckpointHandler
+	}
+	if check.IfNil(args.Metrics) {
+		return nil, errNilMetricsHandler
+	}
+	destinations, err := createDestinations(args)
+	if err != nil {
+		return nil, err
+	}
+
+	readMode := args.ReaderConfig.Mode
+	if readMode == "" {
+		readMode = ScrollReadMode
+	}
+	if readMode != ScrollReadMode && readMode != PitReadMode && readMode != ReindexReadMode {
+		return nil, fmt.Errorf("invalid read mode %s, it should be %s, %s or %s", readMode, ScrollReadMode, PitReadMode, ReindexReadMode)
+	}
+
+	var serverSide *serverSideReindexer
+	if readMode == ReindexReadMode {
+		serverSide, err = newServerSideReindexer(args)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	pageSize := args.ReaderConfig.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+
+	overrides, err := newIndexOverrides(args.Overrides)
+	if err != nil {
+		return nil, err
+	}
 
 	return &reindexer{
-		sourceElastic:      sourceElastic,
-		destinationElastic: destinationElastic,
-		indices:            indices,
+		sourceElastic: args.SourceElastic,
+		destinations:  destinations,
+		indices:       args.Indices,
+		numSlices:     args.NumSlices,
+		readMode:      readMode,
+		pageSize:      pageSize,
+		transformers:  args.Transformers,
+		overrides:     overrides,
+		metrics:       args.Metrics,
+		serverSide:    serverSide,
 	}, nil
 }
 
+func createDestinations(args ArgsReindexer) ([]*destination, error) {
+	name := args.DestinationName
+	if name == "" {
+		name = defaultDestinationName
+	}
+
+	destinations := []*destination{{
+		name:       name,
+		elastic:    args.DestinationElastic,
+		checkpoint: args.Checkpoint,
+	}}
+	names := map[string]struct{}{name: {}}
+	for _, additional := range args.AdditionalDestinations {
+		if check.IfNil(additional.Elastic) {
+			return nil, fmt.Errorf("%w for destination %s", errNilElasticHandler, additional.Name)
+		}
+		if check.IfNil(additional.Checkpoint) {
+			return nil, fmt.Errorf("%w for destination %s", errNilCheckpointHandler, additional.Name)
+		}
+		_, duplicated := names[additional.Name]
+		if duplicated || additional.Name == "" {
+			return nil, fmt.Errorf("invalid destination name %q, every destination should have an unique name", additional.Name)
+		}
+		names[additional.Name] = struct{}{}
+
+		destinations = append(destinations, &destination{
+			name:       additional.Name,
+			elastic:    additional.Elastic,
+			checkpoint: additional.Checkpoint,
+		})
+	}
+
+	return destinations, nil
+}
+
+// incompleteDestinations returns the destinations that did not complete the copy tracked by the provided progress
+// getter, together with their progress
+func (r *reindexer) incompleteDestinations(getProgress func(checkpointHandler CheckpointHandler) checkpoint.Progress) ([]*destination, []checkpoint.Progress) {
+	destinations := make([]*destination, 0, len(r.destinations))
+	progresses := make([]checkpoint.Progress, 0, len(r.destinations))
+	for _, dest := range r.destinations {
+		progress := getProgress(dest.checkpoint)
+		if progress.Completed {
+			continue
+		}
+
+		destinations = append(destinations, dest)
+		progresses = append(progresses, progress)
+	}
+
+	return destinations, progresses
+}
+
 // Process will handle the reindexing from source Elastic client to destination Elastic client
 func (r *reindexer) Process(overwrite bool, skipMappings bool, indices ...string) error {
 	providedIndices := indices
@@ -65,53 +211,150 @@ func (r *reindexer) Process(overwrite bool, skipMappings bool, indices ...string
 }
 
 func (r *reindexer) processIndex(index string, overwrite bool, skipMappings bool) error {
-	originalSourceCount, err := r.sourceElastic.GetCount(index)
+	destinations, progresses := r.incompleteDestinations(func(checkpointHandler CheckpointHandler) checkpoint.Progress {
+		return checkpointHandler.GetIndexProgress(index)
+	})
+	if len(destinations) == 0 {
+		log.Info("index was already copied, skipping", "index", index)
+		return nil
+	}
+
+	originalSourceCount, err := r.getSourceCount(index)
 	if err != nil {
 		return fmt.Errorf("%w while getting the source count for index %s", err, index)
 	}
+	r.metrics.AddExpectedDocuments(index, wholeIndexInterval, originalSourceCount)
 
-	err = r.copyMappingIfNecessary(index, overwrite, skipMappings)
-	if err != nil {
-		return fmt.Errorf("%w while copying the mapping for index %s", err, index)
+	for i, dest := range destinations {
+		overwriteDestination := overwrite
+		if progresses[i].DocsWritten > 0 {
+			// the scroll over all the documents cannot be continued, but the destination index was already created
+			log.Info("index was partially copied, restarting it", "index", index, "destination", dest.name, "docs written", progresses[i].DocsWritten)
+			overwriteDestination = true
+
+			err = dest.checkpoint.RestartIndex(index)
+			if err != nil {
+				return err
+			}
+		}
+
+		if overwriteDestination {
+			err = r.clearDestinationIndex(dest, index)
+			if err != nil {
+				return err
+			}
+		}
+
+		err = r.copyMappingIfNecessary(dest, index, overwriteDestination, skipMappings)
+		if err != nil {
+			return fmt.Errorf("%w while copying the mapping for index %s in destination %s", err, index, dest.name)
+		}
 	}
 
 	log.Info("starting reindexing", "index", index)
 
-	err = r.reindexData(index)
+	targets := newFanOut(destinations)
+	err = r.reindexData(index, targets)
 	if err != nil {
 		return fmt.Errorf("%w while reindexing data for index %s", err, index)
 	}
 
-	destinationCount, err := r.destinationElastic.GetCount(index)
+	targetIndex := r.overrides.targetIndex(index)
+	for _, dest := range targets.active() {
+		err = dest.checkpoint.MarkIndexCompleted(index)
+		if err != nil {
+			return fmt.Errorf("%w while saving the checkpoint for index %s in destination %s", err, index, dest.name)
+		}
+
+		destinationCount, errCount := dest.elastic.GetCount(targetIndex)
+		if errCount != nil {
+			return fmt.Errorf("%w while getting the destination count for index %s in destination %s", errCount, targetIndex, dest.name)
+		}
+
+		log.Info("finished indexing for index",
+			"index", index,
+			"target index", targetIndex,
+			"destination", dest.name,
+			"original source count", originalSourceCount,
+			"destination count (estimation)", destinationCount)
+	}
+	logFailedDestinations(index, targets)
+
+	return nil
+}
+
+// logFailedDestinations reports the destinations that failed while the copy continued for the other ones. Their
+// checkpoint is not completed, so a resumed run will copy the index or the interval again only for them
+func logFailedDestinations(index string, targets *fanOut) {
+	err := targets.err()
 	if err != nil {
-		return fmt.Errorf("%w while getting the destination count for index %s", err, index)
+		log.Error("the copy failed for some destinations, start the tool with --resume to copy again only for them", "index", index, "error", err)
+	}
+}
+
+func (r *reindexer) getSourceCount(index string) (uint64, error) {
+	filter := r.overrides.queryFilter(index)
+	if len(filter) == 0 {
+		return r.sourceElastic.GetCount(index)
+	}
+
+	return r.sourceElastic.GetCountWithBody(index, withQueryFilter(getAll(), filter).Bytes())
+}
+
+// ClearIndex will remove the documents of the provided index from the destinations that append them, like the file
+// client, before the index is copied again from its beginning. The other destinations index the documents by _id, so
+// they are left as they are
+func (r *reindexer) ClearIndex(index string) error {
+	for _, dest := range r.destinations {
+		err := r.clearDestinationIndex(dest, index)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (r *reindexer) clearDestinationIndex(dest *destination, index string) error {
+	cleaner, ok := dest.elastic.(indexCleaner)
+	if !ok {
+		return nil
 	}
 
-	log.Info("finished indexing for index",
-		"index", index,
-		"original source count", originalSourceCount,
-		"destination count (estimation)", destinationCount)
+	targetIndex := r.overrides.targetIndex(index)
+	log.Info("clearing the destination index", "index", targetIndex, "destination", dest.name)
+	err := cleaner.ClearIndex(targetIndex)
+	if err != nil {
+		return fmt.Errorf("%w while clearing index %s in destination %s", err, targetIndex, dest.name)
+	}
 
 	return nil
 }
 
-func (r *reindexer) copyMappingIfNecessary(index string, overwrite bool, skipMappings bool) error {
+// copyMappingIfNecessary creates the target index of the provided source index in the destination, with the mapping
+// of the source index. An existing alias is used as it is, as it can have more backing indices after rollovers, the
+// documents being written in its write index
+func (r *reindexer) copyMappingIfNecessary(dest *destination, index string, overwrite bool, skipMappings bool) error {
 	if skipMappings {
 		return nil
 	}
 
-	indexWithSuffix := index + indexSuffix
+	targetIndex := r.overrides.targetIndex(index)
+	indexWithSuffix := targetIndex + firstGenerationSuffix
 
-	aliasExists := r.destinationElastic.DoesAliasExist(index)
+	aliasExists := dest.elastic.DoesAliasExist(targetIndex)
 	if aliasExists && !overwrite {
 		return fmt.Errorf("index with alias %s already exists. Please clean the destination indexer before"+
-			" retrying, or start the tool using --overwrite flag", index)
+			" retrying, or start the tool using --overwrite flag", targetIndex)
+	}
+	if aliasExists {
+		return nil
 	}
 
-	indexExists := r.destinationElastic.DoesIndexExist(indexWithSuffix)
+	indexExists := dest.elastic.DoesIndexExist(indexWithSuffix)
 	if indexExists && !overwrite {
 		return fmt.Errorf("index %s already exists. Please clean the destination indexer before"+
-			" retrying, or start the tool using --overwrite flag", index)
+			" retrying, or start the tool using --overwrite flag", targetIndex)
 	}
 
 	if !indexExists {
@@ -120,39 +363,115 @@ func (r *reindexer) copyMappingIfNecessary(index string, overwrite bool, skipMap
 			return fmt.Errorf("error while getting mapping from source: %w", err)
 		}
 
-		err = r.destinationElastic.CreateIndexWithMapping(indexWithSuffix, sourceMapping)
+		err = dest.elastic.CreateIndexWithMapping(indexWithSuffix, sourceMapping)
 		if err != nil {
 			return fmt.Errorf("error while creating index with mapping to destination: %w", err)
 		}
 	}
 
-	if aliasExists {
-		return nil
+	return dest.elastic.PutAlias(indexWithSuffix, targetIndex)
+}
+
+func (r *reindexer) reindexData(index string, targets *fanOut) error {
+	return r.copyAllDocuments(index, targets, func(dest *destination, bulkInfo checkpoint.BulkInfo) error {
+		return dest.checkpoint.UpdateIndexProgress(index, bulkInfo)
+	})
+}
+
+// SyncIndex will copy again all the documents of the provided index, without copying the mapping and without saving
+// any checkpoint. The documents deleted from the source are not removed from the destination
+func (r *reindexer) SyncIndex(index string) error {
+	targets := newFanOut(r.destinations)
+	err := r.copyAllDocuments(index, targets, noCheckpoint)
+	if err != nil {
+		return err
 	}
+	logFailedDestinations(index, targets)
+
+	return nil
+}
 
-	return r.destinationElastic.PutAlias(indexWithSuffix, index)
+func noCheckpoint(_ *destination, _ checkpoint.BulkInfo) error {
+	return nil
 }
 
-func (r *reindexer) reindexData(index string) error {
-	count := 0
+func (r *reindexer) copyAllDocuments(index string, targets *fanOut, onBulkIndexed func(dest *destination, bulkInfo checkpoint.BulkInfo) error) error {
+	if r.readMode == ReindexReadMode {
+		return r.runReindexTask(index, r.getAllQuery(index), wholeIndexInterval, func(bulkInfo checkpoint.BulkInfo) error {
+			return onBulkIndexed(r.destinations[0], bulkInfo)
+		})
+	}
+
+	count := uint64(0)
 	handlerFunc := func(responseBytes []byte) error {
-		count++
-		dataBuffers, err := prepareDataForIndexing(responseBytes, index, count)
-		if err != nil {
-			return fmt.Errorf("%w while preparing data for indexing", err)
-		}
+		currentCount := atomic.AddUint64(&count, 1)
+		return r.indexPage(responseBytes, index, wholeIndexInterval, int(currentCount), targets, onBulkIndexed)
+	}
+
+	numSlices := r.numSlices[index]
+	if numSlices < 2 {
+		return r.readAllDocuments(index, r.getAllQuery(index), false, handlerFunc)
+	}
+
+	return r.reindexDataSliced(index, numSlices, handlerFunc)
+}
+
+// reindexDataSliced will read and write the documents of the provided index with a worker for each slice
+func (r *reindexer) reindexDataSliced(index string, numSlices int, handlerFunc func(responseBytes []byte) error) error {
+	log.Info("reindexing using slices", "index", index, "num slices", numSlices)
+
+	wg := &sync.WaitGroup{}
+	wg.Add(numSlices)
 
-		for i := 0; i < len(dataBuffers); i++ {
-			err = r.destinationElastic.DoBulkRequest(dataBuffers[i], index)
+	errorsChan := make(chan error, numSlices)
+	for sliceID := 0; sliceID < numSlices; sliceID++ {
+		go func(id int) {
+			defer wg.Done()
+
+			err := r.readAllDocuments(index, withSlice(r.getAllQuery(index), id, numSlices), false, handlerFunc)
 			if err != nil {
-				return fmt.Errorf("%w while r.destinationElastic.DoBulkRequest", err)
+				errorsChan <- fmt.Errorf("%w for slice %d", err, id)
 			}
+		}(sliceID)
+	}
+
+	wg.Wait()
+	close(errorsChan)
+
+	for err := range errorsChan {
+		return err
+	}
+
+	return nil
+}
+
+// getAllQuery returns the query for all the documents of the provided index that match its query override, if any
+func (r *reindexer) getAllQuery(index string) *bytes.Buffer {
+	return withQueryFilter(getAll(), r.overrides.queryFilter(index))
+}
+
+// getWithTimestampQuery returns the query for the documents of the provided index from the provided interval that match
+// its query override, if any
+func (r *reindexer) getWithTimestampQuery(index string, start, stop int64) *bytes.Buffer {
+	return withQueryFilter(getWithTimestamp(start, stop, true, true), r.overrides.queryFilter(index))
+}
+
+func (r *reindexer) readAllDocuments(index string, query *bytes.Buffer, withTimestamp bool, handlerFunc func(responseBytes []byte) error) error {
+	pageSize := r.overrides.pageSize(index)
+	if r.readMode == PitReadMode {
+		if pageSize == 0 {
+			pageSize = r.pageSize
+		}
+
+		err := r.sourceElastic.DoPitRequestAllDocuments(index, withPitSort(query, withTimestamp).Bytes(), pageSize, handlerFunc)
+		if err != nil {
+			return fmt.Errorf("%w while r.sourceElastic.DoPitRequestAllDocuments", err)
 		}
 
 		return nil
 	}
 
-	err := r.sourceElastic.DoScrollRequestAllDocuments(index, getAll().Bytes(), handlerFunc)
+	err := r.sourceElastic.DoScrollRequestAllDocuments(index, withPageSize(query, pageSize).Bytes(), handlerFunc)
 	if err != nil {
 		return fmt.Errorf("%w while r.sourceElastic.DoScrollRequestAllDocuments", err)
 	}
@@ -160,76 +479,262 @@ func (r *reindexer) reindexData(index string) error {
 	return nil
 }
 
-func prepareDataForIndexing(responseBytes []byte, index string, count int) ([]*bytes.Buffer, error) {
+// indexPage writes the documents of the provided page in all the active destinations, each bulk being sent to all of
+// them concurrently, and calls the provided handler for every destination that acknowledged the whole page
+func (r *reindexer) indexPage(
+	responseBytes []byte,
+	index string,
+	interval string,
+	count int,
+	targets *fanOut,
+	onBulkIndexed func(dest *destination, bulkInfo checkpoint.BulkInfo) error,
+) error {
 	var esResponse generalElasticResponse
 	err := json.Unmarshal(responseBytes, &esResponse)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("%w while preparing data for indexing", err)
+	}
+	r.metrics.AddDocumentsRead(index, interval, uint64(len(esResponse.Hits.Hits)))
+
+	dataBuffers, err := prepareDataForIndexing(esResponse, index, count, r.transformers[index], r.overrides.bulkSize(index))
+	if err != nil {
+		return fmt.Errorf("%w while preparing data for indexing", err)
 	}
 
+	targetIndex := r.overrides.targetIndex(index)
+	for i := 0; i < len(dataBuffers); i++ {
+		// every document has a metadata line and a source line
+		numDocs := bytes.Count(dataBuffers[i].Bytes(), []byte("\n")) / 2
+		numBytes := dataBuffers[i].Len()
+
+		startTime := time.Now()
+		err = targets.write(func(dest *destination) error {
+			errBulk := dest.elastic.DoBulkRequest(bytes.NewBuffer(dataBuffers[i].Bytes()), targetIndex)
+			if errBulk != nil {
+				return fmt.Errorf("%w while r.destinationElastic.DoBulkRequest", errBulk)
+			}
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		r.metrics.ObserveBulk(index, interval, numDocs, numBytes, time.Since(startTime))
+	}
+
+	bulkInfo := extractBulkInfo(esResponse)
+
+	return targets.write(func(dest *destination) error {
+		return onBulkIndexed(dest, bulkInfo)
+	})
+}
+
+func prepareDataForIndexing(
+	esResponse generalElasticResponse,
+	index string,
+	count int,
+	transformer DocumentTransformer,
+	bulkSize int,
+) ([]*bytes.Buffer, error) {
 	resultsMap := extractSourceFromEsResponse(esResponse)
 	log.Info("\tindexing", "index", index, "bulk size", len(resultsMap), "count", count)
-	buffSlice := newBufferSlice()
+	buffSlice := newBufferSlice(bulkSize)
 	for id, source := range resultsMap {
+		if !check.IfNil(transformer) {
+			var keep bool
+			var err error
+			id, source, keep, err = transformer.TransformDocument(id, source)
+			if err != nil {
+				return nil, fmt.Errorf("%w while transforming the document", err)
+			}
+			if !keep {
+				continue
+			}
+		}
 
-		meta := []byte(fmt.Sprintf(`{ "index" : { "_id" : "%s" } }%s`, id, "\n"))
-
-		err = buffSlice.PutData(meta, source)
+		meta, err := json.Marshal(map[string]interface{}{
+			"index": map[string]string{
+				"_id": id,
+			},
+		})
 		if err != nil {
 			return nil, err
 		}
 
+		err = buffSlice.PutData(append(meta, '\n'), source)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	return buffSlice.Buffers(), nil
 }
 
+func extractBulkInfo(esResponse generalElasticResponse) checkpoint.BulkInfo {
+	hits := esResponse.Hits.Hits
+	bulkInfo := checkpoint.BulkInfo{
+		NumDocs: uint64(len(hits)),
+	}
+	if len(hits) == 0 {
+		return bulkInfo
+	}
+
+	// the timestamp of the last hit can be used for resuming only if the hits are sorted
+	lastHit := hits[len(hits)-1]
+	if len(lastHit.Sort) == 0 {
+		return bulkInfo
+	}
+
+	bulkInfo.LastTimestamp = gjson.GetBytes(lastHit.Source, "timestamp").Int()
+	for i := len(hits) - 1; i >= 0; i-- {
+		if gjson.GetBytes(hits[i].Source, "timestamp").Int() != bulkInfo.LastTimestamp {
+			break
+		}
+		bulkInfo.NumDocsAtLastTimestamp++
+	}
+
+	return bulkInfo
+}
+
 // ProcessIndexWithTimestamp will handle the reindexing from source Elastic client to destination Elastic client based on the provided interval
 func (r *reindexer) ProcessIndexWithTimestamp(index string, overwrite bool, skipMappings bool, start, stop int64, count *uint64) error {
-	err := r.copyMappingIfNecessary(index, overwrite, skipMappings)
-	if err != nil {
-		return fmt.Errorf("%w while copying the mapping for index %s", err, index)
+	destinations, progresses := r.incompleteDestinations(func(checkpointHandler CheckpointHandler) checkpoint.Progress {
+		return checkpointHandler.GetIntervalProgress(index, start, stop)
+	})
+	if len(destinations) == 0 {
+		log.Info("interval was already copied, skipping", "index", index, "start", start, "stop", stop)
+		return nil
 	}
 
-	scrollRequestHandlerFunc := r.createScrollRequestHandlerFunction(count, index)
-	err = r.sourceElastic.DoScrollRequestAllDocuments(index, getWithTimestamp(start, stop, true, true).Bytes(), scrollRequestHandlerFunc)
+	for _, dest := range destinations {
+		err := r.copyMappingIfNecessary(dest, index, overwrite, skipMappings)
+		if err != nil {
+			return fmt.Errorf("%w while copying the mapping for index %s in destination %s", err, index, dest.name)
+		}
+	}
+
+	// the documents are sorted ascending by timestamp, so the interval can be restarted from the timestamp of the last
+	// acknowledged bulk. Documents having that timestamp are indexed again: a cluster overwrites them, while a file
+	// destination appends them a second time. With more destinations, the interval is restarted from the one that is
+	// the most behind
+	from := stop
+	for i, progress := range progresses {
+		resumeFrom := start
+		if progress.LastTimestamp > start {
+			resumeFrom = progress.LastTimestamp
+			log.Info("resuming interval", "index", index, "start", start, "stop", stop, "destination", destinations[i].name,
+				"from", resumeFrom, "docs written", progress.DocsWritten)
+		}
+		if resumeFrom < from {
+			from = resumeFrom
+		}
+	}
+
+	r.addExpectedDocumentsForInterval(index, start, stop, from)
+
+	targets := newFanOut(destinations)
+	var err error
+	if r.readMode == ReindexReadMode {
+		err = r.runReindexTask(index, r.getWithTimestampQuery(index, from, stop), intervalLabel(start, stop), func(bulkInfo checkpoint.BulkInfo) error {
+			return r.destinations[0].checkpoint.UpdateIntervalProgress(index, start, stop, bulkInfo)
+		})
+	} else {
+		scrollRequestHandlerFunc := r.createScrollRequestHandlerFunction(count, index, start, stop, targets)
+		err = r.readAllDocuments(index, r.getWithTimestampQuery(index, from, stop), true, scrollRequestHandlerFunc)
+	}
 	if err != nil {
-		return fmt.Errorf("%w while r.sourceElastic.DoScrollRequestAllDocuments", err)
+		return err
+	}
+
+	for _, dest := range targets.active() {
+		err = dest.checkpoint.MarkIntervalCompleted(index, start, stop)
+		if err != nil {
+			return err
+		}
 	}
+	logFailedDestinations(index, targets)
 
 	return nil
 }
 
-// GetCountsForInterval will return the counts from source and destination client based on the provided intervals
-func (r *reindexer) GetCountsForInterval(index string, start, stop int64) (uint64, uint64, error) {
-	body := getWithTimestamp(start, stop, false, false).Bytes()
-
-	countFromSource, err := r.sourceElastic.GetCountWithBody(index, body)
+// addExpectedDocumentsForInterval records the number of documents that are going to be read for the provided interval,
+// starting from the provided timestamp. The count is used only for the metrics, so an error is just logged
+func (r *reindexer) addExpectedDocumentsForInterval(index string, start, stop int64, from int64) {
+	body := withQueryFilter(getWithTimestamp(from, stop, false, false), r.overrides.queryFilter(index))
+	numDocs, err := r.sourceElastic.GetCountWithBody(index, body.Bytes())
 	if err != nil {
-		return 0, 0, err
+		log.Warn("cannot get the source count of interval", "index", index, "start", start, "stop", stop, "error", err)
+		return
+	}
+
+	r.metrics.AddExpectedDocuments(index, intervalLabel(start, stop), numDocs)
+}
+
+func intervalLabel(start, stop int64) string {
+	return fmt.Sprintf("%d-%d", start, stop)
+}
+
+// SyncIndexWithTimestamp will copy the documents of the provided index from the provided interval, without copying the
+// mapping and without saving any checkpoint
+func (r *reindexer) SyncIndexWithTimestamp(index string, start, stop int64) error {
+	if r.readMode == ReindexReadMode {
+		return r.runReindexTask(index, r.getWithTimestampQuery(index, start, stop), intervalLabel(start, stop), func(_ checkpoint.BulkInfo) error {
+			return nil
+		})
+	}
+
+	targets := newFanOut(r.destinations)
+	count := uint64(0)
+	handlerFunc := func(responseBytes []byte) error {
+		currentCount := atomic.AddUint64(&count, 1)
+		return r.indexPage(responseBytes, index, intervalLabel(start, stop), int(currentCount), targets, noCheckpoint)
 	}
-	countFromDestination, err := r.destinationElastic.GetCountWithBody(index, body)
+
+	err := r.readAllDocuments(index, r.getWithTimestampQuery(index, start, stop), true, handlerFunc)
 	if err != nil {
-		return 0, 0, err
+		return err
 	}
+	logFailedDestinations(index, targets)
 
-	return countFromSource, countFromDestination, nil
+	return nil
 }
 
-func (r *reindexer) createScrollRequestHandlerFunction(count *uint64, index string) func([]byte) error {
-	return func(responseBytes []byte) error {
-		atomic.AddUint64(count, 1)
-		dataBuffers, errP := prepareDataForIndexing(responseBytes, index, int(atomic.LoadUint64(count)))
-		if errP != nil {
-			return fmt.Errorf("%w while preparing data for indexing", errP)
-		}
+// GetCountsForInterval will return the count from the source and the count from every destination, by its name, based
+// on the provided interval. The query override of the index is applied only on the source, the destinations having
+// only the matching documents
+func (r *reindexer) GetCountsForInterval(index string, start, stop int64) (uint64, map[string]uint64, error) {
+	countFromSource, err := r.GetSourceCountForInterval(index, start, stop)
+	if err != nil {
+		return 0, nil, err
+	}
 
-		for i := 0; i < len(dataBuffers); i++ {
-			err := r.destinationElastic.DoBulkRequest(dataBuffers[i], index)
-			if err != nil {
-				return fmt.Errorf("%w while r.destinationElastic.DoBulkRequest", err)
-			}
+	body := getWithTimestamp(start, stop, false, false)
+	countsFromDestinations := make(map[string]uint64, len(r.destinations))
+	for _, dest := range r.destinations {
+		countFromDestination, errCount := dest.elastic.GetCountWithBody(r.overrides.targetIndex(index), body.Bytes())
+		if errCount != nil {
+			return 0, nil, fmt.Errorf("%w for destination %s", errCount, dest.name)
 		}
-		return nil
+
+		countsFromDestinations[dest.name] = countFromDestination
+	}
+
+	return countFromSource, countsFromDestinations, nil
+}
+
+// GetSourceCountForInterval will return the number of source documents of the provided index from the provided
+// interval, with the query override of the index applied
+func (r *reindexer) GetSourceCountForInterval(index string, start, stop int64) (uint64, error) {
+	body := getWithTimestamp(start, stop, false, false)
+
+	return r.sourceElastic.GetCountWithBody(index, withQueryFilter(body, r.overrides.queryFilter(index)).Bytes())
+}
+
+func (r *reindexer) createScrollRequestHandlerFunction(count *uint64, index string, start, stop int64, targets *fanOut) func([]byte) error {
+	return func(responseBytes []byte) error {
+		currentCount := atomic.AddUint64(count, 1)
+		return r.indexPage(responseBytes, index, intervalLabel(start, stop), int(currentCount), targets, func(dest *destination, bulkInfo checkpoint.BulkInfo) error {
+			return dest.checkpoint.UpdateIntervalProgress(index, start, stop, bulkInfo)
+		})
 	}
 }
@@ -2,29 +2,191 @@ package process
 
 import (
 	"errors"
+	"fmt"
 
+	"github.com/multiversx/mx-chain-core-go/core/check"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/elastic"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/filestore"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/transform"
 )
 
-// CreateReindexer will create the source and destination elastic handlers and create a reindexer based on them
-func CreateReindexer(cfg *config.GeneralConfig) (*reindexer, error) {
-	if cfg.Indexers.Input.URL == "" {
-		return nil, errors.New("empty url for the input cluster")
+const (
+	// ElasticInstanceType defines an instance that is an Elasticsearch cluster
+	ElasticInstanceType = "elastic"
+	// FileInstanceType defines an instance that is a local directory with NDJSON files
+	FileInstanceType = "file"
+)
+
+var errVerifyFileInstance = errors.New("cannot verify a file instance, the verification needs search requests")
+
+// bulkRetryNotifier defines the behaviour of a destination client that can report the bulk items sent again
+type bulkRetryNotifier interface {
+	SetBulkRetryHandler(handler func(index string, numItems int))
+}
+
+// OutputConfigs returns the configured outputs: the [[config.outputs]] list if it is not empty, or [config.output]
+// otherwise. The outputs without a name are named after their position
+func OutputConfigs(cfg config.IndexersConfig) []config.ElasticInstanceConfig {
+	if len(cfg.Outputs) == 0 {
+		output := cfg.Output
+		if output.Name == "" {
+			output.Name = defaultDestinationName
+		}
+
+		return []config.ElasticInstanceConfig{output}
+	}
+
+	outputs := make([]config.ElasticInstanceConfig, 0, len(cfg.Outputs))
+	for i, output := range cfg.Outputs {
+		if output.Name == "" {
+			output.Name = fmt.Sprintf("%s-%d", defaultDestinationName, i)
+		}
+
+		outputs = append(outputs, output)
+	}
+
+	return outputs
+}
+
+// CreateReindexer will create the source and destination elastic handlers and create a reindexer based on them. Every
+// output from OutputConfigs gets the checkpoint handler from the same position. The custom transformer factories are
+// used for the transformation steps that are not built in
+func CreateReindexer(
+	cfg *config.GeneralConfig,
+	checkpointHandlers []CheckpointHandler,
+	customTransformers map[string]transform.TransformerFactory,
+	metrics MetricsHandler,
+) (*reindexer, error) {
+	outputs := OutputConfigs(cfg.Indexers)
+	if len(checkpointHandlers) != len(outputs) {
+		return nil, fmt.Errorf("%d checkpoint handlers provided for %d outputs", len(checkpointHandlers), len(outputs))
+	}
+
+	sourceElastic, err := createElasticClient(cfg.Indexers.Input)
+	if err != nil {
+		return nil, fmt.Errorf("%w for the input cluster", err)
+	}
+
+	destinations := make([]Destination, 0, len(outputs))
+	for i, output := range outputs {
+		destinationElastic, errCreate := createElasticClient(output)
+		if errCreate != nil {
+			return nil, fmt.Errorf("%w for the output %s", errCreate, output.Name)
+		}
+
+		notifier, ok := destinationElastic.(bulkRetryNotifier)
+		if ok && !check.IfNil(metrics) {
+			notifier.SetBulkRetryHandler(createBulkRetryHandler(cfg.Indexers.IndicesConfig.Overrides, metrics))
+		}
+
+		destinations = append(destinations, Destination{
+			Name:       output.Name,
+			Elastic:    destinationElastic,
+			Checkpoint: checkpointHandlers[i],
+		})
 	}
-	if cfg.Indexers.Output.URL == "" {
-		return nil, errors.New("empty url for the output cluster")
+
+	transformers, err := createTransformers(cfg.Indexers.Transforms, customTransformers)
+	if err != nil {
+		return nil, err
 	}
 
-	sourceElastic, err := elastic.NewElasticClient(cfg.Indexers.Input)
+	return newReindexer(ArgsReindexer{
+		SourceElastic:          sourceElastic,
+		DestinationElastic:     destinations[0].Elastic,
+		Indices:                cfg.Indexers.IndicesConfig.Indices,
+		NumSlices:              cfg.Indexers.IndicesConfig.NumSlices,
+		Checkpoint:             destinations[0].Checkpoint,
+		ReaderConfig:           cfg.Indexers.Reader,
+		Transformers:           transformers,
+		Overrides:              cfg.Indexers.IndicesConfig.Overrides,
+		Metrics:                metrics,
+		DestinationName:        destinations[0].Name,
+		AdditionalDestinations: destinations[1:],
+		SourceConfig:           cfg.Indexers.Input,
+	})
+}
+
+func createTransformers(
+	cfg map[string][]config.TransformConfig,
+	customTransformers map[string]transform.TransformerFactory,
+) (map[string]DocumentTransformer, error) {
+	chains, err := transform.CreateChains(cfg, customTransformers)
 	if err != nil {
 		return nil, err
 	}
 
-	destinationElastic, err := elastic.NewElasticClient(cfg.Indexers.Output)
+	transformers := make(map[string]DocumentTransformer, len(chains))
+	for index, chain := range chains {
+		transformers[index] = chain
+	}
+
+	return transformers, nil
+}
+
+// createBulkRetryHandler returns a handler that records the retried items under the source index name, the destination
+// client knowing only the name of the target index
+func createBulkRetryHandler(overrides map[string]config.IndexOverrideConfig, metrics MetricsHandler) func(index string, numItems int) {
+	sourceIndices := make(map[string]string, len(overrides))
+	for index := range overrides {
+		sourceIndices[indexOverrides(overrides).targetIndex(index)] = index
+	}
+
+	return func(targetIndex string, numItems int) {
+		index, found := sourceIndices[targetIndex]
+		if !found {
+			index = targetIndex
+		}
+
+		metrics.AddRetriedItems(index, numItems)
+	}
+}
+
+// CreateVerifier will create the source and destination elastic handlers and create a verifier based on them. The
+// provided output is one of OutputConfigs. The transforms are applied on the source documents before comparing them
+func CreateVerifier(
+	cfg *config.GeneralConfig,
+	output config.ElasticInstanceConfig,
+	reindexer ReindexerHandler,
+	customTransformers map[string]transform.TransformerFactory,
+) (*verifier, error) {
+	if cfg.Indexers.Input.Type == FileInstanceType {
+		return nil, fmt.Errorf("%w, the input is a file directory", errVerifyFileInstance)
+	}
+	if output.Type == FileInstanceType {
+		return nil, fmt.Errorf("%w, the output %s is a file directory", errVerifyFileInstance, output.Name)
+	}
+
+	sourceElastic, err := createElasticClient(cfg.Indexers.Input)
+	if err != nil {
+		return nil, fmt.Errorf("%w for the input cluster", err)
+	}
+
+	destinationElastic, err := createElasticClient(output)
+	if err != nil {
+		return nil, fmt.Errorf("%w for the output %s", err, output.Name)
+	}
+
+	transformers, err := createTransformers(cfg.Indexers.Transforms, customTransformers)
 	if err != nil {
 		return nil, err
 	}
 
-	return newReindexer(sourceElastic, destinationElastic, cfg.Indexers.IndicesConfig.Indices)
+	return NewVerifier(sourceElastic, destinationElastic, reindexer, transformers, cfg.Indexers)
+}
+
+func createElasticClient(cfg config.ElasticInstanceConfig) (ElasticClientHandler, error) {
+	switch cfg.Type {
+	case "", ElasticInstanceType:
+		if cfg.URL == "" {
+			return nil, errors.New("empty url")
+		}
+
+		return elastic.NewElasticClient(cfg)
+	case FileInstanceType:
+		return filestore.NewFileClient(cfg.File)
+	default:
+		return nil, fmt.Errorf("unknown instance type %s", cfg.Type)
+	}
 }
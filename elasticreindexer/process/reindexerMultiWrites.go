@@ -2,9 +2,12 @@ package process
 
 import (
 	"errors"
+	"fmt"
 	"sync"
 	"time"
 
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
 )
 
@@ -19,18 +22,30 @@ type reindexerMultiWrite struct {
 	numParallelWrite     int
 	blockChainStartTime  int64
 	enabled              bool
+	balancedIntervals    bool
+	targetDocs           uint64
+	overrides            indexOverrides
 
 	reindexerClient ReindexerHandler
+	checkpoint      CheckpointHandler
 }
 
-func NewReindexerMultiWrite(reindexer ReindexerHandler, cfg config.IndicesConfig) (*reindexerMultiWrite, error) {
+func NewReindexerMultiWrite(reindexer ReindexerHandler, cfg config.IndicesConfig, checkpointHandler CheckpointHandler) (*reindexerMultiWrite, error) {
 	if reindexer == nil {
 		return nil, errors.New("nil ReindexerHandler")
 	}
+	if check.IfNil(checkpointHandler) {
+		return nil, errNilCheckpointHandler
+	}
 	if cfg.WithTimestamp.BlockchainStartTime <= 0 {
 		return nil, errors.New("blockchainStartTime cannot be less than zero")
 	}
 
+	overrides, err := newIndexOverrides(cfg.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
 	return &reindexerMultiWrite{
 		reindexerClient:      reindexer,
 		indicesNoTimestamp:   cfg.Indices,
@@ -38,6 +53,10 @@ func NewReindexerMultiWrite(reindexer ReindexerHandler, cfg config.IndicesConfig
 		numParallelWrite:     cfg.WithTimestamp.NumParallelWrites,
 		blockChainStartTime:  cfg.WithTimestamp.BlockchainStartTime,
 		enabled:              cfg.WithTimestamp.Enabled,
+		balancedIntervals:    cfg.WithTimestamp.BalancedIntervals,
+		targetDocs:           cfg.WithTimestamp.TargetDocsPerInterval,
+		overrides:            overrides,
+		checkpoint:           checkpointHandler,
 	}, nil
 }
 
@@ -56,23 +75,47 @@ func (rmw *reindexerMultiWrite) ProcessNoTimestamp(overwrite bool, skipMappings
 	return nil
 }
 
+// CheckMappings compares the source mappings of all the configured indices with the destination ones before any data
+// is copied
+func (rmw *reindexerMultiWrite) CheckMappings(skipMappings bool, force bool) error {
+	indices := append([]string{}, rmw.indicesNoTimestamp...)
+	if rmw.enabled {
+		indices = append(indices, rmw.indicesWithTimestamp...)
+	}
+
+	return rmw.reindexerClient.CheckMappings(skipMappings, force, indices...)
+}
+
 func (rmw *reindexerMultiWrite) ProcessWithTimestamp(overwrite bool, skipMappings bool) error {
 	if !rmw.enabled {
 		return nil
 	}
 
 	currentTimestampUnix := time.Now().Unix()
-	intervals, err := computeIntervals(rmw.blockChainStartTime, currentTimestampUnix, int64(rmw.numParallelWrite))
-	if err != nil {
-		return err
-	}
-
 	for _, index := range rmw.indicesWithTimestamp {
 		if index == "" {
 			continue
 		}
 
-		err = rmw.reindexBasedOnIntervals(index, intervals, overwrite, skipMappings)
+		start, end := rmw.overrides.timeRange(index, rmw.blockChainStartTime, currentTimestampUnix)
+		numParallelWrites := rmw.overrides.numParallelWrites(index, rmw.numParallelWrite)
+		indexIntervals, resumed, err := rmw.getIntervalsForIndex(index, func() ([]*interval, error) {
+			return rmw.computeIntervalsForIndex(index, start, end, numParallelWrites)
+		})
+		if err != nil {
+			return fmt.Errorf("%w for index %s", err, index)
+		}
+
+		// the documents written for the planned intervals are kept when resuming
+		if overwrite && !resumed {
+			err = rmw.reindexerClient.ClearIndex(index)
+			if err != nil {
+				return err
+			}
+		}
+
+		// the destination index was already created by the run that planned the intervals
+		err = rmw.reindexBasedOnIntervals(index, indexIntervals, numParallelWrites, overwrite || resumed, skipMappings)
 		if err != nil {
 			return err
 		}
@@ -81,33 +124,98 @@ func (rmw *reindexerMultiWrite) ProcessWithTimestamp(overwrite bool, skipMapping
 	return nil
 }
 
+func (rmw *reindexerMultiWrite) computeIntervalsForIndex(index string, start, end int64, numParallelWrites int) ([]*interval, error) {
+	if !rmw.balancedIntervals {
+		return computeIntervals(start, end, int64(numParallelWrites))
+	}
+
+	log.Info("planning balanced intervals", "index", index)
+	intervals, err := computeBalancedIntervals(start, end, int64(numParallelWrites), rmw.targetDocs, func(start, stop int64) (uint64, error) {
+		return rmw.reindexerClient.GetSourceCountForInterval(index, start, stop)
+	})
+	if err != nil {
+		return nil, err
+	}
+	log.Info("planned balanced intervals", "index", index, "num intervals", len(intervals))
+
+	return intervals, nil
+}
+
+// getIntervalsForIndex returns the intervals saved in the checkpoint for the provided index, if any, so a resumed run
+// will use the same interval boundaries as the run that was interrupted. Otherwise, the intervals are computed and saved
+func (rmw *reindexerMultiWrite) getIntervalsForIndex(index string, computeIntervalsFunc func() ([]*interval, error)) ([]*interval, bool, error) {
+	savedIntervals := rmw.checkpoint.GetIntervals(index)
+	if len(savedIntervals) > 0 {
+		indexIntervals := make([]*interval, 0, len(savedIntervals))
+		for _, savedInterval := range savedIntervals {
+			indexIntervals = append(indexIntervals, &interval{
+				start: savedInterval.Start,
+				stop:  savedInterval.Stop,
+			})
+		}
+
+		return indexIntervals, true, nil
+	}
+
+	intervals, err := computeIntervalsFunc()
+	if err != nil {
+		return nil, false, err
+	}
+
+	intervalsToSave := make([]checkpoint.IntervalProgress, 0, len(intervals))
+	for _, interv := range intervals {
+		intervalsToSave = append(intervalsToSave, checkpoint.IntervalProgress{
+			Start: interv.start,
+			Stop:  interv.stop,
+		})
+	}
+
+	err = rmw.checkpoint.SetIntervals(index, intervalsToSave)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return intervals, false, nil
+}
+
 func (rmw *reindexerMultiWrite) reindexBasedOnIntervals(
 	index string,
 	intervals []*interval,
+	numParallelWrites int,
 	overwrite bool,
 	skipMappings bool,
 ) error {
 	wg := &sync.WaitGroup{}
 	wg.Add(len(intervals))
 
+	// the balanced intervals can be more than the parallel writes, case when only numParallelWrites run at a time
+	if numParallelWrites < 1 {
+		numParallelWrites = 1
+	}
+	throttler := make(chan struct{}, numParallelWrites)
+
 	log.Info("starting reindexing", "index", index)
 
 	count := uint64(0)
 
 	for idx, interv := range intervals {
+		throttler <- struct{}{}
 		go func(startTime, stopTime int64, idx int, w *sync.WaitGroup) {
 			defer func() {
+				<-throttler
 				time.Sleep(time.Second)
-				countSource, countDestination, err := rmw.reindexerClient.GetCountsForInterval(index, startTime, stopTime)
+				countSource, countsDestinations, err := rmw.reindexerClient.GetCountsForInterval(index, startTime, stopTime)
 				if err != nil {
 					log.Warn("cannot get count", "interval nr", idx, "index", index, "error", err)
 				}
 
-				if countSource != countDestination {
-					log.Warn("counts are not equals", "interval nr", idx, "count source", countSource, "count destination", countDestination)
-				}
+				for destinationName, countDestination := range countsDestinations {
+					if countSource != countDestination {
+						log.Warn("counts are not equals", "interval nr", idx, "destination", destinationName, "count source", countSource, "count destination", countDestination)
+					}
 
-				log.Info("done", "interval nr", idx, "count source", countSource, "count destination", countDestination)
+					log.Info("done", "interval nr", idx, "destination", destinationName, "count source", countSource, "count destination", countDestination)
+				}
 				w.Done()
 			}()
 
@@ -125,6 +233,8 @@ func (rmw *reindexerMultiWrite) reindexBasedOnIntervals(
 	return nil
 }
 
+// computeIntervals splits the provided time range, both ends included, into half-open intervals. The stop of an interval
+// is the start of the next one and the last interval stops after the end of the range
 func computeIntervals(startTime, endTime int64, numIntervals int64) ([]*interval, error) {
 	if startTime > endTime {
 		return nil, errors.New("blockchain start time is greater than current timestamp")
@@ -132,7 +242,7 @@ func computeIntervals(startTime, endTime int64, numIntervals int64) ([]*interval
 	if numIntervals < 2 {
 		return []*interval{{
 			start: startTime,
-			stop:  endTime,
+			stop:  endTime + 1,
 		}}, nil
 	}
 
@@ -146,7 +256,7 @@ func computeIntervals(startTime, endTime int64, numIntervals int64) ([]*interval
 		stop := startTime + (idx+1)*step
 
 		if idx == numIntervals-1 {
-			stop = endTime
+			stop = endTime + 1
 		}
 
 		intervals = append(intervals, &interval{
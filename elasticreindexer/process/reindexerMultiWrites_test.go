@@ -23,7 +23,75 @@ func TestComputeIntervals(t *testing.T) {
 		},
 		{
 			start: 1653978826,
-			stop:  1653981227,
+			stop:  1653981228,
 		},
 	}, res)
 }
+
+// createSkewedCounter returns a counter of the documents having the timestamps 0..999, where the timestamp t has t documents
+func createSkewedCounter(numCalls *int) intervalCounter {
+	return func(start, stop int64) (uint64, error) {
+		*numCalls++
+		count := uint64(0)
+		for t := start; t < stop && t < 1000; t++ {
+			count += uint64(t)
+		}
+		return count, nil
+	}
+}
+
+func TestComputeBalancedIntervals(t *testing.T) {
+	t.Parallel()
+
+	numCalls := 0
+	counter := createSkewedCounter(&numCalls)
+	total, _ := counter(0, 1000)
+
+	res, err := computeBalancedIntervals(0, 999, 4, 0, counter)
+	require.Nil(t, err)
+	require.Equal(t, int64(0), res[0].start)
+	require.Equal(t, int64(1000), res[len(res)-1].stop)
+	for idx := 1; idx < len(res); idx++ {
+		require.Equal(t, res[idx-1].stop, res[idx].start)
+	}
+
+	target := (total + 3) / 4
+	sum := uint64(0)
+	for _, interv := range res {
+		count, _ := counter(interv.start, interv.stop)
+		require.LessOrEqual(t, count, target)
+		sum += count
+	}
+	require.Equal(t, total, sum)
+	// the equal time slices would hold 1/16 of the documents in the first slice and 7/16 in the last one
+	firstCount, _ := counter(res[0].start, res[0].stop)
+	require.Greater(t, firstCount, total/8)
+}
+
+func TestComputeBalancedIntervalsWithTarget(t *testing.T) {
+	t.Parallel()
+
+	numCalls := 0
+	counter := createSkewedCounter(&numCalls)
+	total, _ := counter(0, 1000)
+
+	res, err := computeBalancedIntervals(0, 999, 4, total/20, counter)
+	require.Nil(t, err)
+	require.GreaterOrEqual(t, len(res), 20)
+	require.LessOrEqual(t, len(res), 40)
+}
+
+func TestComputeBalancedIntervalsNoDocuments(t *testing.T) {
+	t.Parallel()
+
+	res, err := computeBalancedIntervals(10, 20, 4, 0, func(start, stop int64) (uint64, error) {
+		return 0, nil
+	})
+	require.Nil(t, err)
+	require.Equal(t, []*interval{{start: 10, stop: 21}}, res)
+
+	_, err = computeBalancedIntervals(20, 10, 4, 0, func(start, stop int64) (uint64, error) {
+		return 0, nil
+	})
+	require.NotNil(t, err)
+}
@@ -2,10 +2,21 @@ package process
 
 import (
 	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
 	"testing"
+	"time"
 
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/filestore"
 	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/process/mock"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/transform"
 	"github.com/stretchr/testify/require"
+	"github.com/tidwall/gjson"
 )
 
 const testIndex = "index"
@@ -24,14 +35,24 @@ func TestCopyMapping(t *testing.T) {
 		testCopyMappingOverwriteAliasAndIndexExistShouldCreate)
 	t.Run("overwrite - no alias - index => should create alias",
 		testCopyMappingOverwriteAliasDoesNotExistShouldCreateAlias)
-	t.Run("overwrite - alias - no index => should create alias",
-		testCopyMappingOverwriteAliasExistsIndexDoesNotExistShouldCreateIndex)
+	t.Run("overwrite - alias - no first generation index => should not create",
+		testCopyMappingOverwriteAliasExistsIndexDoesNotExistShouldNotCreateIndex)
 	t.Run("overwrite - alias - index => should not copy/create",
 		testCopyMappingOverwriteAliasAndIndexExistShouldNotCreate)
 	t.Run("skip-mappings",
 		testSkipMappings)
 }
 
+func createMockArgsReindexer() ArgsReindexer {
+	return ArgsReindexer{
+		SourceElastic:      &mock.ElasticClientStub{},
+		DestinationElastic: &mock.ElasticClientStub{},
+		Indices:            []string{testIndex},
+		Checkpoint:         &mock.CheckpointHandlerStub{},
+		Metrics:            &mock.MetricsHandlerStub{},
+	}
+}
+
 func testCopyMappingNoOverwriteShouldCreate(t *testing.T) {
 	getMappingCalled := false
 	putAliasCalled := false
@@ -60,9 +81,12 @@ func testCopyMappingNoOverwriteShouldCreate(t *testing.T) {
 		},
 	}
 
-	r, _ := newReindexer(sourceClient, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, false, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, false, false)
 	require.NoError(t, err)
 	require.True(t, getMappingCalled)
 	require.True(t, putAliasCalled)
@@ -79,9 +103,11 @@ func testCopyMappingNoOverwriteAliasExistsIndexDoesNotExistShouldErr(t *testing.
 		},
 	}
 
-	r, _ := newReindexer(&mock.ElasticClientStub{}, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, false, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, false, false)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "index with alias index already exists.")
 }
@@ -96,9 +122,11 @@ func testCopyMappingNoOverwriteAliasAndIndexExistShouldErr(t *testing.T) {
 		},
 	}
 
-	r, _ := newReindexer(&mock.ElasticClientStub{}, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, false, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, false, false)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "index with alias index already exists.")
 }
@@ -110,9 +138,12 @@ func testCopyMappingNoOverwriteAliasDoesNotExistsIndexExistsShouldErr(t *testing
 		},
 	}
 
-	r, _ := newReindexer(&mock.ElasticClientStub{}, destinationClient, []string{"test-index"})
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	args.Indices = []string{"test-index"}
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary("test-index", false, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], "test-index", false, false)
 	require.Error(t, err)
 	require.Contains(t, err.Error(), "index test-index already exists.")
 }
@@ -145,9 +176,12 @@ func testCopyMappingOverwriteAliasAndIndexExistShouldNotCreate(t *testing.T) {
 		},
 	}
 
-	r, _ := newReindexer(sourceClient, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, true, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, true, false)
 	require.NoError(t, err)
 	require.False(t, getMappingCalled)
 	require.False(t, putAliasCalled)
@@ -174,15 +208,18 @@ func testCopyMappingOverwriteAliasDoesNotExistShouldCreateAlias(t *testing.T) {
 		},
 	}
 
-	r, _ := newReindexer(&mock.ElasticClientStub{}, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, true, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, true, false)
 	require.NoError(t, err)
 	require.True(t, putAlias)
 	require.False(t, createIndexCalled)
 }
 
-func testCopyMappingOverwriteAliasExistsIndexDoesNotExistShouldCreateIndex(t *testing.T) {
+// the first generation index can be deleted after rollovers, the documents being written in the write index of the alias
+func testCopyMappingOverwriteAliasExistsIndexDoesNotExistShouldNotCreateIndex(t *testing.T) {
 	putAlias := false
 	createIndexCalled := false
 	destinationClient := &mock.ElasticClientStub{
@@ -202,12 +239,14 @@ func testCopyMappingOverwriteAliasExistsIndexDoesNotExistShouldCreateIndex(t *te
 		},
 	}
 
-	r, _ := newReindexer(&mock.ElasticClientStub{}, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, true, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, true, false)
 	require.NoError(t, err)
 	require.False(t, putAlias)
-	require.True(t, createIndexCalled)
+	require.False(t, createIndexCalled)
 }
 
 func testCopyMappingOverwriteAliasAndIndexExistShouldCreate(t *testing.T) {
@@ -230,9 +269,11 @@ func testCopyMappingOverwriteAliasAndIndexExistShouldCreate(t *testing.T) {
 		},
 	}
 
-	r, _ := newReindexer(&mock.ElasticClientStub{}, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, true, false)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, true, false)
 	require.NoError(t, err)
 	require.True(t, putAlias)
 	require.True(t, createIndexCalled)
@@ -259,9 +300,502 @@ func testSkipMappings(t *testing.T) {
 		},
 	}
 
-	r, _ := newReindexer(&mock.ElasticClientStub{}, destinationClient, []string{"index"})
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	r, _ := newReindexer(args)
 
-	err := r.copyMappingIfNecessary(testIndex, false, true)
+	err := r.copyMappingIfNecessary(r.destinations[0], testIndex, false, true)
 	require.NoError(t, err)
 	require.False(t, called)
 }
+
+func TestProcessIndexWithTimestamp(t *testing.T) {
+	t.Run("completed interval => should skip", testProcessIndexWithTimestampCompletedIntervalShouldSkip)
+	t.Run("partial interval => should resume from last timestamp", testProcessIndexWithTimestampPartialIntervalShouldResume)
+}
+
+func testProcessIndexWithTimestampCompletedIntervalShouldSkip(t *testing.T) {
+	scrollCalled := false
+	sourceClient := &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(_ string, _ []byte, _ func(responseBytes []byte) error) error {
+			scrollCalled = true
+			return nil
+		},
+	}
+	checkpointHandler := &mock.CheckpointHandlerStub{
+		GetIntervalProgressCalled: func(_ string, _, _ int64) checkpoint.Progress {
+			return checkpoint.Progress{Completed: true}
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.Checkpoint = checkpointHandler
+	r, _ := newReindexer(args)
+
+	count := uint64(0)
+	err := r.ProcessIndexWithTimestamp(testIndex, false, true, 100, 200, &count)
+	require.NoError(t, err)
+	require.False(t, scrollCalled)
+}
+
+func testProcessIndexWithTimestampPartialIntervalShouldResume(t *testing.T) {
+	var queryBody []byte
+	sourceClient := &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(_ string, body []byte, handlerFunc func(responseBytes []byte) error) error {
+			queryBody = body
+			return handlerFunc([]byte(`{"hits":{"hits":[{"_id":"a","_source":{"timestamp":160},"sort":[160000]}]}}`))
+		},
+	}
+
+	var savedBulk checkpoint.BulkInfo
+	completed := false
+	checkpointHandler := &mock.CheckpointHandlerStub{
+		GetIntervalProgressCalled: func(_ string, _, _ int64) checkpoint.Progress {
+			return checkpoint.Progress{LastTimestamp: 150, DocsWritten: 10}
+		},
+		UpdateIntervalProgressCalled: func(_ string, start, stop int64, bulk checkpoint.BulkInfo) error {
+			require.Equal(t, int64(100), start)
+			require.Equal(t, int64(200), stop)
+			savedBulk = bulk
+			return nil
+		},
+		MarkIntervalCompletedCalled: func(_ string, _, _ int64) error {
+			completed = true
+			return nil
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.Checkpoint = checkpointHandler
+	r, _ := newReindexer(args)
+
+	count := uint64(0)
+	err := r.ProcessIndexWithTimestamp(testIndex, false, true, 100, 200, &count)
+	require.NoError(t, err)
+	require.Equal(t, getWithTimestamp(150, 200, true, true).Bytes(), queryBody)
+	require.Equal(t, uint64(1), savedBulk.NumDocs)
+	require.Equal(t, int64(160), savedBulk.LastTimestamp)
+	require.True(t, completed)
+}
+
+func TestNewReindexer_InvalidReadModeShouldErr(t *testing.T) {
+	args := createMockArgsReindexer()
+	args.ReaderConfig = config.ReaderConfig{Mode: "invalid"}
+	r, err := newReindexer(args)
+	require.Nil(t, r)
+	require.Error(t, err)
+}
+
+func TestReindexData_PitReadModeShouldUsePointInTime(t *testing.T) {
+	pitCalled := false
+	sourceClient := &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(_ string, _ []byte, _ func(responseBytes []byte) error) error {
+			require.Fail(t, "scroll should not be used")
+			return nil
+		},
+		DoPitRequestAllDocumentsCalled: func(index string, body []byte, pageSize int, _ func(responseBytes []byte) error) error {
+			pitCalled = true
+			require.Equal(t, testIndex, index)
+			require.Equal(t, 500, pageSize)
+			require.Contains(t, string(body), "_shard_doc")
+			return nil
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.ReaderConfig = config.ReaderConfig{Mode: PitReadMode, PageSize: 500}
+	r, _ := newReindexer(args)
+
+	err := r.reindexData(testIndex, newFanOut(r.destinations))
+	require.NoError(t, err)
+	require.True(t, pitCalled)
+}
+
+func TestReindexData_WithSlicesShouldReadEverySlice(t *testing.T) {
+	mut := sync.Mutex{}
+	readSlices := make(map[int64]struct{})
+	sourceClient := &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(_ string, body []byte, handlerFunc func(responseBytes []byte) error) error {
+			require.Equal(t, int64(3), gjson.GetBytes(body, "slice.max").Int())
+
+			mut.Lock()
+			readSlices[gjson.GetBytes(body, "slice.id").Int()] = struct{}{}
+			mut.Unlock()
+
+			return handlerFunc([]byte(`{"hits":{"hits":[{"_id":"a","_source":{}}]}}`))
+		},
+	}
+	numBulks := uint64(0)
+	destinationClient := &mock.ElasticClientStub{
+		DoBulkRequestCalled: func(_ *bytes.Buffer, _ string) error {
+			atomic.AddUint64(&numBulks, 1)
+			return nil
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.DestinationElastic = destinationClient
+	args.NumSlices = map[string]int{testIndex: 3}
+	r, _ := newReindexer(args)
+
+	err := r.reindexData(testIndex, newFanOut(r.destinations))
+	require.NoError(t, err)
+	require.Len(t, readSlices, 3)
+	require.Equal(t, uint64(3), atomic.LoadUint64(&numBulks))
+}
+
+func TestReindexData_WithOverridesShouldUseIndexSettings(t *testing.T) {
+	sourceClient := &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(index string, body []byte, handlerFunc func(responseBytes []byte) error) error {
+			require.Equal(t, testIndex, index)
+			require.Equal(t, int64(2), gjson.GetBytes(body, "size").Int())
+			require.Equal(t, int64(1), gjson.GetBytes(body, "query.bool.filter.1.term.shardID").Int())
+
+			return handlerFunc([]byte(`{"hits":{"hits":[{"_id":"a","_source":{"value":"aaaa"}},{"_id":"b","_source":{"value":"bbbb"}}]}}`))
+		},
+	}
+	bulkIndices := make([]string, 0)
+	destinationClient := &mock.ElasticClientStub{
+		DoBulkRequestCalled: func(_ *bytes.Buffer, index string) error {
+			bulkIndices = append(bulkIndices, index)
+			return nil
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.DestinationElastic = destinationClient
+	args.Overrides = map[string]config.IndexOverrideConfig{
+		testIndex: {
+			Query:           `{"term":{"shardID":1}}`,
+			TargetPrefix:    "devnet-",
+			BulkSizeInBytes: 1,
+			PageSize:        2,
+		},
+	}
+	r, err := newReindexer(args)
+	require.NoError(t, err)
+
+	err = r.reindexData(testIndex, newFanOut(r.destinations))
+	require.NoError(t, err)
+	// every document is sent in its own bulk because of the bulk size
+	require.Equal(t, []string{"devnet-" + testIndex, "devnet-" + testIndex}, bulkIndices)
+}
+
+func TestNewReindexer_InvalidQueryOverrideShouldErr(t *testing.T) {
+	args := createMockArgsReindexer()
+	args.Overrides = map[string]config.IndexOverrideConfig{
+		testIndex: {Query: "{invalid"},
+	}
+	r, err := newReindexer(args)
+	require.Nil(t, r)
+	require.Error(t, err)
+}
+
+func TestNewReindexer_NilMetricsShouldErr(t *testing.T) {
+	args := createMockArgsReindexer()
+	args.Metrics = nil
+	r, err := newReindexer(args)
+	require.Nil(t, r)
+	require.Equal(t, errNilMetricsHandler, err)
+}
+
+func TestReindexData_ShouldRecordMetrics(t *testing.T) {
+	sourceClient := &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(_ string, _ []byte, handlerFunc func(responseBytes []byte) error) error {
+			return handlerFunc([]byte(`{"hits":{"hits":[{"_id":"a","_source":{}},{"_id":"b","_source":{}}]}}`))
+		},
+	}
+	docsRead := uint64(0)
+	docsWritten := 0
+	metrics := &mock.MetricsHandlerStub{
+		AddDocumentsReadCalled: func(index string, interval string, numDocs uint64) {
+			require.Equal(t, testIndex, index)
+			require.Equal(t, wholeIndexInterval, interval)
+			docsRead += numDocs
+		},
+		ObserveBulkCalled: func(_ string, _ string, numDocs int, numBytes int, _ time.Duration) {
+			require.Greater(t, numBytes, 0)
+			docsWritten += numDocs
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.Metrics = metrics
+	r, _ := newReindexer(args)
+
+	err := r.reindexData(testIndex, newFanOut(r.destinations))
+	require.NoError(t, err)
+	require.Equal(t, uint64(2), docsRead)
+	require.Equal(t, 2, docsWritten)
+}
+
+func TestReindexData_ReindexModeShouldRunTask(t *testing.T) {
+	polls := []string{
+		`{"completed":false,"task":{"status":{"total":3,"created":1}}}`,
+		`{"completed":true,"task":{"status":{"total":3,"created":2}},"response":{"total":3,"created":2,"updated":1,"failures":[]}}`,
+	}
+	numPolls := 0
+	destinationClient := &mock.ElasticClientStub{
+		StartReindexTaskCalled: func(body []byte, slices string) (string, error) {
+			require.Equal(t, defaultReindexSlices, slices)
+			require.Equal(t, testIndex, gjson.GetBytes(body, "source.index").String())
+			require.Equal(t, "dest-"+testIndex, gjson.GetBytes(body, "dest.index").String())
+			require.Equal(t, int64(100), gjson.GetBytes(body, "source.size").Int())
+			require.True(t, gjson.GetBytes(body, "source.query.bool.filter").Exists())
+			require.False(t, gjson.GetBytes(body, "source.remote").Exists())
+			return "node:1", nil
+		},
+		GetTaskCalled: func(taskID string) ([]byte, error) {
+			require.Equal(t, "node:1", taskID)
+			numPolls++
+			return []byte(polls[numPolls-1]), nil
+		},
+	}
+	docsWritten := 0
+	metrics := &mock.MetricsHandlerStub{
+		ObserveBulkCalled: func(_ string, _ string, numDocs int, _ int, _ time.Duration) {
+			docsWritten += numDocs
+		},
+	}
+	checkpointDocs := uint64(0)
+	checkpointHandler := &mock.CheckpointHandlerStub{
+		UpdateIndexProgressCalled: func(_ string, bulk checkpoint.BulkInfo) error {
+			checkpointDocs += bulk.NumDocs
+			return nil
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	args.Metrics = metrics
+	args.Checkpoint = checkpointHandler
+	args.ReaderConfig = config.ReaderConfig{Mode: ReindexReadMode}
+	args.Overrides = map[string]config.IndexOverrideConfig{
+		testIndex: {Query: `{"term":{"shardID":1}}`, TargetPrefix: "dest-", PageSize: 100},
+	}
+	r, err := newReindexer(args)
+	require.NoError(t, err)
+	r.serverSide.pollInterval = time.Millisecond
+
+	err = r.reindexData(testIndex, newFanOut(r.destinations))
+	require.NoError(t, err)
+	require.Equal(t, 2, numPolls)
+	require.Equal(t, 3, docsWritten)
+	require.Equal(t, uint64(3), checkpointDocs)
+}
+
+func TestReindexData_ReindexModeFailuresShouldErr(t *testing.T) {
+	destinationClient := &mock.ElasticClientStub{
+		StartReindexTaskCalled: func(body []byte, slices string) (string, error) {
+			require.Equal(t, "", slices)
+			require.Equal(t, "http://source:9200", gjson.GetBytes(body, "source.remote.host").String())
+			return "node:1", nil
+		},
+		GetTaskCalled: func(_ string) ([]byte, error) {
+			return []byte(`{"completed":true,"response":{"total":1,"failures":[{"id":"a","cause":{"type":"mapper_parsing_exception"}}]}}`), nil
+		},
+	}
+
+	args := createMockArgsReindexer()
+	args.DestinationElastic = destinationClient
+	args.ReaderConfig = config.ReaderConfig{Mode: ReindexReadMode, ServerSide: config.ServerSideReindexConfig{Remote: true}}
+	args.SourceConfig = config.ElasticInstanceConfig{URL: "http://source:9200"}
+	r, err := newReindexer(args)
+	require.NoError(t, err)
+	r.serverSide.pollInterval = time.Millisecond
+
+	err = r.reindexData(testIndex, newFanOut(r.destinations))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "mapper_parsing_exception")
+}
+
+func TestNewReindexer_ReindexModeWithTransformsShouldErr(t *testing.T) {
+	args := createMockArgsReindexer()
+	args.ReaderConfig = config.ReaderConfig{Mode: ReindexReadMode}
+	args.Transformers = map[string]DocumentTransformer{testIndex: transform.NewChain()}
+	r, err := newReindexer(args)
+	require.Nil(t, r)
+	require.Error(t, err)
+
+	args = createMockArgsReindexer()
+	args.ReaderConfig = config.ReaderConfig{Mode: ReindexReadMode, ServerSide: config.ServerSideReindexConfig{Remote: true}}
+	r, err = newReindexer(args)
+	require.Nil(t, r)
+	require.Error(t, err)
+}
+
+func TestProcess_MultipleDestinationsShouldWriteInAll(t *testing.T) {
+	sourceClient := &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(_ string, _ []byte, handlerFunc func(responseBytes []byte) error) error {
+			return handlerFunc([]byte(`{"hits":{"hits":[{"_id":"a","_source":{"v":1}},{"_id":"b","_source":{"v":2}}]}}`))
+		},
+	}
+
+	mut := sync.Mutex{}
+	bulks := make(map[string]int)
+	completed := make(map[string]bool)
+	createDestination := func(name string, bulkErr error) (*mock.ElasticClientStub, *mock.CheckpointHandlerStub) {
+		client := &mock.ElasticClientStub{
+			DoBulkRequestCalled: func(buff *bytes.Buffer, _ string) error {
+				require.Contains(t, buff.String(), `"_id":"a"`)
+
+				mut.Lock()
+				bulks[name]++
+				mut.Unlock()
+				return bulkErr
+			},
+		}
+		checkpointHandler := &mock.CheckpointHandlerStub{
+			MarkIndexCompletedCalled: func(_ string) error {
+				mut.Lock()
+				completed[name] = true
+				mut.Unlock()
+				return nil
+			},
+		}
+
+		return client, checkpointHandler
+	}
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.DestinationElastic, args.Checkpoint = createDestination("main", nil)
+	args.DestinationName = "main"
+	replicaClient, replicaCheckpoint := createDestination("replica", nil)
+	analyticsClient, analyticsCheckpoint := createDestination("analytics", errors.New("cluster unavailable"))
+	args.AdditionalDestinations = []Destination{
+		{Name: "replica", Elastic: replicaClient, Checkpoint: replicaCheckpoint},
+		{Name: "analytics", Elastic: analyticsClient, Checkpoint: analyticsCheckpoint},
+	}
+	r, err := newReindexer(args)
+	require.NoError(t, err)
+
+	// the failed destination does not stop the copy in the other ones
+	err = r.Process(false, true, testIndex)
+	require.NoError(t, err)
+	require.Equal(t, map[string]int{"main": 1, "replica": 1, "analytics": 1}, bulks)
+	require.Equal(t, map[string]bool{"main": true, "replica": true}, completed)
+}
+
+func TestNewReindexer_DuplicatedDestinationNameShouldErr(t *testing.T) {
+	args := createMockArgsReindexer()
+	args.AdditionalDestinations = []Destination{
+		{Name: defaultDestinationName, Elastic: &mock.ElasticClientStub{}, Checkpoint: &mock.CheckpointHandlerStub{}},
+	}
+
+	r, err := newReindexer(args)
+	require.Nil(t, r)
+	require.Error(t, err)
+}
+
+func TestProcess_OverwriteShouldClearTheFileDestination(t *testing.T) {
+	t.Parallel()
+
+	fileClient, err := filestore.NewFileClient(config.FileConfig{Path: t.TempDir()})
+	require.NoError(t, err)
+	require.NoError(t, fileClient.DoBulkRequest(bytes.NewBufferString(`{"index":{"_id":"old"}}`+"\n"+`{"v":0}`+"\n"), testIndex))
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = &mock.ElasticClientStub{
+		DoScrollRequestAllDocumentsCalled: func(_ string, _ []byte, handlerFunc func(responseBytes []byte) error) error {
+			return handlerFunc([]byte(`{"hits":{"hits":[{"_id":"a","_source":{"v":1}},{"_id":"b","_source":{"v":2}}]}}`))
+		},
+	}
+	args.DestinationElastic = fileClient
+	r, err := newReindexer(args)
+	require.NoError(t, err)
+
+	err = r.Process(false, true, testIndex)
+	require.NoError(t, err)
+	count, _ := fileClient.GetCount(testIndex)
+	require.Equal(t, uint64(3), count)
+
+	err = r.Process(true, true, testIndex)
+	require.NoError(t, err)
+	count, _ = fileClient.GetCount(testIndex)
+	require.Equal(t, uint64(2), count)
+}
+
+func TestPrepareDataForIndexing_ShouldEscapeTheIDs(t *testing.T) {
+	t.Parallel()
+
+	esResponse := generalElasticResponse{}
+	esResponse.Hits.Hits = append(esResponse.Hits.Hits, struct {
+		ID     string          `json:"_id"`
+		Source json.RawMessage `json:"_source"`
+		Sort   json.RawMessage `json:"sort"`
+	}{ID: `a"b\c`, Source: json.RawMessage(`{"v":1}`)})
+
+	buffers, err := prepareDataForIndexing(esResponse, testIndex, 1, nil, 1000)
+	require.NoError(t, err)
+	require.Len(t, buffers, 1)
+
+	lines := bytes.Split(buffers[0].Bytes(), []byte("\n"))
+	require.Equal(t, `a"b\c`, gjson.GetBytes(lines[0], "index._id").String())
+	require.Equal(t, `{"v":1}`, string(lines[1]))
+}
+
+func TestCountSince(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, uint64(3), countSince(10, 7))
+	require.Equal(t, uint64(0), countSince(7, 7))
+	require.Equal(t, uint64(0), countSince(5, 7))
+}
+
+func TestProcessIndexWithTimestamp_BoundaryDocumentsShouldBeWrittenOnce(t *testing.T) {
+	t.Parallel()
+
+	sourceClient, err := filestore.NewFileClient(config.FileConfig{Path: t.TempDir()})
+	require.NoError(t, err)
+	bulk := &bytes.Buffer{}
+	for _, timestamp := range []int{10, 15, 20, 25, 30} {
+		bulk.WriteString(fmt.Sprintf(`{"index":{"_id":"doc-%d"}}`+"\n"+`{"timestamp":%d}`+"\n", timestamp, timestamp))
+	}
+	require.NoError(t, sourceClient.DoBulkRequest(bulk, testIndex))
+
+	destinationClient, err := filestore.NewFileClient(config.FileConfig{Path: t.TempDir()})
+	require.NoError(t, err)
+
+	args := createMockArgsReindexer()
+	args.SourceElastic = sourceClient
+	args.DestinationElastic = destinationClient
+	r, err := newReindexer(args)
+	require.NoError(t, err)
+
+	intervals, err := computeIntervals(10, 30, 2)
+	require.NoError(t, err)
+	for _, interv := range intervals {
+		count := uint64(0)
+		err = r.ProcessIndexWithTimestamp(testIndex, false, true, interv.start, interv.stop, &count)
+		require.NoError(t, err)
+	}
+
+	count, _ := destinationClient.GetCount(testIndex)
+	require.Equal(t, uint64(5), count)
+}
+
+func TestExtractBulkInfo(t *testing.T) {
+	t.Parallel()
+
+	esResponse := generalElasticResponse{}
+	require.Equal(t, checkpoint.BulkInfo{}, extractBulkInfo(esResponse))
+
+	for _, timestamp := range []int{10, 12, 12} {
+		esResponse.Hits.Hits = append(esResponse.Hits.Hits, struct {
+			ID     string          `json:"_id"`
+			Source json.RawMessage `json:"_source"`
+			Sort   json.RawMessage `json:"sort"`
+		}{Source: json.RawMessage(fmt.Sprintf(`{"timestamp":%d}`, timestamp))})
+	}
+	require.Equal(t, checkpoint.BulkInfo{NumDocs: 3}, extractBulkInfo(esResponse))
+
+	esResponse.Hits.Hits[2].Sort = json.RawMessage(`[12]`)
+	require.Equal(t, checkpoint.BulkInfo{NumDocs: 3, LastTimestamp: 12, NumDocsAtLastTimestamp: 2}, extractBulkInfo(esResponse))
+}
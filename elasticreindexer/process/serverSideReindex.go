@@ -0,0 +1,279 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/checkpoint"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/elastic"
+	"github.com/tidwall/gjson"
+)
+
+const (
+	defaultReindexSlices    = "auto"
+	defaultTaskPollInterval = 5 * time.Second
+	// maxReindexBatchSize is the default max_result_window of an index, the batches of a task being search requests
+	maxReindexBatchSize    = 10000
+	numOfFailuresToExtract = 5
+)
+
+type serverSideReindexer struct {
+	client       ServerSideReindexHandler
+	slices       string
+	pollInterval time.Duration
+	// remote holds the connection details of the input cluster, or nil if the input and output are the same cluster
+	remote object
+}
+
+type reindexTaskStatus struct {
+	Total            uint64 `json:"total"`
+	Created          uint64 `json:"created"`
+	Updated          uint64 `json:"updated"`
+	Deleted          uint64 `json:"deleted"`
+	Noops            uint64 `json:"noops"`
+	VersionConflicts uint64 `json:"version_conflicts"`
+}
+
+type reindexTaskResponse struct {
+	Completed bool `json:"completed"`
+	Task      struct {
+		Status reindexTaskStatus `json:"status"`
+	} `json:"task"`
+	Response *struct {
+		reindexTaskStatus
+		Failures []json.RawMessage `json:"failures"`
+	} `json:"response"`
+	Error json.RawMessage `json:"error"`
+}
+
+func (rts reindexTaskStatus) processed() uint64 {
+	return rts.Created + rts.Updated + rts.Deleted + rts.Noops + rts.VersionConflicts
+}
+
+func (rts reindexTaskStatus) written() uint64 {
+	return rts.Created + rts.Updated
+}
+
+func newServerSideReindexer(args ArgsReindexer) (*serverSideReindexer, error) {
+	client, ok := args.DestinationElastic.(ServerSideReindexHandler)
+	if !ok {
+		return nil, fmt.Errorf("the %s mode needs an Elasticsearch output", ReindexReadMode)
+	}
+	if len(args.AdditionalDestinations) > 0 {
+		return nil, fmt.Errorf("the %s mode can copy the documents in a single output", ReindexReadMode)
+	}
+	for index, transformer := range args.Transformers {
+		if transformer != nil {
+			return nil, fmt.Errorf("the transforms of index %s cannot be applied in the %s mode, as the documents are copied by the cluster", index, ReindexReadMode)
+		}
+	}
+
+	cfg := args.ReaderConfig.ServerSide
+	slices := cfg.Slices
+	if slices == "" {
+		slices = defaultReindexSlices
+	}
+	pollInterval := time.Duration(cfg.PollIntervalInSeconds) * time.Second
+	if pollInterval <= 0 {
+		pollInterval = defaultTaskPollInterval
+	}
+
+	var remote object
+	if cfg.Remote {
+		var err error
+		remote, err = createRemoteSource(args.SourceConfig)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &serverSideReindexer{
+		client:       client,
+		slices:       slices,
+		pollInterval: pollInterval,
+		remote:       remote,
+	}, nil
+}
+
+// createRemoteSource returns the remote section of a _reindex request. The API key and the bearer token of the input
+// cluster are sent as the Authorization header, while its TLS settings have to be configured in the output cluster
+func createRemoteSource(cfg config.ElasticInstanceConfig) (object, error) {
+	if cfg.URL == "" {
+		return nil, errors.New("the remote reindex needs the url of the input cluster")
+	}
+
+	username, err := elastic.ResolveSecret(cfg.Username)
+	if err != nil {
+		return nil, fmt.Errorf("%w for the username of the input cluster", err)
+	}
+	password, err := elastic.ResolveSecret(cfg.Password)
+	if err != nil {
+		return nil, fmt.Errorf("%w for the password of the input cluster", err)
+	}
+	apiKey, err := elastic.ResolveSecret(cfg.APIKey)
+	if err != nil {
+		return nil, fmt.Errorf("%w for the api key of the input cluster", err)
+	}
+	bearerToken, err := elastic.ResolveSecret(cfg.BearerToken)
+	if err != nil {
+		return nil, fmt.Errorf("%w for the bearer token of the input cluster", err)
+	}
+
+	remote := object{
+		"host": cfg.URL,
+	}
+	switch {
+	case username != "":
+		remote["username"] = username
+		remote["password"] = password
+	case apiKey != "":
+		remote["headers"] = object{"Authorization": "ApiKey " + apiKey}
+	case bearerToken != "":
+		remote["headers"] = object{"Authorization": "Bearer " + bearerToken}
+	}
+
+	return remote, nil
+}
+
+// runReindexTask copies the documents of the provided index that match the provided query with a _reindex task of the
+// output cluster. The task is polled until it completes, its progress being reported as the bulks of the client-side
+// copy: in the metrics and through the provided handler
+func (r *reindexer) runReindexTask(index string, query *bytes.Buffer, interval string, onProgress func(bulkInfo checkpoint.BulkInfo) error) error {
+	body, err := r.createReindexBody(index, query)
+	if err != nil {
+		return err
+	}
+
+	// the remote reindex cannot be sliced
+	slices := r.serverSide.slices
+	if r.numSlices[index] >= 2 {
+		slices = strconv.Itoa(r.numSlices[index])
+	}
+	if r.serverSide.remote != nil {
+		slices = ""
+	}
+
+	taskID, err := r.serverSide.client.StartReindexTask(body, slices)
+	if err != nil {
+		return fmt.Errorf("%w while starting the reindex task", err)
+	}
+	log.Info("started reindex task", "index", index, "interval", interval, "task", taskID, "slices", slices)
+
+	previous := reindexTaskStatus{}
+	lastPoll := time.Now()
+	for {
+		time.Sleep(r.serverSide.pollInterval)
+
+		response, errGet := r.getReindexTask(taskID)
+		if errGet != nil {
+			return fmt.Errorf("%w while getting the reindex task %s", errGet, taskID)
+		}
+
+		status := response.Task.Status
+		if response.Completed && response.Response != nil {
+			status = response.Response.reindexTaskStatus
+		}
+
+		// the batches done by the task since the previous poll are reported as a single bulk
+		numRead := countSince(status.processed(), previous.processed())
+		numWritten := countSince(status.written(), previous.written())
+		r.metrics.AddDocumentsRead(index, interval, numRead)
+		r.metrics.ObserveBulk(index, interval, int(numWritten), 0, time.Since(lastPoll))
+		lastPoll = time.Now()
+		previous = status
+
+		err = onProgress(checkpoint.BulkInfo{NumDocs: numWritten})
+		if err != nil {
+			return err
+		}
+
+		log.Info("\treindex task progress", "index", index, "interval", interval, "task", taskID,
+			"processed", status.processed(), "total", status.Total, "written", status.written())
+
+		if response.Completed {
+			return extractReindexTaskError(response)
+		}
+	}
+}
+
+// countSince returns the increase of a task counter since the previous poll. The status of a running task and the
+// response of the completed one are reported separately, so a counter lower than at the previous poll is counted as no
+// progress instead of wrapping around
+func countSince(current uint64, previous uint64) uint64 {
+	if current < previous {
+		return 0
+	}
+
+	return current - previous
+}
+
+func (r *reindexer) createReindexBody(index string, query *bytes.Buffer) ([]byte, error) {
+	batchSize := r.overrides.pageSize(index)
+	if batchSize == 0 {
+		batchSize = r.pageSize
+	}
+	if batchSize > maxReindexBatchSize {
+		batchSize = maxReindexBatchSize
+	}
+
+	source := object{
+		"index": index,
+		"size":  batchSize,
+	}
+	queryClause := gjson.GetBytes(query.Bytes(), "query")
+	if queryClause.Exists() {
+		source["query"] = json.RawMessage(queryClause.Raw)
+	}
+	if r.serverSide.remote != nil {
+		source["remote"] = r.serverSide.remote
+	}
+
+	return json.Marshal(object{
+		"source": source,
+		"dest": object{
+			"index": r.overrides.targetIndex(index),
+		},
+	})
+}
+
+func (r *reindexer) getReindexTask(taskID string) (*reindexTaskResponse, error) {
+	responseBytes, err := r.serverSide.client.GetTask(taskID)
+	if err != nil {
+		return nil, err
+	}
+
+	response := &reindexTaskResponse{}
+	err = json.Unmarshal(responseBytes, response)
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func extractReindexTaskError(response *reindexTaskResponse) error {
+	if len(response.Error) > 0 && string(response.Error) != "null" {
+		return fmt.Errorf("the reindex task failed: %s", response.Error)
+	}
+	if response.Response == nil || len(response.Response.Failures) == 0 {
+		return nil
+	}
+
+	failures := response.Response.Failures
+	numFailures := len(failures)
+	if len(failures) > numOfFailuresToExtract {
+		failures = failures[:numOfFailuresToExtract]
+	}
+	failuresString := make([]string, 0, len(failures))
+	for _, failure := range failures {
+		failuresString = append(failuresString, string(failure))
+	}
+
+	return fmt.Errorf("the reindex task has %d failures: %s", numFailures, strings.Join(failuresString, "\n"))
+}
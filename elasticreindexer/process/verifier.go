@@ -0,0 +1,451 @@
+package process
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/multiversx/mx-chain-core-go/core/check"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+)
+
+const (
+	defaultNumVerifyIntervals = 100
+	defaultSampleSize         = 1000
+	bucketsPageSize           = 1000
+)
+
+// documentsFilterer defines the behaviour of a transformation chain that can tell if it filters out documents
+type documentsFilterer interface {
+	FiltersDocuments() bool
+}
+
+type verifier struct {
+	sourceElastic        ElasticClientHandler
+	destinationElastic   ElasticClientHandler
+	reindexerClient      ReindexerHandler
+	indicesNoTimestamp   []string
+	indicesWithTimestamp []string
+	blockChainStartTime  int64
+	numIntervals         int
+	sampleSize           int
+	bucketFields         map[string]string
+	overrides            indexOverrides
+	transformers         map[string]DocumentTransformer
+}
+
+// NewVerifier will create a new instance of a component that compares the data from the source and the destination clusters
+func NewVerifier(
+	sourceElastic ElasticClientHandler,
+	destinationElastic ElasticClientHandler,
+	reindexer ReindexerHandler,
+	transformers map[string]DocumentTransformer,
+	cfg config.IndexersConfig,
+) (*verifier, error) {
+	if check.IfNil(sourceElastic) {
+		return nil, fmt.Errorf("%w for source", errNilElasticHandler)
+	}
+	if check.IfNil(destinationElastic) {
+		return nil, fmt.Errorf("%w for destination", errNilElasticHandler)
+	}
+	if reindexer == nil {
+		return nil, errors.New("nil ReindexerHandler")
+	}
+
+	numIntervals := cfg.Verify.NumIntervals
+	if numIntervals <= 0 {
+		numIntervals = defaultNumVerifyIntervals
+	}
+	sampleSize := cfg.Verify.SampleSize
+	if sampleSize <= 0 {
+		sampleSize = defaultSampleSize
+	}
+
+	overrides, err := newIndexOverrides(cfg.IndicesConfig.Overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	indicesWithTimestamp := cfg.IndicesConfig.WithTimestamp.IndicesWithTimestamp
+	if !cfg.IndicesConfig.WithTimestamp.Enabled {
+		indicesWithTimestamp = nil
+	}
+
+	return &verifier{
+		sourceElastic:        sourceElastic,
+		destinationElastic:   destinationElastic,
+		reindexerClient:      reindexer,
+		indicesNoTimestamp:   cfg.IndicesConfig.Indices,
+		indicesWithTimestamp: indicesWithTimestamp,
+		blockChainStartTime:  cfg.IndicesConfig.WithTimestamp.BlockchainStartTime,
+		numIntervals:         numIntervals,
+		sampleSize:           sampleSize,
+		bucketFields:         cfg.Verify.BucketFields,
+		overrides:            overrides,
+		transformers:         transformers,
+	}, nil
+}
+
+// Verify will compare the counts and a sample of documents of every configured index and will return the report
+func (v *verifier) Verify() (*VerifyReport, error) {
+	report := &VerifyReport{
+		Passed: true,
+	}
+
+	for _, index := range v.indicesNoTimestamp {
+		if index == "" {
+			continue
+		}
+
+		indexReport, err := v.verifyIndex(index, false)
+		if err != nil {
+			return nil, err
+		}
+		report.addIndex(indexReport)
+	}
+
+	for _, index := range v.indicesWithTimestamp {
+		if index == "" {
+			continue
+		}
+
+		indexReport, err := v.verifyIndex(index, true)
+		if err != nil {
+			return nil, err
+		}
+		report.addIndex(indexReport)
+	}
+
+	return report, nil
+}
+
+func (v *verifier) verifyIndex(index string, withTimestamp bool) (*IndexReport, error) {
+	log.Info("verifying index", "index", index)
+
+	sourceCount, err := v.getSourceCount(index)
+	if err != nil {
+		return nil, fmt.Errorf("%w while getting the source count for index %s", err, index)
+	}
+	targetIndex := v.overrides.targetIndex(index)
+	destinationCount, err := v.destinationElastic.GetCount(targetIndex)
+	if err != nil {
+		return nil, fmt.Errorf("%w while getting the destination count for index %s", err, targetIndex)
+	}
+
+	// the source counts cannot tell how many documents are filtered out by the transformation chain, so only the sample
+	// of a filtered index is compared
+	filterer, ok := v.transformers[index].(documentsFilterer)
+	skipCountCheck := ok && filterer.FiltersDocuments()
+	indexReport := &IndexReport{
+		Index:             index,
+		SourceCount:       sourceCount,
+		DestinationCount:  destinationCount,
+		Passed:            skipCountCheck || sourceCount == destinationCount,
+		SkippedCountCheck: skipCountCheck,
+	}
+
+	switch {
+	case skipCountCheck:
+		log.Warn("the counts are not compared, the documents of the index are filtered", "index", index)
+	case withTimestamp:
+		err = v.verifyIntervals(indexReport)
+	default:
+		err = v.verifyBuckets(indexReport)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = v.verifySample(indexReport)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Info("verified index", "index", index, "passed", indexReport.Passed,
+		"source count", sourceCount, "destination count", destinationCount)
+
+	return indexReport, nil
+}
+
+func (v *verifier) getSourceCount(index string) (uint64, error) {
+	filter := v.overrides.queryFilter(index)
+	if len(filter) == 0 {
+		return v.sourceElastic.GetCount(index)
+	}
+
+	return v.sourceElastic.GetCountWithBody(index, withQueryFilter(getAll(), filter).Bytes())
+}
+
+func (v *verifier) verifyIntervals(indexReport *IndexReport) error {
+	start, end := v.overrides.timeRange(indexReport.Index, v.blockChainStartTime, time.Now().Unix())
+	intervals, err := computeIntervals(start, end, int64(v.numIntervals))
+	if err != nil {
+		return err
+	}
+
+	for _, interv := range intervals {
+		countSource, countDestination, errCount := v.getCountsForInterval(indexReport.Index, interv.start, interv.stop)
+		if errCount != nil {
+			return fmt.Errorf("%w while getting the counts for index %s, interval %d-%d", errCount, indexReport.Index, interv.start, interv.stop)
+		}
+
+		if countSource == countDestination {
+			continue
+		}
+
+		indexReport.Passed = false
+		indexReport.FailedIntervals = append(indexReport.FailedIntervals, &IntervalReport{
+			Start:            interv.start,
+			Stop:             interv.stop,
+			SourceCount:      countSource,
+			DestinationCount: countDestination,
+		})
+	}
+
+	return nil
+}
+
+// getCountsForInterval returns the source count of the provided interval, with the query override of the index applied,
+// and the count of the verified destination
+func (v *verifier) getCountsForInterval(index string, start, stop int64) (uint64, uint64, error) {
+	countSource, err := v.reindexerClient.GetSourceCountForInterval(index, start, stop)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	body := getWithTimestamp(start, stop, false, false)
+	countDestination, err := v.destinationElastic.GetCountWithBody(v.overrides.targetIndex(index), body.Bytes())
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return countSource, countDestination, nil
+}
+
+func (v *verifier) verifyBuckets(indexReport *IndexReport) error {
+	field, found := v.bucketFields[indexReport.Index]
+	if !found {
+		return nil
+	}
+
+	sourceBuckets, err := getBuckets(v.sourceElastic, indexReport.Index, field, v.overrides.queryFilter(indexReport.Index))
+	if err != nil {
+		return fmt.Errorf("%w while getting the source buckets for index %s", err, indexReport.Index)
+	}
+	destinationBuckets, err := getBuckets(v.destinationElastic, v.overrides.targetIndex(indexReport.Index), field, nil)
+	if err != nil {
+		return fmt.Errorf("%w while getting the destination buckets for index %s", err, indexReport.Index)
+	}
+
+	for key, countSource := range sourceBuckets {
+		countDestination := destinationBuckets[key]
+		if countSource == countDestination {
+			continue
+		}
+
+		indexReport.addFailedBucket(field, key, countSource, countDestination)
+	}
+	for key, countDestination := range destinationBuckets {
+		_, found = sourceBuckets[key]
+		if found {
+			continue
+		}
+
+		indexReport.addFailedBucket(field, key, 0, countDestination)
+	}
+
+	return nil
+}
+
+type compositeAggregationResponse struct {
+	Aggregations struct {
+		Buckets struct {
+			AfterKey json.RawMessage `json:"after_key"`
+			Buckets  []struct {
+				Key struct {
+					Key interface{} `json:"key"`
+				} `json:"key"`
+				DocCount uint64 `json:"doc_count"`
+			} `json:"buckets"`
+		} `json:"buckets"`
+	} `json:"aggregations"`
+}
+
+// getBuckets returns the number of documents for every value of the provided field, reading all the pages of a
+// composite aggregation
+func getBuckets(client ElasticClientHandler, index string, field string, filter json.RawMessage) (map[string]uint64, error) {
+	buckets := make(map[string]uint64)
+	var afterKey json.RawMessage
+	for {
+		query := withQueryFilter(getCompositeAggregation(field, bucketsPageSize, afterKey), filter)
+		responseBytes, err := client.DoSearchRequest(index, query.Bytes())
+		if err != nil {
+			return nil, err
+		}
+
+		response := &compositeAggregationResponse{}
+		err = json.Unmarshal(responseBytes, response)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, bucket := range response.Aggregations.Buckets.Buckets {
+			buckets[fmt.Sprintf("%v", bucket.Key.Key)] = bucket.DocCount
+		}
+		if len(response.Aggregations.Buckets.Buckets) == 0 || len(response.Aggregations.Buckets.AfterKey) == 0 {
+			return buckets, nil
+		}
+
+		afterKey = response.Aggregations.Buckets.AfterKey
+	}
+}
+
+// verifySample picks random documents from both clusters and checks that they exist, with the same content, in the other one.
+// The source documents are passed through the transformation chain of the index before being compared. The destination
+// documents of a transformed index cannot be traced back to the source ones, so they are not looked up in the source
+func (v *verifier) verifySample(indexReport *IndexReport) error {
+	sample := &SampleReport{}
+
+	targetIndex := v.overrides.targetIndex(indexReport.Index)
+	sourceDocuments, err := getRandomDocuments(v.sourceElastic, indexReport.Index, v.sampleSize, v.overrides.queryFilter(indexReport.Index))
+	if err != nil {
+		return fmt.Errorf("%w while getting a source sample for index %s", err, indexReport.Index)
+	}
+	transformer := v.transformers[indexReport.Index]
+	isTransformed := !check.IfNil(transformer)
+	if isTransformed {
+		sourceDocuments, err = transformDocuments(transformer, sourceDocuments)
+		if err != nil {
+			return fmt.Errorf("%w while transforming the source sample for index %s", err, indexReport.Index)
+		}
+	}
+	sourceHashes := hashDocuments(sourceDocuments)
+	destinationHashes, err := getDocumentsHashesByIDs(v.destinationElastic, targetIndex, sourceHashes)
+	if err != nil {
+		return fmt.Errorf("%w while getting the destination sample for index %s", err, indexReport.Index)
+	}
+	for id, sourceHash := range sourceHashes {
+		sample.NumChecked++
+
+		destinationHash, found := destinationHashes[id]
+		if !found {
+			sample.MissingIDs = append(sample.MissingIDs, id)
+			continue
+		}
+		if destinationHash != sourceHash {
+			sample.DifferentIDs = append(sample.DifferentIDs, id)
+		}
+	}
+
+	if isTransformed {
+		sample.SkippedExtraCheck = true
+	} else {
+		err = v.verifyDestinationSample(indexReport.Index, targetIndex, sample)
+		if err != nil {
+			return err
+		}
+	}
+
+	indexReport.Sample = sample
+	if len(sample.MissingIDs)+len(sample.ExtraIDs)+len(sample.DifferentIDs) > 0 {
+		indexReport.Passed = false
+	}
+
+	return nil
+}
+
+// verifyDestinationSample picks random documents from the destination and checks that they exist in the source
+func (v *verifier) verifyDestinationSample(index string, targetIndex string, sample *SampleReport) error {
+	destinationDocuments, err := getRandomDocuments(v.destinationElastic, targetIndex, v.sampleSize, nil)
+	if err != nil {
+		return fmt.Errorf("%w while getting a destination sample for index %s", err, index)
+	}
+	destinationHashes := hashDocuments(destinationDocuments)
+	sourceHashes, err := getDocumentsHashesByIDs(v.sourceElastic, index, destinationHashes)
+	if err != nil {
+		return fmt.Errorf("%w while getting the source sample for index %s", err, index)
+	}
+	for id := range destinationHashes {
+		sample.NumChecked++
+
+		_, found := sourceHashes[id]
+		if !found {
+			sample.ExtraIDs = append(sample.ExtraIDs, id)
+		}
+	}
+
+	return nil
+}
+
+func getRandomDocuments(client ElasticClientHandler, index string, size int, filter json.RawMessage) (map[string]json.RawMessage, error) {
+	responseBytes, err := client.DoSearchRequest(index, getRandomSample(size, filter).Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	return extractDocuments(responseBytes)
+}
+
+// transformDocuments passes the provided documents through the transformation chain, the filtered out ones being dropped
+func transformDocuments(transformer DocumentTransformer, documents map[string]json.RawMessage) (map[string]json.RawMessage, error) {
+	transformed := make(map[string]json.RawMessage, len(documents))
+	for id, source := range documents {
+		newID, newSource, keep, err := transformer.TransformDocument(id, source)
+		if err != nil {
+			return nil, err
+		}
+		if !keep {
+			continue
+		}
+
+		transformed[newID] = newSource
+	}
+
+	return transformed, nil
+}
+
+func getDocumentsHashesByIDs(client ElasticClientHandler, index string, documents map[string]string) (map[string]string, error) {
+	if len(documents) == 0 {
+		return make(map[string]string), nil
+	}
+
+	ids := make([]string, 0, len(documents))
+	for id := range documents {
+		ids = append(ids, id)
+	}
+
+	responseBytes, err := client.DoSearchRequest(index, getByIDs(ids).Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	foundDocuments, err := extractDocuments(responseBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return hashDocuments(foundDocuments), nil
+}
+
+func extractDocuments(responseBytes []byte) (map[string]json.RawMessage, error) {
+	var esResponse generalElasticResponse
+	err := json.Unmarshal(responseBytes, &esResponse)
+	if err != nil {
+		return nil, err
+	}
+
+	return extractSourceFromEsResponse(esResponse), nil
+}
+
+func hashDocuments(documents map[string]json.RawMessage) map[string]string {
+	hashes := make(map[string]string, len(documents))
+	for id, source := range documents {
+		hash := sha256.Sum256(source)
+		hashes[id] = hex.EncodeToString(hash[:])
+	}
+
+	return hashes
+}
@@ -0,0 +1,203 @@
+package process
+
+import (
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/process/mock"
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/transform"
+	"github.com/stretchr/testify/require"
+)
+
+func createVerifyConfig() config.IndexersConfig {
+	cfg := config.IndexersConfig{}
+	cfg.IndicesConfig.Indices = []string{"accounts"}
+	cfg.IndicesConfig.WithTimestamp.Enabled = true
+	cfg.IndicesConfig.WithTimestamp.BlockchainStartTime = 1
+	cfg.IndicesConfig.WithTimestamp.IndicesWithTimestamp = []string{"blocks"}
+	cfg.Verify.NumIntervals = 2
+	cfg.Verify.BucketFields = map[string]string{"accounts": "shardID"}
+
+	return cfg
+}
+
+func TestVerifier_Verify(t *testing.T) {
+	sourceClient := &mock.ElasticClientStub{
+		DoSearchRequestCalled: func(_ string, body []byte) ([]byte, error) {
+			switch {
+			case strings.Contains(string(body), `"after":{"key":1}`):
+				return []byte(`{"aggregations":{"buckets":{"buckets":[]}}}`), nil
+			case strings.Contains(string(body), `"after":{"key":0}`):
+				return []byte(`{"aggregations":{"buckets":{"after_key":{"key":1},"buckets":[{"key":{"key":1},"doc_count":3}]}}}`), nil
+			case strings.Contains(string(body), "aggs"):
+				return []byte(`{"aggregations":{"buckets":{"after_key":{"key":0},"buckets":[{"key":{"key":0},"doc_count":5}]}}}`), nil
+			case strings.Contains(string(body), "random_score"):
+				return []byte(`{"hits":{"hits":[{"_id":"a","_source":{"v":1}},{"_id":"b","_source":{"v":2}},{"_id":"c","_source":{"v":3}}]}}`), nil
+			default:
+				return []byte(`{"hits":{"hits":[{"_id":"d","_source":{"v":4}}]}}`), nil
+			}
+		},
+		GetCountCalled: func(_ string) (uint64, error) {
+			return 8, nil
+		},
+	}
+	destinationClient := &mock.ElasticClientStub{
+		DoSearchRequestCalled: func(_ string, body []byte) ([]byte, error) {
+			switch {
+			case strings.Contains(string(body), `"after":{"key":1}`):
+				return []byte(`{"aggregations":{"buckets":{"buckets":[]}}}`), nil
+			case strings.Contains(string(body), "aggs"):
+				return []byte(`{"aggregations":{"buckets":{"after_key":{"key":1},"buckets":[{"key":{"key":0},"doc_count":5},{"key":{"key":1},"doc_count":2}]}}}`), nil
+			case strings.Contains(string(body), "random_score"):
+				return []byte(`{"hits":{"hits":[{"_id":"d","_source":{"v":4}},{"_id":"e","_source":{"v":5}}]}}`), nil
+			default:
+				return []byte(`{"hits":{"hits":[{"_id":"a","_source":{"v":1}},{"_id":"b","_source":{"v":20}}]}}`), nil
+			}
+		},
+		GetCountCalled: func(_ string) (uint64, error) {
+			return 7, nil
+		},
+		GetCountWithBodyCalled: func(_ string, body []byte) (uint64, error) {
+			if strings.Contains(string(body), `"gte":"1"`) {
+				return 10, nil
+			}
+			return 9, nil
+		},
+	}
+	reindexer := &mock.ReindexerHandlerStub{
+		GetSourceCountForIntervalCalled: func(_ string, _, _ int64) (uint64, error) {
+			return 10, nil
+		},
+	}
+
+	v, err := NewVerifier(sourceClient, destinationClient, reindexer, nil, createVerifyConfig())
+	require.NoError(t, err)
+
+	report, err := v.Verify()
+	require.NoError(t, err)
+	require.False(t, report.Passed)
+	require.Len(t, report.Indices, 2)
+
+	accountsReport := report.Indices[0]
+	require.Equal(t, "accounts", accountsReport.Index)
+	require.False(t, accountsReport.Passed)
+	require.Equal(t, []*BucketReport{{Field: "shardID", Key: "1", SourceCount: 3, DestinationCount: 2}}, accountsReport.FailedBuckets)
+	require.Equal(t, []string{"c"}, accountsReport.Sample.MissingIDs)
+	require.Equal(t, []string{"b"}, accountsReport.Sample.DifferentIDs)
+	require.Equal(t, []string{"e"}, accountsReport.Sample.ExtraIDs)
+
+	blocksReport := report.Indices[1]
+	require.Len(t, blocksReport.FailedIntervals, 1)
+	require.NotEqual(t, int64(1), blocksReport.FailedIntervals[0].Start)
+	require.Nil(t, blocksReport.FailedBuckets)
+
+	dir := t.TempDir()
+	require.NoError(t, report.SaveToFile(filepath.Join(dir, "report.json")))
+	require.NoError(t, report.SaveToFile(filepath.Join(dir, "report.html")))
+}
+
+func TestVerifier_VerifyShouldTransformTheSourceSample(t *testing.T) {
+	t.Parallel()
+
+	sourceClient := &mock.ElasticClientStub{
+		DoSearchRequestCalled: func(_ string, _ []byte) ([]byte, error) {
+			return []byte(`{"hits":{"hits":[{"_id":"a","_source":{"v":1,"shard":0}},{"_id":"b","_source":{"v":2,"shard":1}},{"_id":"c","_source":{"v":3,"shard":1}}]}}`), nil
+		},
+	}
+	requestedIDs := ""
+	destinationClient := &mock.ElasticClientStub{
+		DoSearchRequestCalled: func(_ string, body []byte) ([]byte, error) {
+			requestedIDs = string(body)
+			return []byte(`{"hits":{"hits":[{"_id":"b-1","_source":{"shard":1}},{"_id":"c-1","_source":{"shard":2}}]}}`), nil
+		},
+	}
+	chains, err := transform.CreateChains(map[string][]config.TransformConfig{
+		"accounts": {
+			{Type: transform.FilterType, Field: "shard", Value: 1},
+			{Type: transform.RemapIDType, Template: "{_id}-{shard}"},
+			{Type: transform.DropFieldType, Field: "v"},
+		},
+	}, nil)
+	require.NoError(t, err)
+
+	cfg := config.IndexersConfig{}
+	cfg.IndicesConfig.Indices = []string{"accounts"}
+	v, err := NewVerifier(sourceClient, destinationClient, &mock.ReindexerHandlerStub{}, map[string]DocumentTransformer{"accounts": chains["accounts"]}, cfg)
+	require.NoError(t, err)
+
+	report, err := v.Verify()
+	require.NoError(t, err)
+	require.NotContains(t, requestedIDs, "random_score")
+	require.Contains(t, requestedIDs, "b-1")
+
+	sample := report.Indices[0].Sample
+	require.Equal(t, 2, sample.NumChecked)
+	require.Nil(t, sample.MissingIDs)
+	require.Equal(t, []string{"c-1"}, sample.DifferentIDs)
+	require.True(t, sample.SkippedExtraCheck)
+}
+
+func TestVerifier_VerifyFilteredIndexShouldSkipTheCounts(t *testing.T) {
+	t.Parallel()
+
+	emptySample := []byte(`{"hits":{"hits":[]}}`)
+	sourceClient := &mock.ElasticClientStub{
+		DoSearchRequestCalled: func(_ string, _ []byte) ([]byte, error) {
+			return emptySample, nil
+		},
+		GetCountCalled: func(_ string) (uint64, error) {
+			return 10, nil
+		},
+	}
+	destinationClient := &mock.ElasticClientStub{
+		DoSearchRequestCalled: func(_ string, _ []byte) ([]byte, error) {
+			return emptySample, nil
+		},
+		GetCountCalled: func(_ string) (uint64, error) {
+			return 4, nil
+		},
+		GetCountWithBodyCalled: func(_ string, _ []byte) (uint64, error) {
+			return 2, nil
+		},
+	}
+	reindexer := &mock.ReindexerHandlerStub{
+		GetSourceCountForIntervalCalled: func(_ string, _, _ int64) (uint64, error) {
+			return 5, nil
+		},
+	}
+	chains, err := transform.CreateChains(map[string][]config.TransformConfig{
+		"blocks": {{Type: transform.FilterType, Field: "shardID", Value: 1}},
+	}, nil)
+	require.NoError(t, err)
+
+	cfg := createVerifyConfig()
+	cfg.IndicesConfig.Indices = nil
+	v, err := NewVerifier(sourceClient, destinationClient, reindexer, map[string]DocumentTransformer{"blocks": chains["blocks"]}, cfg)
+	require.NoError(t, err)
+
+	report, err := v.Verify()
+	require.NoError(t, err)
+	require.True(t, report.Passed)
+	require.True(t, report.Indices[0].SkippedCountCheck)
+	require.Nil(t, report.Indices[0].FailedIntervals)
+}
+
+func TestCreateVerifier_FileInstanceShouldErr(t *testing.T) {
+	t.Parallel()
+
+	cfg := &config.GeneralConfig{}
+	cfg.Indexers.Input = config.ElasticInstanceConfig{URL: "http://localhost:9200"}
+	output := config.ElasticInstanceConfig{Name: "backup", Type: FileInstanceType, File: config.FileConfig{Path: t.TempDir()}}
+	v, err := CreateVerifier(cfg, output, &mock.ReindexerHandlerStub{}, nil)
+	require.Nil(t, v)
+	require.True(t, errors.Is(err, errVerifyFileInstance))
+	require.Contains(t, err.Error(), "backup")
+
+	cfg.Indexers.Input = config.ElasticInstanceConfig{Type: FileInstanceType, File: config.FileConfig{Path: t.TempDir()}}
+	v, err = CreateVerifier(cfg, config.ElasticInstanceConfig{URL: "http://localhost:9200"}, &mock.ReindexerHandlerStub{}, nil)
+	require.Nil(t, v)
+	require.True(t, errors.Is(err, errVerifyFileInstance))
+}
@@ -0,0 +1,127 @@
+package process
+
+import (
+	"bytes"
+	"encoding/json"
+	"html/template"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// VerifyReport holds the result of the verification of all the indices
+type VerifyReport struct {
+	Passed  bool           `json:"passed"`
+	Indices []*IndexReport `json:"indices"`
+}
+
+// IndexReport holds the result of the verification of an index. SkippedCountCheck is set for the indices whose
+// transformation chain filters out documents, whose total, interval and bucket counts are not compared
+type IndexReport struct {
+	Index             string            `json:"index"`
+	Passed            bool              `json:"passed"`
+	SourceCount       uint64            `json:"sourceCount"`
+	DestinationCount  uint64            `json:"destinationCount"`
+	SkippedCountCheck bool              `json:"skippedCountCheck,omitempty"`
+	FailedIntervals   []*IntervalReport `json:"failedIntervals,omitempty"`
+	FailedBuckets     []*BucketReport   `json:"failedBuckets,omitempty"`
+	Sample            *SampleReport     `json:"sample,omitempty"`
+}
+
+// IntervalReport holds the counts of a timestamp interval whose source and destination counts are different
+type IntervalReport struct {
+	Start            int64  `json:"start"`
+	Stop             int64  `json:"stop"`
+	SourceCount      uint64 `json:"sourceCount"`
+	DestinationCount uint64 `json:"destinationCount"`
+}
+
+// BucketReport holds the counts of a keyword bucket whose source and destination counts are different
+type BucketReport struct {
+	Field            string `json:"field"`
+	Key              string `json:"key"`
+	SourceCount      uint64 `json:"sourceCount"`
+	DestinationCount uint64 `json:"destinationCount"`
+}
+
+// SampleReport holds the result of comparing a sample of documents by _id and content hash. SkippedExtraCheck is set
+// for the transformed indices, whose destination documents are not looked up in the source
+type SampleReport struct {
+	NumChecked        int      `json:"numChecked"`
+	MissingIDs        []string `json:"missingIDs,omitempty"`
+	ExtraIDs          []string `json:"extraIDs,omitempty"`
+	DifferentIDs      []string `json:"differentIDs,omitempty"`
+	SkippedExtraCheck bool     `json:"skippedExtraCheck,omitempty"`
+}
+
+func (vr *VerifyReport) addIndex(indexReport *IndexReport) {
+	vr.Indices = append(vr.Indices, indexReport)
+	vr.Passed = vr.Passed && indexReport.Passed
+}
+
+func (ir *IndexReport) addFailedBucket(field string, key string, countSource uint64, countDestination uint64) {
+	ir.Passed = false
+	ir.FailedBuckets = append(ir.FailedBuckets, &BucketReport{
+		Field:            field,
+		Key:              key,
+		SourceCount:      countSource,
+		DestinationCount: countDestination,
+	})
+}
+
+// SaveToFile will write the report in the provided file. The report is written as HTML if the file has the .html
+// extension, or as JSON otherwise
+func (vr *VerifyReport) SaveToFile(filePath string) error {
+	var reportBytes []byte
+	var err error
+	if strings.EqualFold(filepath.Ext(filePath), ".html") {
+		reportBytes, err = vr.toHTML()
+	} else {
+		reportBytes, err = json.MarshalIndent(vr, "", "  ")
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(filePath, reportBytes, 0644)
+}
+
+var htmlReportTemplate = template.Must(template.New("report").Parse(`<!DOCTYPE html>
+<html>
+<head><meta charset="utf-8"><title>Elasticsearch reindexing verification</title></head>
+<body>
+<h1>Verification {{if .Passed}}passed{{else}}failed{{end}}</h1>
+<table border="1">
+<tr><th>index</th><th>passed</th><th>source count</th><th>destination count</th></tr>
+{{range .Indices}}<tr><td>{{.Index}}</td><td>{{.Passed}}</td><td>{{.SourceCount}}</td><td>{{.DestinationCount}}{{if .SkippedCountCheck}} (not compared, the index is filtered){{end}}</td></tr>
+{{end}}</table>
+{{range .Indices}}{{if not .Passed}}
+<h2>{{.Index}}</h2>
+{{if .FailedIntervals}}<h3>failed intervals</h3>
+<table border="1">
+<tr><th>start</th><th>stop</th><th>source count</th><th>destination count</th></tr>
+{{range .FailedIntervals}}<tr><td>{{.Start}}</td><td>{{.Stop}}</td><td>{{.SourceCount}}</td><td>{{.DestinationCount}}</td></tr>
+{{end}}</table>{{end}}
+{{if .FailedBuckets}}<h3>failed buckets</h3>
+<table border="1">
+<tr><th>field</th><th>key</th><th>source count</th><th>destination count</th></tr>
+{{range .FailedBuckets}}<tr><td>{{.Field}}</td><td>{{.Key}}</td><td>{{.SourceCount}}</td><td>{{.DestinationCount}}</td></tr>
+{{end}}</table>{{end}}
+{{with .Sample}}<h3>sample of {{.NumChecked}} documents</h3>
+<p>missing: {{range .MissingIDs}}{{.}} {{end}}</p>
+{{if .SkippedExtraCheck}}<p>extra: not checked, the index is transformed</p>{{else}}<p>extra: {{range .ExtraIDs}}{{.}} {{end}}</p>{{end}}
+<p>different: {{range .DifferentIDs}}{{.}} {{end}}</p>{{end}}
+{{end}}{{end}}
+</body>
+</html>
+`))
+
+func (vr *VerifyReport) toHTML() ([]byte, error) {
+	buff := &bytes.Buffer{}
+	err := htmlReportTemplate.Execute(buff, vr)
+	if err != nil {
+		return nil, err
+	}
+
+	return buff.Bytes(), nil
+}
@@ -0,0 +1,162 @@
+package transform
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+)
+
+// Document holds a document that passes through a transformation chain
+type Document struct {
+	ID     string
+	Source map[string]interface{}
+}
+
+// Transformer defines the behaviour of a step from a transformation chain. It returns false if the document should
+// be filtered out
+type Transformer interface {
+	Transform(doc *Document) (bool, error)
+}
+
+// TransformerFactory defines a function that creates a custom transformer from its configuration
+type TransformerFactory func(cfg config.TransformConfig) (Transformer, error)
+
+// fieldPathTransformer defines a step that renames or drops fields, whose effect can be applied on the field paths of
+// a mapping. It returns false if the field is dropped
+type fieldPathTransformer interface {
+	TransformFieldPath(path []string) ([]string, bool)
+}
+
+type chain struct {
+	transformers []Transformer
+}
+
+// NewChain will create a new transformation chain from the provided steps
+func NewChain(transformers ...Transformer) *chain {
+	return &chain{
+		transformers: transformers,
+	}
+}
+
+// CreateChains will create a transformation chain for every index from the provided configuration. The custom
+// factories are used for the step types that are not built in
+func CreateChains(cfg map[string][]config.TransformConfig, customFactories map[string]TransformerFactory) (map[string]*chain, error) {
+	chains := make(map[string]*chain, len(cfg))
+	for index, stepsConfig := range cfg {
+		transformers := make([]Transformer, 0, len(stepsConfig))
+		for idx, stepConfig := range stepsConfig {
+			transformer, err := createTransformer(stepConfig, customFactories)
+			if err != nil {
+				return nil, fmt.Errorf("%w for index %s, step %d", err, index, idx)
+			}
+
+			transformers = append(transformers, transformer)
+		}
+
+		chains[index] = NewChain(transformers...)
+	}
+
+	return chains, nil
+}
+
+func createTransformer(cfg config.TransformConfig, customFactories map[string]TransformerFactory) (Transformer, error) {
+	switch cfg.Type {
+	case DropFieldType:
+		return newDropField(cfg)
+	case RenameFieldType:
+		return newRenameField(cfg)
+	case SetFieldType:
+		return newSetField(cfg)
+	case FilterType:
+		return newFilter(cfg)
+	case RemapIDType:
+		return newRemapID(cfg)
+	}
+
+	factory, found := customFactories[cfg.Type]
+	if !found {
+		return nil, fmt.Errorf("unknown transformation type %s", cfg.Type)
+	}
+
+	return factory(cfg)
+}
+
+// TransformDocument will pass the provided document through all the steps of the chain. It returns false if the
+// document was filtered out
+func (c *chain) TransformDocument(id string, source []byte) (string, []byte, bool, error) {
+	if len(c.transformers) == 0 {
+		return id, source, true, nil
+	}
+
+	doc := &Document{
+		ID:     id,
+		Source: make(map[string]interface{}),
+	}
+	decoder := json.NewDecoder(bytes.NewReader(source))
+	// the numbers have to be written back exactly as they were read
+	decoder.UseNumber()
+	err := decoder.Decode(&doc.Source)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("%w while decoding document %s", err, id)
+	}
+
+	for _, transformer := range c.transformers {
+		keep, errTransform := transformer.Transform(doc)
+		if errTransform != nil {
+			return "", nil, false, errTransform
+		}
+		if !keep {
+			return "", nil, false, nil
+		}
+	}
+
+	newSource, err := json.Marshal(doc.Source)
+	if err != nil {
+		return "", nil, false, fmt.Errorf("%w while encoding document %s", err, id)
+	}
+
+	return doc.ID, newSource, true, nil
+}
+
+// TransformFieldPath returns the dotted path that the provided field of the source documents has after the steps of
+// the chain that rename or drop fields. It returns false if the field is dropped
+func (c *chain) TransformFieldPath(path string) (string, bool) {
+	fieldPath := splitPath(path)
+	for _, transformer := range c.transformers {
+		pathTransformer, ok := transformer.(fieldPathTransformer)
+		if !ok {
+			continue
+		}
+
+		var keep bool
+		fieldPath, keep = pathTransformer.TransformFieldPath(fieldPath)
+		if !keep {
+			return "", false
+		}
+	}
+
+	return strings.Join(fieldPath, "."), true
+}
+
+// FiltersDocuments returns true if a step of the chain can filter out documents: a filter step or a custom step, whose
+// behaviour is not known
+func (c *chain) FiltersDocuments() bool {
+	for _, transformer := range c.transformers {
+		switch transformer.(type) {
+		case *dropField, *renameField, *setFieldTransformer, *remapID:
+			continue
+		}
+
+		return true
+	}
+
+	return false
+}
+
+// IsInterfaceNil returns true if there is no value under the interface
+func (c *chain) IsInterfaceNil() bool {
+	return c == nil
+}
@@ -0,0 +1,119 @@
+package transform
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+	"github.com/stretchr/testify/require"
+)
+
+type transformerStub struct {
+	transformCalled func(doc *Document) (bool, error)
+}
+
+func (ts *transformerStub) Transform(doc *Document) (bool, error) {
+	return ts.transformCalled(doc)
+}
+
+func TestCreateChains(t *testing.T) {
+	t.Parallel()
+
+	t.Run("unknown type should err", func(t *testing.T) {
+		_, err := CreateChains(map[string][]config.TransformConfig{"index": {{Type: "unknown"}}}, nil)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "unknown transformation type unknown")
+	})
+	t.Run("invalid step should err", func(t *testing.T) {
+		_, err := CreateChains(map[string][]config.TransformConfig{"index": {{Type: RenameFieldType, Field: "a"}}}, nil)
+		require.ErrorIs(t, err, errEmptyNewField)
+	})
+	t.Run("custom type should use the factory", func(t *testing.T) {
+		customFactories := map[string]TransformerFactory{
+			"uppercase-id": func(_ config.TransformConfig) (Transformer, error) {
+				return &transformerStub{
+					transformCalled: func(doc *Document) (bool, error) {
+						doc.ID = "ID"
+						return true, nil
+					},
+				}, nil
+			},
+		}
+
+		chains, err := CreateChains(map[string][]config.TransformConfig{"index": {{Type: "uppercase-id"}}}, customFactories)
+		require.NoError(t, err)
+
+		id, _, keep, err := chains["index"].TransformDocument("id", []byte(`{}`))
+		require.NoError(t, err)
+		require.True(t, keep)
+		require.Equal(t, "ID", id)
+	})
+}
+
+func TestChain_TransformDocument(t *testing.T) {
+	t.Parallel()
+
+	cfg := map[string][]config.TransformConfig{
+		"index": {
+			{Type: FilterType, Field: "status", Value: "fail", Exclude: true},
+			{Type: DropFieldType, Field: "data"},
+			{Type: DropFieldType, Field: "nested.drop"},
+			{Type: RenameFieldType, Field: "sender", NewField: "from.address"},
+			{Type: SetFieldType, Field: "version", Value: int64(2)},
+			{Type: RemapIDType, Template: "{_id}-{shard}"},
+		},
+	}
+	chains, err := CreateChains(cfg, nil)
+	require.NoError(t, err)
+	indexChain := chains["index"]
+
+	source := []byte(`{"status":"success","data":"ZGF0YQ==","nested":{"drop":1,"keep":2},"sender":"erd1","shard":1,"value":123456789012345678901234567890}`)
+	id, newSource, keep, err := indexChain.TransformDocument("hash", source)
+	require.NoError(t, err)
+	require.True(t, keep)
+	require.Equal(t, "hash-1", id)
+	require.JSONEq(t, `{"status":"success","nested":{"keep":2},"from":{"address":"erd1"},"shard":1,"version":2,"value":123456789012345678901234567890}`, string(newSource))
+
+	_, _, keep, err = indexChain.TransformDocument("hash", []byte(`{"status":"fail","shard":1}`))
+	require.NoError(t, err)
+	require.False(t, keep)
+
+	_, _, _, err = indexChain.TransformDocument("hash", []byte(`{"status":"success"}`))
+	require.Error(t, err)
+}
+
+func TestChain_TransformDocumentStepErrorShouldErr(t *testing.T) {
+	t.Parallel()
+
+	expectedErr := errors.New("expected error")
+	c := NewChain(&transformerStub{
+		transformCalled: func(_ *Document) (bool, error) {
+			return false, expectedErr
+		},
+	})
+
+	_, _, _, err := c.TransformDocument("id", []byte(`{}`))
+	require.Equal(t, expectedErr, err)
+}
+
+func TestFilter_KeepOnlyMatchingDocuments(t *testing.T) {
+	t.Parallel()
+
+	f, _ := newFilter(config.TransformConfig{Field: "shardID", Value: int64(1)})
+
+	keep, _ := f.Transform(&Document{Source: map[string]interface{}{"shardID": 1}})
+	require.True(t, keep)
+
+	keep, _ = f.Transform(&Document{Source: map[string]interface{}{"shardID": 2}})
+	require.False(t, keep)
+
+	keep, _ = f.Transform(&Document{Source: map[string]interface{}{}})
+	require.False(t, keep)
+}
+
+func TestChain_FiltersDocuments(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, NewChain(&dropField{path: []string{"a"}}, &remapID{template: "{_id}"}).FiltersDocuments())
+	require.True(t, NewChain(&dropField{path: []string{"a"}}, &filter{path: []string{"a"}, value: "1"}).FiltersDocuments())
+}
@@ -0,0 +1,260 @@
+package transform
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/multiversx/mx-chain-tools-go/elasticreindexer/config"
+)
+
+const (
+	// DropFieldType defines the step that removes a field
+	DropFieldType = "drop-field"
+	// RenameFieldType defines the step that moves the value of a field under a new name
+	RenameFieldType = "rename-field"
+	// SetFieldType defines the step that sets a field to a constant value
+	SetFieldType = "set-field"
+	// FilterType defines the step that keeps (or, if exclude is set, drops) the documents having a field with the provided value
+	FilterType = "filter"
+	// RemapIDType defines the step that computes the _id from a template
+	RemapIDType = "remap-id"
+
+	idPlaceholder = "_id"
+)
+
+var (
+	errEmptyField    = errors.New("empty field")
+	errEmptyNewField = errors.New("empty new field")
+	errEmptyTemplate = errors.New("empty template")
+
+	placeholderRegex = regexp.MustCompile(`\{([^{}]+)\}`)
+)
+
+type dropField struct {
+	path []string
+}
+
+func newDropField(cfg config.TransformConfig) (*dropField, error) {
+	if cfg.Field == "" {
+		return nil, errEmptyField
+	}
+
+	return &dropField{
+		path: splitPath(cfg.Field),
+	}, nil
+}
+
+// Transform will remove the field from the document
+func (df *dropField) Transform(doc *Document) (bool, error) {
+	deleteField(doc.Source, df.path)
+	return true, nil
+}
+
+// TransformFieldPath returns false for the dropped field and for its children
+func (df *dropField) TransformFieldPath(path []string) ([]string, bool) {
+	return path, !hasPathPrefix(path, df.path)
+}
+
+type renameField struct {
+	path    []string
+	newPath []string
+}
+
+func newRenameField(cfg config.TransformConfig) (*renameField, error) {
+	if cfg.Field == "" {
+		return nil, errEmptyField
+	}
+	if cfg.NewField == "" {
+		return nil, errEmptyNewField
+	}
+
+	return &renameField{
+		path:    splitPath(cfg.Field),
+		newPath: splitPath(cfg.NewField),
+	}, nil
+}
+
+// Transform will move the value of the field under the new name
+func (rf *renameField) Transform(doc *Document) (bool, error) {
+	value, found := getField(doc.Source, rf.path)
+	if !found {
+		return true, nil
+	}
+
+	deleteField(doc.Source, rf.path)
+	setField(doc.Source, rf.newPath, value)
+
+	return true, nil
+}
+
+// TransformFieldPath moves the renamed field and its children under the new name
+func (rf *renameField) TransformFieldPath(path []string) ([]string, bool) {
+	if !hasPathPrefix(path, rf.path) {
+		return path, true
+	}
+
+	newPath := make([]string, 0, len(rf.newPath)+len(path)-len(rf.path))
+	newPath = append(newPath, rf.newPath...)
+	newPath = append(newPath, path[len(rf.path):]...)
+
+	return newPath, true
+}
+
+type setFieldTransformer struct {
+	path  []string
+	value interface{}
+}
+
+func newSetField(cfg config.TransformConfig) (*setFieldTransformer, error) {
+	if cfg.Field == "" {
+		return nil, errEmptyField
+	}
+
+	return &setFieldTransformer{
+		path:  splitPath(cfg.Field),
+		value: cfg.Value,
+	}, nil
+}
+
+// Transform will set the field to the constant value
+func (sf *setFieldTransformer) Transform(doc *Document) (bool, error) {
+	setField(doc.Source, sf.path, sf.value)
+	return true, nil
+}
+
+type filter struct {
+	path    []string
+	value   string
+	exclude bool
+}
+
+func newFilter(cfg config.TransformConfig) (*filter, error) {
+	if cfg.Field == "" {
+		return nil, errEmptyField
+	}
+
+	return &filter{
+		path:    splitPath(cfg.Field),
+		value:   fmt.Sprintf("%v", cfg.Value),
+		exclude: cfg.Exclude,
+	}, nil
+}
+
+// Transform will return false if the document should be filtered out
+func (f *filter) Transform(doc *Document) (bool, error) {
+	value, found := getField(doc.Source, f.path)
+	matches := found && fmt.Sprintf("%v", value) == f.value
+
+	return matches != f.exclude, nil
+}
+
+type remapID struct {
+	template string
+}
+
+func newRemapID(cfg config.TransformConfig) (*remapID, error) {
+	if cfg.Template == "" {
+		return nil, errEmptyTemplate
+	}
+
+	return &remapID{
+		template: cfg.Template,
+	}, nil
+}
+
+// Transform will compute the new _id of the document by replacing every {field} placeholder from the template with the
+// value of the field. The {_id} placeholder is replaced with the current _id
+func (ri *remapID) Transform(doc *Document) (bool, error) {
+	var err error
+	newID := placeholderRegex.ReplaceAllStringFunc(ri.template, func(placeholder string) string {
+		field := strings.Trim(placeholder, "{}")
+		if field == idPlaceholder {
+			return doc.ID
+		}
+
+		value, found := getField(doc.Source, splitPath(field))
+		if !found {
+			err = fmt.Errorf("field %s from the _id template is missing in document %s", field, doc.ID)
+			return ""
+		}
+
+		return fmt.Sprintf("%v", value)
+	})
+	if err != nil {
+		return false, err
+	}
+
+	doc.ID = newID
+
+	return true, nil
+}
+
+func splitPath(field string) []string {
+	return strings.Split(field, ".")
+}
+
+func hasPathPrefix(path []string, prefix []string) bool {
+	if len(path) < len(prefix) {
+		return false
+	}
+	for i := range prefix {
+		if path[i] != prefix[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func getField(source map[string]interface{}, path []string) (interface{}, bool) {
+	current := source
+	for i, key := range path {
+		value, found := current[key]
+		if !found {
+			return nil, false
+		}
+		if i == len(path)-1 {
+			return value, true
+		}
+
+		current, found = value.(map[string]interface{})
+		if !found {
+			return nil, false
+		}
+	}
+
+	return nil, false
+}
+
+func deleteField(source map[string]interface{}, path []string) {
+	parentPath, key := path[:len(path)-1], path[len(path)-1]
+	if len(parentPath) == 0 {
+		delete(source, key)
+		return
+	}
+
+	parent, found := getField(source, parentPath)
+	if !found {
+		return
+	}
+	parentObject, isObject := parent.(map[string]interface{})
+	if isObject {
+		delete(parentObject, key)
+	}
+}
+
+func setField(source map[string]interface{}, path []string, value interface{}) {
+	current := source
+	for _, key := range path[:len(path)-1] {
+		next, isObject := current[key].(map[string]interface{})
+		if !isObject {
+			next = make(map[string]interface{})
+			current[key] = next
+		}
+		current = next
+	}
+
+	current[path[len(path)-1]] = value
+}